@@ -1,33 +1,25 @@
 package main
 
 import (
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
 
 	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
 	"github.com/jalavosus/stuffnotifier/pkg/discord"
+	"github.com/jalavosus/stuffnotifier/pkg/email"
 	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
 	"github.com/jalavosus/stuffnotifier/pkg/gemini"
 	"github.com/jalavosus/stuffnotifier/pkg/slack"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 	"github.com/jalavosus/stuffnotifier/pkg/twilio"
+	"github.com/jalavosus/stuffnotifier/pkg/webhook"
 )
 
 func loadPollerConfig(c *cli.Context) (*poller.Config, error) {
 	return loadConfigFromFlag[poller.Config](c, pollerConfigFlag, poller.LoadConfig)
 }
 
-func loadTwilioConfig(c *cli.Context) (*twilio.Config, error) {
-	return loadConfigFromFlag[twilio.Config](c, twilioConfigFlag, twilio.LoadConfig)
-}
-
-func loadDiscordConfig(c *cli.Context) (*discord.Config, error) {
-	return loadConfigFromFlag[discord.Config](c, discordConfigFlag, discord.LoadConfig)
-}
-
-func loadSlackConfig(c *cli.Context) (*slack.Config, error) {
-	return loadConfigFromFlag[slack.Config](c, slackConfigFlag, slack.LoadConfig)
-}
-
 func loadFlightAwareConfig(c *cli.Context) (*flightaware.Config, error) {
 	return loadConfigFromFlag[flightaware.Config](c, flightawareConfigFlag, flightaware.LoadConfig)
 }
@@ -63,31 +55,42 @@ func loadBuildPollerConfig(c *cli.Context) (config poller.Config) {
 		}
 	}
 
-	if config.Twilio == nil {
-		twilioConf, confErr := loadTwilioConfig(c)
-		if confErr != nil {
-			logger.Warn("error loading Twilio config", zap.Error(confErr))
-		} else if twilioConf != nil {
-			config.Twilio = twilioConf
-		}
+	loadNotifierConfig(c, "Twilio", twilioConfigFlag, twilio.LoadConfig, &config.Twilio)
+	loadNotifierConfig(c, "Discord", discordConfigFlag, discord.LoadConfig, &config.Discord)
+	loadNotifierConfig(c, "Slack", slackConfigFlag, slack.LoadConfig, &config.Slack)
+	loadNotifierConfig(c, "Email", emailConfigFlag, email.LoadConfig, &config.Email)
+	loadNotifierConfig(c, "Webhook", webhookConfigFlag, webhook.LoadConfig, &config.Webhook)
+
+	return
+}
+
+// loadNotifierConfig loads a notifier's config from the passed flag into dest,
+// if dest wasn't already set by the main poller config.
+func loadNotifierConfig[T any](c *cli.Context, name string, flag cli.PathFlag, loadConf func(string) (T, error), dest **T) {
+	if *dest != nil {
+		return
 	}
 
-	if config.Discord == nil {
-		discordConf, confErr := loadDiscordConfig(c)
-		if confErr != nil {
-			logger.Warn("error loading Discord config", zap.Error(confErr))
-		} else if discordConf != nil {
-			config.Discord = discordConf
-		}
+	conf, confErr := loadConfigFromFlag[T](c, flag, loadConf)
+	if confErr != nil {
+		logger.Warn("error loading "+name+" config", zap.Error(confErr))
+	} else if conf != nil {
+		*dest = conf
 	}
+}
 
-	if config.Slack == nil {
-		slackConf, confErr := loadSlackConfig(c)
-		if confErr != nil {
-			logger.Warn("error loading Slack config", zap.Error(confErr))
-		} else if slackConf != nil {
-			config.Slack = slackConf
-		}
+// cassetteFromFlags returns the cassette config set by the record and replay flags,
+// and whether either was set.
+func cassetteFromFlags(c *cli.Context) (conf transport.CassetteConfig, ok bool, err error) {
+	recordPath, replayPath := recordFlag.Get(c), replayFlag.Get(c)
+
+	switch {
+	case recordPath != "" && replayPath != "":
+		err = errors.New("only one of --record and --replay may be set")
+	case recordPath != "":
+		conf, ok = transport.CassetteConfig{Mode: transport.CassetteRecord, Path: recordPath}, true
+	case replayPath != "":
+		conf, ok = transport.CassetteConfig{Mode: transport.CassetteReplay, Path: replayPath}, true
 	}
 
 	return
@@ -8,6 +8,7 @@ import (
 
 const (
 	categoryConfig      string = "Config"
+	categoryCassette    string = "Record/Replay"
 	categoryAuth        string = "Auth"
 	categoryTwilioAuth         = categoryAuth + " - Twilio"
 	categoryDiscordAuth        = categoryAuth + " - Discord"
@@ -18,8 +19,14 @@ const (
 	twilioFlagName      string = "twilio"
 	discordFlagName     string = "discord"
 	slackFlagName       string = "slack"
+	emailFlagName       string = "email"
+	webhookFlagName     string = "webhook"
 	geminiFlagName      string = "gemini"
 	flightAwareFlagName string = "flightaware"
+	recordFlagName      string = "record"
+	replayFlagName      string = "replay"
+	resumeFlagName      string = "resume"
+	forgetFlagName      string = "forget"
 )
 
 const (
@@ -61,6 +68,8 @@ var (
 	twilioConfigFlag      = makeConfigFlag(twilioFlagName)
 	discordConfigFlag     = makeConfigFlag(discordFlagName)
 	slackConfigFlag       = makeConfigFlag(slackFlagName)
+	emailConfigFlag       = makeConfigFlag(emailFlagName)
+	webhookConfigFlag     = makeConfigFlag(webhookFlagName)
 )
 
 var (
@@ -130,3 +139,33 @@ var (
 		EnvVars:  []string{env.FlightAwareKey},
 	}
 )
+
+var (
+	recordFlag = cli.PathFlag{
+		Name:     recordFlagName,
+		Usage:    "record API traffic to the cassette file at `path`",
+		Category: categoryCassette,
+		Required: false,
+	}
+	replayFlag = cli.PathFlag{
+		Name:     replayFlagName,
+		Usage:    "replay API traffic from the cassette file at `path`, instead of calling the API",
+		Category: categoryCassette,
+		Required: false,
+	}
+)
+
+var (
+	resumeFlag = cli.BoolFlag{
+		Name:     resumeFlagName,
+		Usage:    "resume polling everything which was being polled when a previous run stopped, from the cache",
+		Category: categoryConfig,
+		Required: false,
+	}
+	forgetFlag = cli.StringSliceFlag{
+		Name:     forgetFlagName,
+		Usage:    "remove the Gemini `symbol` (ex. BTCUSD) from the cache, so that it isn't resumed; may be passed more than once",
+		Category: categoryConfig,
+		Required: false,
+	}
+)
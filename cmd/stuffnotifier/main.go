@@ -21,6 +21,9 @@ func main() {
 		Flags: []cli.Flag{
 			&twilioConfigFlag,
 			&discordConfigFlag,
+			&slackConfigFlag,
+			&emailConfigFlag,
+			&webhookConfigFlag,
 			&twilioSidFlag,
 			&twilioApiKeyFlag,
 			&twilioApiSecretFlag,
@@ -27,18 +27,28 @@ var (
 			&geminiConfigFlag,
 			&geminiApiKeyFlag,
 			&geminiApiSecret,
+			&recordFlag,
+			&replayFlag,
+			&resumeFlag,
+			&forgetFlag,
 		},
 	}
 	flightawareCmd = cli.Command{
 		Name:        "flightaware",
 		Description: "Monitor flightaware flights",
-		ArgsUsage: "FLIGHT_IDENTIFIER: (Flight number OR FlightAware flight ID for a flight) " +
-			"IDENTIFIER_TYPE: ('flight_number' if passing a flight number, 'flightaware_id' if passing a FlightAware ID number)",
+		ArgsUsage: "[FLIGHT_IDENTIFIER IDENTIFIER_TYPE]... " +
+			"FLIGHT_IDENTIFIER: (Flight number OR FlightAware flight ID for a flight, OR an aircraft registration) " +
+			"IDENTIFIER_TYPE: ('flight_number' if passing a flight number, 'flightaware_id' if passing a FlightAware ID number, " +
+			"'registration' if passing an aircraft registration, to track every flight it flies). " +
+			"Any number of flights may be passed, in addition to those configured in the FlightAware config",
 		Action: flightawareCmdAction,
 		Flags: []cli.Flag{
 			&pollerConfigFlag,
 			&flightawareConfigFlag,
 			&flightAwareApiKeyFlag,
+			&recordFlag,
+			&replayFlag,
+			&resumeFlag,
 		},
 	}
 )
@@ -50,6 +60,7 @@ func geminiCmdAction(c *cli.Context) error {
 	)
 
 	config := loadBuildPollerConfig(c)
+	config.Resume = config.Resume || resumeFlag.Get(c)
 
 	if config.Gemini == nil {
 		geminiConf, confErr := loadGeminiConfig(c)
@@ -67,31 +78,41 @@ func geminiCmdAction(c *cli.Context) error {
 
 	config.PollInterval = pollInterval
 
-	poller, err := geminipoller.NewPoller(config, geminiConfig)
+	cassette, useCassette, err := cassetteFromFlags(c)
 	if err != nil {
 		return err
 	}
 
-	return poller.Start(c.Context)
-}
+	if useCassette {
+		if geminiConfig == nil {
+			geminiConfig = gemini.DefaultConfig()
+		}
 
-func flightawareCmdAction(c *cli.Context) error {
-	if c.Args().Len() < 2 {
-		return errors.New("not enough arguments passed")
+		geminiConfig.Cassette = cassette
+	}
+
+	if forget := forgetFlag.Get(c); len(forget) > 0 {
+		if geminiConfig == nil {
+			geminiConfig = gemini.DefaultConfig()
+		}
+
+		geminiConfig.Forget = append(geminiConfig.Forget, forget...)
+	}
+
+	poller, err := geminipoller.NewPoller(config, geminiConfig)
+	if err != nil {
+		return err
 	}
 
-	flightId := c.Args().First()
-	flightIdTypeStr := c.Args().Get(1)
+	defer func() { _ = poller.Close() }()
 
-	var flightIdType flightaware.IdentifierType
+	return poller.Start(c.Context)
+}
 
-	switch strings.ToLower(flightIdTypeStr) {
-	case "flight_number":
-		flightIdType = flightaware.DesignatorIdent
-	case "flightaware_id":
-		flightIdType = flightaware.FaFlightIdIdent
-	default:
-		return errors.Errorf("unknown identifier type %[1]s. Allowed values: 'flight_number', 'flightaware_id'", flightIdType)
+func flightawareCmdAction(c *cli.Context) error {
+	flights, err := flightsFromArgs(c.Args().Slice())
+	if err != nil {
+		return err
 	}
 
 	var (
@@ -100,6 +121,7 @@ func flightawareCmdAction(c *cli.Context) error {
 	)
 
 	config := loadBuildPollerConfig(c)
+	config.Resume = config.Resume || resumeFlag.Get(c)
 
 	if config.FlightAware == nil {
 		faConf, confErr := loadFlightAwareConfig(c)
@@ -117,10 +139,51 @@ func flightawareCmdAction(c *cli.Context) error {
 
 	config.PollInterval = pollInterval
 
+	cassette, useCassette, err := cassetteFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	if useCassette {
+		if faConfig == nil {
+			defaultConf := flightaware.DefaultConfig()
+			faConfig = &defaultConf
+		}
+
+		faConfig.Cassette = cassette
+	}
+
 	faPoller, err := fapoller.NewPoller(config, faConfig)
 	if err != nil {
 		return err
 	}
 
-	return faPoller.Start(c.Context, flightId, flightIdType)
+	defer func() { _ = faPoller.Close() }()
+
+	return faPoller.Start(c.Context, flights...)
+}
+
+// flightsFromArgs parses command line arguments, passed as
+// pairs of FLIGHT_IDENTIFIER IDENTIFIER_TYPE, into flight configs.
+func flightsFromArgs(args []string) ([]flightaware.FlightConfig, error) {
+	if len(args)%2 != 0 {
+		return nil, errors.New("flights must be passed as pairs of FLIGHT_IDENTIFIER IDENTIFIER_TYPE")
+	}
+
+	flights := make([]flightaware.FlightConfig, 0, len(args)/2)
+
+	for i := 0; i < len(args); i += 2 {
+		flight := flightaware.FlightConfig{
+			Identifier:     args[i],
+			IdentifierType: strings.ToLower(args[i+1]),
+		}
+
+		if _, err := flightaware.ParseIdentifierType(flight.IdentifierType); err != nil {
+			return nil, err
+		}
+
+		flights = append(flights, flight)
+	}
+
+	return flights, nil
 }
@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"strings"
 	"time"
 )
 
@@ -31,3 +32,7 @@ func (d *baseDatastore[T]) SetKeyPrefix(newPrefix string) {
 func (d *baseDatastore[T]) prefixKey(key string) string {
 	return d.KeyPrefix() + ":" + key
 }
+
+func (d *baseDatastore[T]) unprefixKey(key string) string {
+	return strings.TrimPrefix(key, d.KeyPrefix()+":")
+}
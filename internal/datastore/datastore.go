@@ -23,14 +23,39 @@ type Datastore[T any] interface {
 	// as well as a boolean representing whether the key exists
 	// in the datastore at all.
 	Get(ctx context.Context, key string) (*T, bool, error)
+	// GetVersioned is Get, but also returns the version of the stored data.
+	// Every insert increments a key's version by 1; keys which don't exist
+	// are at version 0.
+	GetVersioned(ctx context.Context, key string) (*T, uint64, bool, error)
 	// Insert inserts data using a given key into the datastore,
 	// returning any error returned by the underlying datastore implementation.
-	Insert(ctx context.Context, key string, data T) error
+	// By default, data is stored with the datastore's default TTL, overwriting
+	// any existing data; see InsertOption for the available options.
+	// The TTL, condition check and write happen atomically.
+	Insert(ctx context.Context, key string, data T, opts ...InsertOption) error
 	// Delete removes a given key from the datastore, returning any error
 	// returned by the underlying datastore implementation.
 	// Note that this is a "blind" delete: if the passed key does not exist,
 	// no error is returned, and no data is otherwise modified.
 	Delete(ctx context.Context, key string) error
+	// Keys returns every key in the datastore which starts with the passed prefix,
+	// without the datastore's key prefix.
+	// Keys are returned in no particular order.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+	// Scan calls fn with every key in the datastore which starts with the passed prefix
+	// (without the datastore's key prefix), and the data stored with it,
+	// in no particular order. Keys removed while scanning are skipped.
+	// If fn returns an error, scanning stops and the error is returned.
+	Scan(ctx context.Context, prefix string, fn func(key string, data *T) error) error
+	// GetMany returns the data stored with each of the passed keys, keyed by key.
+	// Keys which don't exist in the datastore are omitted from the result.
+	GetMany(ctx context.Context, keys []string) (map[string]*T, error)
+	// InsertMany inserts every passed key's data into the datastore,
+	// returning any error returned by the underlying datastore implementation.
+	InsertMany(ctx context.Context, data map[string]T) error
+	// DeleteByPrefix removes every key which starts with the passed prefix from the datastore,
+	// returning the number of keys removed.
+	DeleteByPrefix(ctx context.Context, prefix string) (int, error)
 	// UpdateTtl sets the time-to-live for an object in the datastore which
 	// is stored at the passed key.
 	// If the passed key is unset, false is returned; otherwise, true is returned.
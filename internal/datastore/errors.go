@@ -1,5 +1,9 @@
 package datastore
 
+import (
+	"github.com/pkg/errors"
+)
+
 const (
 	base64DecodeErrMsg string = "error decoding base64 string"
 	snappyDecodeErrMsg string = "error decompressing snappy-compressed bytes"
@@ -11,3 +15,12 @@ const (
 	// snappyEncodeErrMsg string = "error snappy-compressing bytes"
 	gobEncodeErrMsg string = "error gob-encoding data"
 )
+
+var (
+	// ErrKeyExists is returned by Insert when it's passed IfAbsent,
+	// and the key already exists.
+	ErrKeyExists = errors.New("key already exists")
+	// ErrVersionMismatch is returned by Insert when it's passed IfVersion,
+	// and the key's current version isn't the expected version.
+	ErrVersionMismatch = errors.New("version mismatch")
+)
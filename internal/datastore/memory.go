@@ -3,6 +3,8 @@ package datastore
 import (
 	"context"
 	"encoding/binary"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
@@ -24,6 +26,19 @@ type memoryDatastore[T any] struct {
 	*baseDatastore[T]
 	client       *ristretto.Cache
 	clientConfig *ristretto.Config
+	// ristretto can't enumerate its keys,
+	// so every key inserted into the datastore is indexed.
+	keys   map[string]struct{}
+	keysMu sync.Mutex
+	// Inserts check a key's current version before setting it,
+	// which has to happen atomically.
+	writeMu sync.Mutex
+}
+
+// memoryEntry is the value stored in the cache.
+type memoryEntry struct {
+	Data    string
+	Version uint64
 }
 
 func NewInMemoryDatastore[T any](conf *Config) (Datastore[T], error) {
@@ -36,6 +51,7 @@ func NewInMemoryDatastore[T any](conf *Config) (Datastore[T], error) {
 
 	m := &memoryDatastore[T]{
 		baseDatastore: newBaseDatastore[T](datastoreConf),
+		keys:          make(map[string]struct{}),
 		clientConfig: &ristretto.Config{
 			NumCounters: inMemoryCacheNumCounters,
 			MaxCost:     inMemoryCacheMaxCost,
@@ -62,35 +78,201 @@ func (m *memoryDatastore[T]) CheckTtl(_ context.Context, key string) (time.Durat
 	return ttl, ok, nil
 }
 
-func (m *memoryDatastore[T]) Get(_ context.Context, key string) (*T, bool, error) {
+func (m *memoryDatastore[T]) Get(ctx context.Context, key string) (*T, bool, error) {
+	data, _, ok, err := m.GetVersioned(ctx, key)
+	return data, ok, err
+}
+
+func (m *memoryDatastore[T]) GetVersioned(_ context.Context, key string) (*T, uint64, bool, error) {
+	entry, ok := m.entry(key)
+	if !ok {
+		return nil, 0, false, nil
+	}
+
+	data, ok, err := fullDecode[T](entry.Data)
+
+	return data, entry.Version, ok, err
+}
+
+func (m *memoryDatastore[T]) Insert(_ context.Context, key string, data T, opts ...InsertOption) error {
+	o := newInsertOptions(opts)
+
+	ttl, err := o.resolveTtl(m.DefaultTtl(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	entry, exists := m.entry(key)
+	if err = o.check(exists, entry.Version); err != nil {
+		return err
+	}
+
+	if err = m.set(key, data, entry.Version+1, ttl); err != nil {
+		return err
+	}
+
+	// Sets are buffered, and wouldn't otherwise be visible to Get or Keys right away.
+	m.client.Wait()
+
+	return nil
+}
+
+func (m *memoryDatastore[T]) Delete(_ context.Context, key string) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	m.del(key)
+
+	return nil
+}
+
+// Keys returns the indexed keys with the passed prefix which are still in the cache.
+// Keys which have expired or been evicted are removed from the index.
+func (m *memoryDatastore[T]) Keys(_ context.Context, prefix string) ([]string, error) {
+	m.keysMu.Lock()
+	defer m.keysMu.Unlock()
+
+	var keys []string
+
+	for key := range m.keys {
+		if _, ok := m.client.Get(m.prefixKey(key)); !ok {
+			delete(m.keys, key)
+			continue
+		}
+
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (m *memoryDatastore[T]) Scan(ctx context.Context, prefix string, fn func(key string, data *T) error) error {
+	keys, err := m.Keys(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		data, ok, getErr := m.Get(ctx, key)
+		if getErr != nil {
+			return errors.WithMessagef(getErr, "key %[1]s", key)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if fnErr := fn(key, data); fnErr != nil {
+			return fnErr
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryDatastore[T]) GetMany(ctx context.Context, keys []string) (map[string]*T, error) {
+	res := make(map[string]*T, len(keys))
+
+	for _, key := range keys {
+		data, ok, err := m.Get(ctx, key)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "key %[1]s", key)
+		}
+
+		if ok {
+			res[key] = data
+		}
+	}
+
+	return res, nil
+}
+
+func (m *memoryDatastore[T]) InsertMany(_ context.Context, data map[string]T) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	for key, value := range data {
+		entry, _ := m.entry(key)
+
+		if err := m.set(key, value, entry.Version+1, m.DefaultTtl()); err != nil {
+			return err
+		}
+	}
+
+	m.client.Wait()
+
+	return nil
+}
+
+func (m *memoryDatastore[T]) DeleteByPrefix(ctx context.Context, prefix string) (int, error) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	keys, err := m.Keys(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		m.del(key)
+	}
+
+	return len(keys), nil
+}
+
+// entry returns the cache entry stored with the passed key.
+func (m *memoryDatastore[T]) entry(key string) (memoryEntry, bool) {
 	res, ok := m.client.Get(m.prefixKey(key))
 	if !ok {
-		return nil, false, nil
+		return memoryEntry{}, false
 	}
 
-	return fullDecode[T](res.(string))
+	return res.(memoryEntry), true
 }
 
-func (m *memoryDatastore[T]) Insert(_ context.Context, key string, data T) error {
+// set adds data to the cache with the passed version and indexes its key,
+// without waiting for the cache's buffered sets to be applied.
+// Callers must hold writeMu.
+func (m *memoryDatastore[T]) set(key string, data T, version uint64, ttl time.Duration) error {
 	encoded, err := fullEncode[T](data)
 	if err != nil {
 		return err
 	}
 
-	ok := m.client.SetWithTTL(m.prefixKey(key), encoded, inMemoryCacheItemCost, m.DefaultTtl())
+	entry := memoryEntry{Data: encoded, Version: version}
+
+	ok := m.client.SetWithTTL(m.prefixKey(key), entry, inMemoryCacheItemCost, ttl)
 	if !ok {
 		return errors.Errorf("unable to add data with key %[1]s to cache", key)
 	}
 
+	m.keysMu.Lock()
+	m.keys[key] = struct{}{}
+	m.keysMu.Unlock()
+
 	return nil
 }
 
-func (m *memoryDatastore[T]) Delete(_ context.Context, key string) error {
+// del removes the passed key from the cache and its index.
+// Callers must hold writeMu, so that a key isn't deleted
+// between an Insert checking its version and setting it.
+func (m *memoryDatastore[T]) del(key string) {
 	m.client.Del(m.prefixKey(key))
-	return nil
+
+	m.keysMu.Lock()
+	delete(m.keys, key)
+	m.keysMu.Unlock()
 }
 
 func (m *memoryDatastore[T]) UpdateTtl(_ context.Context, key string, newTtl time.Duration) (bool, error) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
 	key = m.prefixKey(key)
 
 	res, ok := m.client.Get(key)
@@ -0,0 +1,175 @@
+package datastore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	Name  string
+	Count int
+}
+
+func TestMemoryDatastore_BatchOperations(t *testing.T) {
+	ctx := context.Background()
+
+	ds, err := NewInMemoryDatastore[testEntry](nil)
+	require.NoError(t, err)
+
+	require.NoError(t, ds.InsertMany(ctx, map[string]testEntry{
+		"flightdata:UAL1": {Name: "UAL1", Count: 1},
+		"flightdata:UAL2": {Name: "UAL2", Count: 2},
+		"gemini:ETHUSD":   {Name: "ETHUSD", Count: 3},
+	}))
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL3", testEntry{Name: "UAL3", Count: 4}))
+
+	keys, err := ds.Keys(ctx, "flightdata:")
+	require.NoError(t, err)
+	sort.Strings(keys)
+	assert.Equal(t, []string{"flightdata:UAL1", "flightdata:UAL2", "flightdata:UAL3"}, keys)
+
+	scanned := make(map[string]testEntry)
+	require.NoError(t, ds.Scan(ctx, "gemini:", func(key string, data *testEntry) error {
+		scanned[key] = *data
+		return nil
+	}))
+	assert.Equal(t, map[string]testEntry{"gemini:ETHUSD": {Name: "ETHUSD", Count: 3}}, scanned)
+
+	stop := errors.New("stop")
+	assert.Equal(t, stop, ds.Scan(ctx, "", func(string, *testEntry) error { return stop }))
+
+	got, err := ds.GetMany(ctx, []string{"flightdata:UAL1", "flightdata:UAL9", "gemini:ETHUSD"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*testEntry{
+		"flightdata:UAL1": {Name: "UAL1", Count: 1},
+		"gemini:ETHUSD":   {Name: "ETHUSD", Count: 3},
+	}, got)
+
+	require.NoError(t, ds.Delete(ctx, "flightdata:UAL2"))
+
+	deleted, err := ds.DeleteByPrefix(ctx, "flightdata:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	keys, err = ds.Keys(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gemini:ETHUSD"}, keys)
+}
+
+func TestMemoryDatastore_ConditionalInsert(t *testing.T) {
+	ctx := context.Background()
+
+	ds, err := NewInMemoryDatastore[testEntry](nil)
+	require.NoError(t, err)
+
+	_, version, ok, err := ds.GetVersioned(ctx, "flightdata:UAL1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Zero(t, version)
+
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 1}, IfAbsent()))
+	assert.ErrorIs(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 2}, IfAbsent()), ErrKeyExists)
+
+	data, version, ok, err := ds.GetVersioned(ctx, "flightdata:UAL1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, testEntry{Name: "UAL1", Count: 1}, *data)
+	assert.Equal(t, uint64(1), version)
+
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 3}, IfVersion(1)))
+	assert.ErrorIs(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 4}, IfVersion(1)), ErrVersionMismatch)
+
+	data, version, _, err = ds.GetVersioned(ctx, "flightdata:UAL1")
+	require.NoError(t, err)
+	assert.Equal(t, testEntry{Name: "UAL1", Count: 3}, *data)
+	assert.Equal(t, uint64(2), version)
+
+	// Keys which don't exist are at version 0.
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL2", testEntry{Name: "UAL2"}, IfVersion(0)))
+	assert.ErrorIs(t, ds.Insert(ctx, "flightdata:UAL3", testEntry{Name: "UAL3"}, IfVersion(1)), ErrVersionMismatch)
+
+	_, ok, err = ds.Get(ctx, "flightdata:UAL3")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMemoryDatastore_InsertTtl(t *testing.T) {
+	ctx := context.Background()
+
+	ds, err := NewInMemoryDatastore[testEntry](nil)
+	require.NoError(t, err)
+
+	require.NoError(t, ds.Insert(ctx, "ttl", testEntry{}, WithTtl(time.Hour)))
+	ttl, ok, err := ds.CheckTtl(ctx, "ttl")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Minute))
+
+	require.NoError(t, ds.Insert(ctx, "expiry", testEntry{}, WithExpiry(time.Now().Add(2*time.Hour))))
+	ttl, ok, err = ds.CheckTtl(ctx, "expiry")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.InDelta(t, 2*time.Hour, ttl, float64(time.Minute))
+
+	require.NoError(t, ds.Insert(ctx, "forever", testEntry{}, WithTtl(0)))
+	ttl, ok, err = ds.CheckTtl(ctx, "forever")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Zero(t, ttl)
+
+	assert.Error(t, ds.Insert(ctx, "past", testEntry{}, WithExpiry(time.Now().Add(-time.Minute))))
+	assert.Error(t, ds.Insert(ctx, "negative", testEntry{}, WithTtl(-time.Minute)))
+}
+
+func TestMemoryDatastore_DeleteInsert(t *testing.T) {
+	const workers = 8
+
+	ctx := context.Background()
+
+	ds, err := NewInMemoryDatastore[testEntry](nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				// Every worker inserts and deletes the same key,
+				// so inserts race with other workers' deletes.
+				insertErr := ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: i}, IfAbsent())
+				if insertErr != nil && !errors.Is(insertErr, ErrKeyExists) {
+					assert.NoError(t, insertErr)
+				}
+
+				assert.NoError(t, ds.Delete(ctx, "flightdata:UAL1"))
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	m := ds.(*memoryDatastore[testEntry])
+	assert.Empty(t, m.keys)
+
+	// Deleted keys can be inserted again.
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1"}, IfAbsent()))
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL2", testEntry{Name: "UAL2"}, IfAbsent()))
+
+	deleted, err := ds.DeleteByPrefix(ctx, "flightdata:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Empty(t, m.keys)
+
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1"}, IfAbsent()))
+}
@@ -0,0 +1,102 @@
+package datastore
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// InsertOption configures a single call to Insert.
+type InsertOption func(o *insertOptions)
+
+// WithTtl sets the time-to-live of the inserted data,
+// in place of the datastore's default TTL.
+// A TTL of 0 stores the data without expiry.
+func WithTtl(ttl time.Duration) InsertOption {
+	return func(o *insertOptions) {
+		o.ttl = &ttl
+		o.expiry = time.Time{}
+	}
+}
+
+// WithExpiry sets the time at which the inserted data expires,
+// in place of the datastore's default TTL.
+func WithExpiry(expiry time.Time) InsertOption {
+	return func(o *insertOptions) {
+		o.expiry = expiry
+		o.ttl = nil
+	}
+}
+
+// IfAbsent only inserts the data if the key doesn't exist,
+// otherwise Insert returns ErrKeyExists.
+func IfAbsent() InsertOption {
+	return func(o *insertOptions) {
+		o.ifAbsent = true
+	}
+}
+
+// IfVersion only inserts the data if the key's current version (see GetVersioned)
+// is the passed version, otherwise Insert returns ErrVersionMismatch.
+// Keys which don't exist are at version 0.
+func IfVersion(version uint64) InsertOption {
+	return func(o *insertOptions) {
+		o.ifVersion = &version
+	}
+}
+
+type insertOptions struct {
+	ttl       *time.Duration
+	ifVersion *uint64
+	expiry    time.Time
+	ifAbsent  bool
+}
+
+func newInsertOptions(opts []InsertOption) insertOptions {
+	var o insertOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// resolveTtl returns the time-to-live of the inserted data at `now`.
+func (o insertOptions) resolveTtl(defaultTtl time.Duration, now time.Time) (time.Duration, error) {
+	switch {
+	case o.ttl != nil:
+		if *o.ttl < 0 {
+			return 0, errors.Errorf("invalid ttl %[1]s", *o.ttl)
+		}
+
+		return *o.ttl, nil
+	case !o.expiry.IsZero():
+		ttl := o.expiry.Sub(now)
+		if ttl <= 0 {
+			return 0, errors.Errorf("expiry %[1]s is in the past", o.expiry.Format(time.RFC3339))
+		}
+
+		return ttl, nil
+	default:
+		return defaultTtl, nil
+	}
+}
+
+// check returns an error if data can't be inserted for a key
+// with the passed current version, which is 0 if the key doesn't exist.
+func (o insertOptions) check(exists bool, version uint64) error {
+	if o.ifAbsent && exists {
+		return ErrKeyExists
+	}
+
+	if o.ifVersion != nil && *o.ifVersion != version {
+		return versionMismatch(*o.ifVersion, version)
+	}
+
+	return nil
+}
+
+func versionMismatch(expected, got uint64) error {
+	return errors.WithMessagef(ErrVersionMismatch, "expected version %[1]d, got %[2]d", expected, got)
+}
@@ -3,6 +3,8 @@ package datastore
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v9"
@@ -13,8 +15,50 @@ const (
 	redisDefaultHost     string = "localhost"
 	redisDefaultPort     int    = 6379
 	redisDefaultPassword string = ""
+	// Number of keys requested from each SCAN call.
+	redisScanCount int64 = 100
+	// Separates a stored value's version from its encoded data.
+	redisVersionSeparator string = ":"
 )
 
+// Status codes returned by redisInsertScript.
+const (
+	redisInsertOk int64 = iota
+	redisInsertKeyExists
+	redisInsertVersionMismatch
+)
+
+// redisInsertScript atomically checks an Insert's conditions against the key's current version,
+// and sets the key to "<version + 1>:<data>" if they're met.
+// KEYS[1] is the key. ARGV is the encoded data, the TTL in milliseconds (0 for no expiry),
+// "1" if the key must not exist, and the expected version ("" to skip the check).
+// Returns {status, version}, where version is the new version if the key was set,
+// and the current version otherwise.
+var redisInsertScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+local version = 0
+if current then
+	local sep = string.find(current, ":", 1, true)
+	if sep then
+		version = tonumber(string.sub(current, 1, sep - 1)) or 0
+	end
+	if ARGV[3] == "1" then
+		return {1, version}
+	end
+end
+if ARGV[4] ~= "" and tonumber(ARGV[4]) ~= version then
+	return {2, version}
+end
+local value = string.format("%d", version + 1) .. ":" .. ARGV[1]
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], value, "PX", ttl)
+else
+	redis.call("SET", KEYS[1], value)
+end
+return {0, version + 1}
+`)
+
 type redisDatastore[T any] struct {
 	*baseDatastore[T]
 	client       *redis.Client
@@ -85,25 +129,55 @@ func (d *redisDatastore[T]) CheckTtl(ctx context.Context, key string) (time.Dura
 }
 
 func (d *redisDatastore[T]) Get(ctx context.Context, key string) (*T, bool, error) {
+	data, _, ok, err := d.GetVersioned(ctx, key)
+	return data, ok, err
+}
+
+func (d *redisDatastore[T]) GetVersioned(ctx context.Context, key string) (*T, uint64, bool, error) {
 	res, err := d.client.Get(ctx, d.prefixKey(key)).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, false, nil
+			return nil, 0, false, nil
 		}
 
-		return nil, false, err
+		return nil, 0, false, err
 	}
 
-	return fullDecode[T](res)
+	return decodeVersioned[T](res)
 }
 
-func (d *redisDatastore[T]) Insert(ctx context.Context, key string, data T) error {
+func (d *redisDatastore[T]) Insert(ctx context.Context, key string, data T, opts ...InsertOption) error {
+	o := newInsertOptions(opts)
+
+	ttl, err := o.resolveTtl(d.DefaultTtl(), time.Now())
+	if err != nil {
+		return err
+	}
+
 	encoded, err := fullEncode[T](data)
 	if err != nil {
 		return err
 	}
 
-	return d.client.Set(ctx, d.prefixKey(key), encoded, d.DefaultTtl()).Err()
+	res, err := redisInsertScript.Run(ctx, d.client, []string{d.prefixKey(key)}, insertScriptArgs(encoded, ttl, o)...).Int64Slice()
+	if err != nil {
+		return err
+	}
+
+	if len(res) != 2 {
+		return errors.Errorf("unexpected insert script result %[1]v", res)
+	}
+
+	switch res[0] {
+	case redisInsertOk:
+		return nil
+	case redisInsertKeyExists:
+		return ErrKeyExists
+	case redisInsertVersionMismatch:
+		return versionMismatch(*o.ifVersion, uint64(res[1]))
+	default:
+		return errors.Errorf("unexpected insert script status %[1]d", res[0])
+	}
 }
 
 func (d *redisDatastore[T]) Delete(ctx context.Context, key string) error {
@@ -111,6 +185,248 @@ func (d *redisDatastore[T]) Delete(ctx context.Context, key string) error {
 	return err
 }
 
+func (d *redisDatastore[T]) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+
+	err := d.scanKeys(ctx, prefix, func(page []string) error {
+		for _, key := range page {
+			keys = append(keys, d.unprefixKey(key))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (d *redisDatastore[T]) Scan(ctx context.Context, prefix string, fn func(key string, data *T) error) error {
+	return d.scanKeys(ctx, prefix, func(page []string) error {
+		values, err := d.client.MGet(ctx, page...).Result()
+		if err != nil {
+			return err
+		}
+
+		for i, value := range values {
+			data, ok, decodeErr := decodeRedisValue[T](value)
+			if decodeErr != nil {
+				return errors.WithMessagef(decodeErr, "key %[1]s", page[i])
+			}
+
+			if !ok {
+				continue
+			}
+
+			if fnErr := fn(d.unprefixKey(page[i]), data); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		return nil
+	})
+}
+
+func (d *redisDatastore[T]) GetMany(ctx context.Context, keys []string) (map[string]*T, error) {
+	res := make(map[string]*T, len(keys))
+
+	if len(keys) == 0 {
+		return res, nil
+	}
+
+	prefixedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		prefixedKeys[i] = d.prefixKey(key)
+	}
+
+	values, err := d.client.MGet(ctx, prefixedKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		data, ok, decodeErr := decodeRedisValue[T](value)
+		if decodeErr != nil {
+			return nil, errors.WithMessagef(decodeErr, "key %[1]s", keys[i])
+		}
+
+		if ok {
+			res[keys[i]] = data
+		}
+	}
+
+	return res, nil
+}
+
+func (d *redisDatastore[T]) InsertMany(ctx context.Context, data map[string]T) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	encoded := make(map[string]string, len(data))
+
+	for key, value := range data {
+		encodedValue, err := fullEncode[T](value)
+		if err != nil {
+			return errors.WithMessagef(err, "key %[1]s", key)
+		}
+
+		encoded[key] = encodedValue
+	}
+
+	ttl := d.DefaultTtl()
+
+	_, err := d.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range encoded {
+			// Scripts can't be loaded from within a pipeline, so they're sent in full.
+			redisInsertScript.Eval(ctx, pipe, []string{d.prefixKey(key)}, insertScriptArgs(value, ttl, insertOptions{})...)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (d *redisDatastore[T]) DeleteByPrefix(ctx context.Context, prefix string) (int, error) {
+	var deleted int
+
+	err := d.scanKeys(ctx, prefix, func(page []string) error {
+		n, err := d.client.Del(ctx, page...).Result()
+		if err != nil {
+			return err
+		}
+
+		deleted += int(n)
+
+		return nil
+	})
+
+	return deleted, err
+}
+
+// scanKeys calls fn with each page of (prefixed) keys starting with the passed prefix,
+// as returned by SCAN. Each key is only passed once.
+func (d *redisDatastore[T]) scanKeys(ctx context.Context, prefix string, fn func(page []string) error) error {
+	var (
+		cursor uint64
+		seen   = make(map[string]bool)
+		match  = escapeScanPattern(d.prefixKey(prefix)) + "*"
+	)
+
+	for {
+		res, nextCursor, err := d.client.Scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		// SCAN can return a key more than once.
+		page := make([]string, 0, len(res))
+
+		for _, key := range res {
+			if !seen[key] {
+				seen[key] = true
+				page = append(page, key)
+			}
+		}
+
+		if len(page) > 0 {
+			if fnErr := fn(page); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if nextCursor == 0 {
+			return nil
+		}
+
+		cursor = nextCursor
+	}
+}
+
 func (d *redisDatastore[T]) UpdateTtl(ctx context.Context, key string, newTtl time.Duration) (bool, error) {
 	return d.client.Expire(ctx, d.prefixKey(key), newTtl).Result()
 }
+
+// escapeScanPattern escapes the glob-style special characters of the passed string,
+// so that it's matched literally by SCAN.
+func escapeScanPattern(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// decodeRedisValue decodes a value returned by MGET,
+// which is nil for keys which don't exist.
+func decodeRedisValue[T any](value any) (*T, bool, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, false, nil
+	}
+
+	data, _, ok, err := decodeVersioned[T](str)
+
+	return data, ok, err
+}
+
+// decodeVersioned decodes a stored "<version>:<data>" value.
+// Values stored before versioning was added have no version, and are at version 0.
+// Base64-encoded data never contains the separator, so the two can't be confused.
+func decodeVersioned[T any](value string) (*T, uint64, bool, error) {
+	encoded, version, err := splitVersion(value)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	data, ok, err := fullDecode[T](encoded)
+
+	return data, version, ok, err
+}
+
+func splitVersion(value string) (string, uint64, error) {
+	rawVersion, encoded, found := strings.Cut(value, redisVersionSeparator)
+	if !found {
+		return value, 0, nil
+	}
+
+	version, err := strconv.ParseUint(rawVersion, 10, 64)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "error parsing version %[1]q", rawVersion)
+	}
+
+	return encoded, version, nil
+}
+
+// insertScriptArgs returns the ARGV passed to redisInsertScript.
+func insertScriptArgs(encoded string, ttl time.Duration, o insertOptions) []any {
+	var (
+		ttlMs     int64
+		ifAbsent  = "0"
+		ifVersion string
+	)
+
+	if ttl > 0 {
+		// PX doesn't accept 0, so sub-millisecond TTLs are rounded up.
+		ttlMs = int64((ttl + time.Millisecond - 1) / time.Millisecond)
+	}
+
+	if o.ifAbsent {
+		ifAbsent = "1"
+	}
+
+	if o.ifVersion != nil {
+		ifVersion = strconv.FormatUint(*o.ifVersion, 10)
+	}
+
+	return []any{encoded, ttlMs, ifAbsent, ifVersion}
+}
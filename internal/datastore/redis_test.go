@@ -0,0 +1,248 @@
+package datastore
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+)
+
+// newTestRedisDatastore returns a Redis datastore backed by an in-process Redis server,
+// using the passed key prefix.
+func newTestRedisDatastore(t *testing.T, keyPrefix string) (*miniredis.Miniredis, Datastore[testEntry]) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+
+	port, err := strconv.Atoi(server.Port())
+	require.NoError(t, err)
+
+	ds, err := NewRedisDatastore[testEntry](&Config{
+		KeyPrefix: utils.ToPointer(keyPrefix),
+		Redis: &RedisDatastoreConfig{
+			Host: utils.ToPointer(server.Host()),
+			Port: utils.ToPointer(port),
+		},
+	})
+	require.NoError(t, err)
+
+	return server, ds
+}
+
+func TestRedisDatastore_BatchOperations(t *testing.T) {
+	ctx := context.Background()
+
+	// Glob characters in the key prefix only match themselves.
+	server, ds := newTestRedisDatastore(t, "stuff*notifier")
+
+	// Keys outside of the datastore's key prefix are never returned or deleted.
+	require.NoError(t, server.Set("stuffxnotifier:flightdata:UAL9", "1:foreign"))
+	require.NoError(t, server.Set("flightdata:UAL9", "1:foreign"))
+
+	require.NoError(t, ds.InsertMany(ctx, map[string]testEntry{
+		"flightdata:UAL1": {Name: "UAL1", Count: 1},
+		"flightdata:UAL2": {Name: "UAL2", Count: 2},
+		"gemini:ETHUSD":   {Name: "ETHUSD", Count: 3},
+	}))
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL3", testEntry{Name: "UAL3", Count: 4}))
+
+	// Keys inserted in a batch are versioned like any other.
+	_, version, ok, err := ds.GetVersioned(ctx, "flightdata:UAL1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), version)
+
+	keys, err := ds.Keys(ctx, "flightdata:")
+	require.NoError(t, err)
+	sort.Strings(keys)
+	assert.Equal(t, []string{"flightdata:UAL1", "flightdata:UAL2", "flightdata:UAL3"}, keys)
+
+	scanned := make(map[string]testEntry)
+	require.NoError(t, ds.Scan(ctx, "gemini:", func(key string, data *testEntry) error {
+		scanned[key] = *data
+		return nil
+	}))
+	assert.Equal(t, map[string]testEntry{"gemini:ETHUSD": {Name: "ETHUSD", Count: 3}}, scanned)
+
+	stop := errors.New("stop")
+	assert.Equal(t, stop, ds.Scan(ctx, "", func(string, *testEntry) error { return stop }))
+
+	got, err := ds.GetMany(ctx, []string{"flightdata:UAL1", "flightdata:UAL9", "gemini:ETHUSD"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*testEntry{
+		"flightdata:UAL1": {Name: "UAL1", Count: 1},
+		"gemini:ETHUSD":   {Name: "ETHUSD", Count: 3},
+	}, got)
+
+	require.NoError(t, ds.Delete(ctx, "flightdata:UAL2"))
+
+	deleted, err := ds.DeleteByPrefix(ctx, "flightdata:")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	keys, err = ds.Keys(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gemini:ETHUSD"}, keys)
+
+	assert.True(t, server.Exists("stuffxnotifier:flightdata:UAL9"))
+	assert.True(t, server.Exists("flightdata:UAL9"))
+}
+
+func TestRedisDatastore_ConditionalInsert(t *testing.T) {
+	ctx := context.Background()
+
+	server, ds := newTestRedisDatastore(t, DefaultKeyPrefix)
+
+	_, version, ok, err := ds.GetVersioned(ctx, "flightdata:UAL1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Zero(t, version)
+
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 1}, IfAbsent()))
+	assert.ErrorIs(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 2}, IfAbsent()), ErrKeyExists)
+
+	data, version, ok, err := ds.GetVersioned(ctx, "flightdata:UAL1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, testEntry{Name: "UAL1", Count: 1}, *data)
+	assert.Equal(t, uint64(1), version)
+
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 3}, IfVersion(1)))
+
+	err = ds.Insert(ctx, "flightdata:UAL1", testEntry{Name: "UAL1", Count: 4}, IfVersion(1))
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	assert.Contains(t, err.Error(), "expected version 1, got 2")
+
+	data, version, _, err = ds.GetVersioned(ctx, "flightdata:UAL1")
+	require.NoError(t, err)
+	assert.Equal(t, testEntry{Name: "UAL1", Count: 3}, *data)
+	assert.Equal(t, uint64(2), version)
+
+	// Keys which don't exist are at version 0.
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL2", testEntry{Name: "UAL2"}, IfVersion(0)))
+	assert.ErrorIs(t, ds.Insert(ctx, "flightdata:UAL3", testEntry{Name: "UAL3"}, IfVersion(1)), ErrVersionMismatch)
+
+	_, ok, err = ds.Get(ctx, "flightdata:UAL3")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Values written before versioning are at version 0, and are versioned once they're next inserted.
+	require.NoError(t, server.Set(DefaultKeyPrefix+":flightdata:UAL4", mustEncode(t, testEntry{Name: "UAL4"})))
+
+	_, version, ok, err = ds.GetVersioned(ctx, "flightdata:UAL4")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Zero(t, version)
+
+	assert.ErrorIs(t, ds.Insert(ctx, "flightdata:UAL4", testEntry{Name: "UAL4"}, IfAbsent()), ErrKeyExists)
+	require.NoError(t, ds.Insert(ctx, "flightdata:UAL4", testEntry{Name: "UAL4", Count: 1}, IfVersion(0)))
+
+	_, version, _, err = ds.GetVersioned(ctx, "flightdata:UAL4")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+}
+
+func TestRedisDatastore_InsertTtl(t *testing.T) {
+	ctx := context.Background()
+
+	server, ds := newTestRedisDatastore(t, DefaultKeyPrefix)
+
+	require.NoError(t, ds.Insert(ctx, "ttl", testEntry{}, WithTtl(time.Hour)))
+	ttl, ok, err := ds.CheckTtl(ctx, "ttl")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Minute))
+
+	require.NoError(t, ds.Insert(ctx, "expiry", testEntry{}, WithExpiry(time.Now().Add(2*time.Hour))))
+	ttl, ok, err = ds.CheckTtl(ctx, "expiry")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.InDelta(t, 2*time.Hour, ttl, float64(time.Minute))
+
+	require.NoError(t, ds.Insert(ctx, "forever", testEntry{}, WithTtl(0)))
+	ttl, ok, err = ds.CheckTtl(ctx, "forever")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Zero(t, ttl)
+
+	assert.Error(t, ds.Insert(ctx, "past", testEntry{}, WithExpiry(time.Now().Add(-time.Minute))))
+	assert.Error(t, ds.Insert(ctx, "negative", testEntry{}, WithTtl(-time.Minute)))
+
+	// Once a key expires, it's absent and back at version 0.
+	server.FastForward(time.Hour + time.Second)
+
+	_, ok, err = ds.CheckTtl(ctx, "ttl")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.ErrorIs(t, ds.Insert(ctx, "ttl", testEntry{}, IfVersion(1)), ErrVersionMismatch)
+	require.NoError(t, ds.Insert(ctx, "ttl", testEntry{Count: 1}, IfAbsent(), WithTtl(time.Hour)))
+
+	data, version, ok, err := ds.GetVersioned(ctx, "ttl")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, testEntry{Count: 1}, *data)
+	assert.Equal(t, uint64(1), version)
+}
+
+// mustEncode returns the passed data encoded as the Redis datastore stored it before values were versioned.
+func mustEncode(t *testing.T, data testEntry) string {
+	t.Helper()
+
+	encoded, err := fullEncode[testEntry](data)
+	require.NoError(t, err)
+
+	return encoded
+}
+
+func TestEscapeScanPattern(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "lavamonster:flightdata:", want: "lavamonster:flightdata:"},
+		{in: "lavamonster:gemini:*", want: `lavamonster:gemini:\*`},
+		{in: `a?b[c]d\e`, want: `a\?b\[c\]d\\e`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, escapeScanPattern(tt.in), tt.in)
+	}
+}
+
+func TestSplitVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		wantEncoded string
+		wantVersion uint64
+		wantErr     bool
+	}{
+		{name: "versioned", in: "3:Zm9vYmFy", wantEncoded: "Zm9vYmFy", wantVersion: 3},
+		{name: "unversioned", in: "Zm9vYmFy", wantEncoded: "Zm9vYmFy", wantVersion: 0},
+		{name: "invalid version", in: "x:Zm9vYmFy", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, version, err := splitVersion(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantEncoded, encoded)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
@@ -12,6 +12,7 @@ const (
 	GeminiKey     string = "GEMINI_API_KEY"
 	GeminiSecret  string = "GEMINI_API_SECRET"
 	GeminiSandbox string = "GEMINI_SANDBOX"
+	GeminiBaseUrl string = "GEMINI_BASE_URL"
 )
 
 const (
@@ -19,19 +20,27 @@ const (
 )
 
 const (
-	TwilioSid    string = "TWILIO_ACCOUNT_SID"
-	TwilioKey    string = "TWILIO_API_KEY"
-	TwilioSecret string = "TWILIO_API_SECRET"
-	TwilioToken  string = "TWILIO_API_TOKEN"
-	TwilioSender string = "TWILIO_SENDER"
+	TwilioSid     string = "TWILIO_ACCOUNT_SID"
+	TwilioKey     string = "TWILIO_API_KEY"
+	TwilioSecret  string = "TWILIO_API_SECRET"
+	TwilioToken   string = "TWILIO_API_TOKEN"
+	TwilioSender  string = "TWILIO_SENDER"
+	TwilioBaseUrl string = "TWILIO_BASE_URL"
 )
 
 const (
-	FlightAwareKey string = "FLIGHTAWARE_API_KEY"
+	FlightAwareKey     string = "FLIGHTAWARE_API_KEY"
+	FlightAwareBaseUrl string = "FLIGHTAWARE_BASE_URL"
 )
 
 const (
-	SlackToken string = "SLACK_TOKEN"
+	SlackToken   string = "SLACK_TOKEN"
+	SlackBaseUrl string = "SLACK_BASE_URL"
+)
+
+const (
+	SmtpUsername string = "SMTP_USERNAME"
+	SmtpPassword string = "SMTP_PASSWORD"
 )
 
 const (
@@ -95,6 +104,16 @@ func GeminiUseSandbox() bool {
 	return false
 }
 
+// BaseUrl returns the API base URL set in the environment under envKey,
+// or fallback if it isn't set (or is empty).
+func BaseUrl(envKey string, fallback string) string {
+	if val, _ := FromEnv(envKey); val != "" {
+		return val
+	}
+
+	return fallback
+}
+
 func TwilioFromNumber() (string, error) {
 	return FromEnv(TwilioSender)
 }
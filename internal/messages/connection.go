@@ -0,0 +1,138 @@
+package messages
+
+import (
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const ConnectionAlertKind string = "connection_alert"
+
+// ConnectionAlert is sent when a connection between two legs
+// of an itinerary is at risk.
+type ConnectionAlert struct {
+	baseMessage
+	// Estimated arrival time of the inbound leg.
+	InboundArrival time.Time
+	// Estimated departure time of the outbound leg.
+	OutboundDeparture time.Time
+	plaintextTemplate *template.Template
+	markdownTemplate  *template.Template
+	htmlTemplate      *htmltemplate.Template
+	// Airport the connection is made at.
+	Airport           FlightAwareAirportInfo
+	ItineraryName     string
+	InboundFlight     string
+	OutboundFlight    string
+	ConnectionTime    time.Duration
+	MinConnectionTime time.Duration
+	IsAtRisk          bool
+	IsInboundDiverted bool
+	// If true, the inbound leg has been cancelled.
+	IsInboundCancelled bool
+	UseLocalTimezone   bool
+}
+
+// IsMissed returns true if the outbound leg is estimated
+// to depart before the inbound leg arrives.
+func (a ConnectionAlert) IsMissed() bool {
+	return a.ConnectionTime < 0
+}
+
+func (a ConnectionAlert) FormatPlaintext() string {
+	msg, err := a.format(a.PlaintextTemplate(), a)
+	if err != nil {
+		logger.Panic("error formatting ConnectionAlert plaintext template", zap.Error(err))
+	}
+
+	return msg
+}
+
+func (a ConnectionAlert) FormatMarkdown() string {
+	msg, err := a.format(a.MarkdownTemplate(), a)
+	if err != nil {
+		logger.Panic("error formatting ConnectionAlert markdown template", zap.Error(err))
+	}
+
+	return msg
+}
+
+func (a ConnectionAlert) FormatHTML() string {
+	msg, err := a.format(a.HTMLTemplate(), a)
+	if err != nil {
+		logger.Panic("error formatting ConnectionAlert html template", zap.Error(err))
+	}
+
+	return msg
+}
+
+// SetTemplates sets the alert's plaintext, markdown, and html templates.
+// Any nil templates fall back to the built-in templates.
+func (a *ConnectionAlert) SetTemplates(tmpls MessageTemplates) *ConnectionAlert {
+	a.plaintextTemplate = tmpls.Plaintext
+	a.markdownTemplate = tmpls.Markdown
+	a.htmlTemplate = tmpls.HTML
+
+	return a
+}
+
+func (a ConnectionAlert) PlaintextTemplate() *template.Template {
+	if a.plaintextTemplate != nil {
+		return a.plaintextTemplate
+	}
+
+	return ConnectionAlertPlaintextTemplate
+}
+
+func (a ConnectionAlert) MarkdownTemplate() *template.Template {
+	if a.markdownTemplate != nil {
+		return a.markdownTemplate
+	}
+
+	return ConnectionAlertMarkdownTemplate
+}
+
+func (a ConnectionAlert) HTMLTemplate() *htmltemplate.Template {
+	if a.htmlTemplate != nil {
+		return a.htmlTemplate
+	}
+
+	return ConnectionAlertHTMLTemplate
+}
+
+// ConnectionAlertData is the structured representation of a ConnectionAlert.
+type ConnectionAlertData struct {
+	InboundArrival     *time.Time             `json:"inbound_arrival,omitempty"`
+	OutboundDeparture  *time.Time             `json:"outbound_departure,omitempty"`
+	Airport            FlightAwareAirportData `json:"airport"`
+	ItineraryName      string                 `json:"itinerary_name,omitempty"`
+	InboundFlight      string                 `json:"inbound_flight"`
+	OutboundFlight     string                 `json:"outbound_flight"`
+	ConnectionTime     int64                  `json:"connection_time"`
+	MinConnectionTime  int64                  `json:"min_connection_time"`
+	IsAtRisk           bool                   `json:"is_at_risk"`
+	IsInboundDiverted  bool                   `json:"is_inbound_diverted"`
+	IsInboundCancelled bool                   `json:"is_inbound_cancelled"`
+}
+
+func (a ConnectionAlert) Kind() string {
+	return ConnectionAlertKind
+}
+
+func (a ConnectionAlert) Structured() any {
+	return ConnectionAlertData{
+		InboundArrival:     optionalTime(a.InboundArrival),
+		OutboundDeparture:  optionalTime(a.OutboundDeparture),
+		Airport:            FlightAwareAirportData(a.Airport),
+		ItineraryName:      a.ItineraryName,
+		InboundFlight:      a.InboundFlight,
+		OutboundFlight:     a.OutboundFlight,
+		ConnectionTime:     int64(a.ConnectionTime.Seconds()),
+		MinConnectionTime:  int64(a.MinConnectionTime.Seconds()),
+		IsAtRisk:           a.IsAtRisk,
+		IsInboundDiverted:  a.IsInboundDiverted,
+		IsInboundCancelled: a.IsInboundCancelled,
+	}
+}
@@ -1,13 +1,17 @@
 package messages
 
 import (
+	htmltemplate "html/template"
 	"text/template"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
 )
 
+const FlightAwareAlertKind string = "flightaware_alert"
+
 type FlightAwareAlert struct {
 	baseMessage
 	GateArrivalTime   flightaware.FlightTimestamp
@@ -15,14 +19,41 @@ type FlightAwareAlert struct {
 	TakeoffTime       flightaware.FlightTimestamp
 	GateDepartureTime flightaware.FlightTimestamp
 	plaintextTemplate *template.Template
+	markdownTemplate  *template.Template
+	htmlTemplate      *htmltemplate.Template
 	Origin            FlightAwareAirportInfo
 	Destination       FlightAwareAirportInfo
-	FlightNumber      string
-	IsLanding         bool
-	IsGateArrival     bool
-	IsTakeoff         bool
-	IsGateDeparture   bool
-	UseLocalTimezone  bool
+	// Origin/Destination info prior to a gate change.
+	PreviousOrigin      FlightAwareAirportInfo
+	PreviousDestination FlightAwareAirportInfo
+	FlightNumber        string
+	// Code of the airport a diverted flight is now flying to, if known.
+	DivertedTo string
+	// Flight number of the inbound flight operated by the flight's aircraft.
+	InboundFlight string
+	// Baggage claim carousel at the destination.
+	BaggageClaim string
+	// Estimated gate arrival time of the inbound flight.
+	InboundArrival time.Time
+	DepartureDelay time.Duration
+	ArrivalDelay   time.Duration
+	// Departure delay predicted from the inbound flight's arrival.
+	PredictedDepartureDelay time.Duration
+	IsLanding               bool
+	IsGateArrival           bool
+	IsTakeoff               bool
+	IsGateDeparture         bool
+	IsGateChange            bool
+	IsDelay                 bool
+	IsCancelled             bool
+	IsDiverted              bool
+	IsInboundLate           bool
+	IsBaggageClaim          bool
+	// If true, the alert summarizes several of the flight's lifecycle events
+	// (set with IsGateDeparture, IsTakeoff, IsDiverted, IsLanding, and IsGateArrival)
+	// which happened since the last update.
+	IsCatchUp        bool
+	UseLocalTimezone bool
 }
 
 type FlightAwareAirportInfo struct {
@@ -32,6 +63,18 @@ type FlightAwareAirportInfo struct {
 	Terminal string
 }
 
+// OriginGateChanged returns true if the alert's origin gate
+// or terminal differs from its previous origin gate or terminal.
+func (a FlightAwareAlert) OriginGateChanged() bool {
+	return a.Origin.Gate != a.PreviousOrigin.Gate || a.Origin.Terminal != a.PreviousOrigin.Terminal
+}
+
+// DestinationGateChanged returns true if the alert's destination gate
+// or terminal differs from its previous destination gate or terminal.
+func (a FlightAwareAlert) DestinationGateChanged() bool {
+	return a.Destination.Gate != a.PreviousDestination.Gate || a.Destination.Terminal != a.PreviousDestination.Terminal
+}
+
 func (a FlightAwareAlert) FormatPlaintext() string {
 	msg, err := a.format(a.PlaintextTemplate(), a)
 	if err != nil {
@@ -41,15 +84,37 @@ func (a FlightAwareAlert) FormatPlaintext() string {
 	return msg
 }
 
+// FormatMarkdown returns the alert formatted using its markdown template.
+// If no markdown template is set, the plaintext formatting is returned.
 func (a FlightAwareAlert) FormatMarkdown() string {
-	// msg, err := a.format(a.MarkdownTemplate(), a)
-	// if err != nil {
-	// 	panic(err)
-	// }
-	//
-	// return msg
+	if a.MarkdownTemplate() == nil {
+		return a.FormatPlaintext()
+	}
+
+	msg, err := a.format(a.MarkdownTemplate(), a)
+	if err != nil {
+		logger.Panic("error formatting FlightAwareAlert markdown template", zap.Error(err))
+	}
+
+	return msg
+}
 
-	return ""
+func (a FlightAwareAlert) FormatHTML() string {
+	msg, err := a.format(a.HTMLTemplate(), a)
+	if err != nil {
+		logger.Panic("error formatting FlightAwareAlert html template", zap.Error(err))
+	}
+
+	return msg
+}
+
+// SetTemplates sets the alert's plaintext, markdown, and html templates.
+func (a *FlightAwareAlert) SetTemplates(tmpls MessageTemplates) *FlightAwareAlert {
+	a.plaintextTemplate = tmpls.Plaintext
+	a.markdownTemplate = tmpls.Markdown
+	a.htmlTemplate = tmpls.HTML
+
+	return a
 }
 
 func (a FlightAwareAlert) PlaintextTemplate() *template.Template {
@@ -62,5 +127,124 @@ func (a *FlightAwareAlert) SetPlaintextTemplate(tmpl *template.Template) *Flight
 }
 
 func (a FlightAwareAlert) MarkdownTemplate() *template.Template {
-	return nil
+	return a.markdownTemplate
+}
+
+func (a *FlightAwareAlert) SetMarkdownTemplate(tmpl *template.Template) *FlightAwareAlert {
+	a.markdownTemplate = tmpl
+	return a
+}
+
+func (a FlightAwareAlert) HTMLTemplate() *htmltemplate.Template {
+	return a.htmlTemplate
+}
+
+func (a *FlightAwareAlert) SetHTMLTemplate(tmpl *htmltemplate.Template) *FlightAwareAlert {
+	a.htmlTemplate = tmpl
+	return a
+}
+
+// FlightAwareAlertData is the structured representation of a FlightAwareAlert.
+type FlightAwareAlertData struct {
+	GateDepartureTime FlightTimestampData    `json:"gate_departure_time"`
+	TakeoffTime       FlightTimestampData    `json:"takeoff_time"`
+	LandingTime       FlightTimestampData    `json:"landing_time"`
+	GateArrivalTime   FlightTimestampData    `json:"gate_arrival_time"`
+	Origin            FlightAwareAirportData `json:"origin"`
+	Destination       FlightAwareAirportData `json:"destination"`
+	// Only set for gate change alerts.
+	PreviousOrigin      *FlightAwareAirportData `json:"previous_origin,omitempty"`
+	PreviousDestination *FlightAwareAirportData `json:"previous_destination,omitempty"`
+	FlightNumber        string                  `json:"flight_number"`
+	DivertedTo          string                  `json:"diverted_to,omitempty"`
+	// Only set for inbound aircraft alerts.
+	InboundFlight  string     `json:"inbound_flight,omitempty"`
+	InboundArrival *time.Time `json:"inbound_arrival,omitempty"`
+	BaggageClaim   string     `json:"baggage_claim,omitempty"`
+	// Delays, in seconds.
+	DepartureDelay          int64 `json:"departure_delay"`
+	ArrivalDelay            int64 `json:"arrival_delay"`
+	PredictedDepartureDelay int64 `json:"predicted_departure_delay,omitempty"`
+	IsGateDeparture         bool  `json:"is_gate_departure"`
+	IsTakeoff               bool  `json:"is_takeoff"`
+	IsLanding               bool  `json:"is_landing"`
+	IsGateArrival           bool  `json:"is_gate_arrival"`
+	IsGateChange            bool  `json:"is_gate_change"`
+	IsDelay                 bool  `json:"is_delay"`
+	IsCancelled             bool  `json:"is_cancelled"`
+	IsDiverted              bool  `json:"is_diverted"`
+	IsInboundLate           bool  `json:"is_inbound_late"`
+	IsBaggageClaim          bool  `json:"is_baggage_claim"`
+	IsCatchUp               bool  `json:"is_catch_up"`
+}
+
+// FlightTimestampData is the structured representation of a flightaware.FlightTimestamp.
+// Unset timestamps are omitted.
+type FlightTimestampData struct {
+	Scheduled *time.Time `json:"scheduled,omitempty"`
+	Estimated *time.Time `json:"estimated,omitempty"`
+	Actual    *time.Time `json:"actual,omitempty"`
+}
+
+// FlightAwareAirportData is the structured representation of a FlightAwareAirportInfo.
+type FlightAwareAirportData struct {
+	Airport  string `json:"airport"`
+	Timezone string `json:"timezone,omitempty"`
+	Gate     string `json:"gate,omitempty"`
+	Terminal string `json:"terminal,omitempty"`
+}
+
+func (a FlightAwareAlert) Kind() string {
+	return FlightAwareAlertKind
+}
+
+func (a FlightAwareAlert) Structured() any {
+	data := FlightAwareAlertData{
+		GateDepartureTime: newFlightTimestampData(a.GateDepartureTime),
+		TakeoffTime:       newFlightTimestampData(a.TakeoffTime),
+		LandingTime:       newFlightTimestampData(a.LandingTime),
+		GateArrivalTime:   newFlightTimestampData(a.GateArrivalTime),
+		Origin:            FlightAwareAirportData(a.Origin),
+		Destination:       FlightAwareAirportData(a.Destination),
+		FlightNumber:      a.FlightNumber,
+		IsGateDeparture:   a.IsGateDeparture,
+		IsTakeoff:         a.IsTakeoff,
+		IsLanding:         a.IsLanding,
+		IsGateArrival:     a.IsGateArrival,
+		IsGateChange:      a.IsGateChange,
+		IsDelay:           a.IsDelay,
+		IsCancelled:       a.IsCancelled,
+		IsDiverted:        a.IsDiverted,
+		DivertedTo:        a.DivertedTo,
+		DepartureDelay:    int64(a.DepartureDelay.Seconds()),
+		ArrivalDelay:      int64(a.ArrivalDelay.Seconds()),
+		IsInboundLate:     a.IsInboundLate,
+		IsBaggageClaim:    a.IsBaggageClaim,
+		BaggageClaim:      a.BaggageClaim,
+		IsCatchUp:         a.IsCatchUp,
+	}
+
+	if a.IsInboundLate {
+		data.InboundFlight = a.InboundFlight
+		data.InboundArrival = optionalTime(a.InboundArrival)
+		data.PredictedDepartureDelay = int64(a.PredictedDepartureDelay.Seconds())
+	}
+
+	if a.IsGateChange {
+		previousOrigin := FlightAwareAirportData(a.PreviousOrigin)
+		previousDestination := FlightAwareAirportData(a.PreviousDestination)
+
+		data.PreviousOrigin = &previousOrigin
+		data.PreviousDestination = &previousDestination
+	}
+
+	return data
+}
+
+func newFlightTimestampData(ts flightaware.FlightTimestamp) FlightTimestampData {
+	return FlightTimestampData{
+		Scheduled: optionalTime(ts.Scheduled),
+		Estimated: optionalTime(ts.Estimated),
+		Actual:    optionalTime(ts.Actual),
+	}
 }
@@ -2,6 +2,7 @@ package messages_test
 
 import (
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -127,7 +128,204 @@ func TestFlightAwareDepartureAlert_FormatPlaintext(t *testing.T) {
 				LandingTime:       flightaware.FlightTimestamp{Estimated: tt.fields.EstimatedLandingTime},
 			}
 
-			_ = a.FormatPlaintext()
+			a.SetPlaintextTemplate(messages.DepartureAlertPlaintextTemplate)
+
+			assert.Contains(t, a.FormatPlaintext(), "Flight UA2614")
+		})
+	}
+}
+
+func TestFlightAwareAlert_FormatMarkdown(t *testing.T) {
+	base := messages.FlightAwareAlert{
+		FlightNumber: "UA2614",
+		Origin: messages.FlightAwareAirportInfo{
+			Airport:  "LAX",
+			Gate:     "37",
+			Timezone: "America/Los_Angeles",
+		},
+		Destination: messages.FlightAwareAirportInfo{
+			Airport:  "EWR",
+			Gate:     "C71",
+			Terminal: "C",
+			Timezone: "America/New_York",
+		},
+		GateDepartureTime: flightaware.FlightTimestamp{
+			Scheduled: mustParseTime(t, "2022-05-31 12:30:00 (-0700)"),
+			Estimated: mustParseTime(t, "2022-05-31 12:35:00 (-0700)"),
+			Actual:    mustParseTime(t, "2022-05-31 12:35:00 (-0700)"),
+		},
+		TakeoffTime: flightaware.FlightTimestamp{Actual: mustParseTime(t, "2022-05-31 12:40:00 (-0700)")},
+		LandingTime: flightaware.FlightTimestamp{
+			Estimated: mustParseTime(t, "2022-05-31 20:53:00 (-0400)"),
+			Actual:    mustParseTime(t, "2022-05-31 20:50:00 (-0400)"),
+		},
+		GateArrivalTime: flightaware.FlightTimestamp{
+			Estimated: mustParseTime(t, "2022-05-31 21:00:00 (-0400)"),
+			Actual:    mustParseTime(t, "2022-05-31 21:02:00 (-0400)"),
+		},
+	}
+
+	tests := []struct {
+		tmpl   *template.Template
+		modify func(a *messages.FlightAwareAlert)
+		name   string
+		want   []string
+	}{
+		{
+			name:   "gate departure",
+			tmpl:   messages.DepartureAlertMarkdownTemplate,
+			modify: func(a *messages.FlightAwareAlert) { a.IsGateDeparture = true },
+			want:   []string{"*Flight Information Update*", "Flight *UA2614* departed from *LAX* gate *37*"},
+		},
+		{
+			name:   "takeoff",
+			tmpl:   messages.DepartureAlertMarkdownTemplate,
+			modify: func(a *messages.FlightAwareAlert) { a.IsTakeoff = true },
+			want:   []string{"Flight *UA2614* took off from *LAX*", "Estimated landing time at *EWR*"},
+		},
+		{
+			name:   "landing",
+			tmpl:   messages.ArrivalAlertMarkdownTemplate,
+			modify: func(a *messages.FlightAwareAlert) { a.IsLanding = true },
+			want:   []string{"Flight *UA2614* landed at *EWR*", "Estimated gate arrival time"},
+		},
+		{
+			name:   "gate arrival",
+			tmpl:   messages.ArrivalAlertMarkdownTemplate,
+			modify: func(a *messages.FlightAwareAlert) { a.IsGateArrival = true },
+			want:   []string{"Flight *UA2614* arrived at *EWR* (terminal *C* gate *C71*)"},
+		},
+		{
+			name: "pre-departure",
+			tmpl: messages.PreDepartureAlertMarkdownTemplate,
+			want: []string{"*Flight Pre-Departure Alert*", "Estimated departure time from gate *37*"},
+		},
+		{
+			name: "pre-arrival",
+			tmpl: messages.PreArrivalAlertMarkdownTemplate,
+			want: []string{"*Flight Pre-Arrival Alert*", "Estimated arrival at terminal *C* gate *C71*"},
+		},
+		{
+			name: "catch-up",
+			tmpl: messages.CatchUpAlertMarkdownTemplate,
+			modify: func(a *messages.FlightAwareAlert) {
+				a.IsCatchUp = true
+				a.IsLanding = true
+				a.IsGateArrival = true
+			},
+			want: []string{
+				"*Flight Status Catch-Up*",
+				"• landed at *EWR*",
+				"• arrived at *EWR* (terminal *C* gate *C71*)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			a := base
+			if tt.modify != nil {
+				tt.modify(&a)
+			}
+
+			a.SetMarkdownTemplate(tt.tmpl)
+
+			formatted := a.FormatMarkdown()
+			for _, want := range tt.want {
+				assert.Contains(t, formatted, want)
+			}
+		})
+	}
+}
+
+func TestFlightAwareAlert_ChangeAlerts(t *testing.T) {
+	base := messages.FlightAwareAlert{
+		FlightNumber: "UA2614",
+		Origin:       messages.FlightAwareAirportInfo{Airport: "LAX", Gate: "41", Terminal: "7"},
+		Destination:  messages.FlightAwareAirportInfo{Airport: "EWR", Gate: "C71", Terminal: "C"},
+		GateDepartureTime: flightaware.FlightTimestamp{
+			Scheduled: mustParseTime(t, "2022-05-31 12:30:00 (-0700)"),
+			Estimated: mustParseTime(t, "2022-05-31 13:35:00 (-0700)"),
+		},
+	}
+
+	tests := []struct {
+		tmpls  messages.MessageTemplates
+		modify func(a *messages.FlightAwareAlert)
+		name   string
+		want   []string
+		absent []string
+	}{
+		{
+			name:  "origin gate change",
+			tmpls: messages.DefaultTemplateSet().Get(messages.GateChangeTemplates),
+			modify: func(a *messages.FlightAwareAlert) {
+				a.IsGateChange = true
+				a.PreviousOrigin = a.Origin
+				a.PreviousOrigin.Gate = "37"
+				a.PreviousDestination = a.Destination
+			},
+			want:   []string{"now departs from LAX terminal 7 gate 41 (previously terminal 7 gate 37)"},
+			absent: []string{"now arrives"},
+		},
+		{
+			name:  "destination gate assigned",
+			tmpls: messages.DefaultTemplateSet().Get(messages.GateChangeTemplates),
+			modify: func(a *messages.FlightAwareAlert) {
+				a.IsGateChange = true
+				a.PreviousOrigin = a.Origin
+				a.PreviousDestination = messages.FlightAwareAirportInfo{Airport: "EWR"}
+			},
+			want:   []string{"now arrives at EWR terminal C gate C71."},
+			absent: []string{"now departs", "previously"},
+		},
+		{
+			name:  "delay",
+			tmpls: messages.DefaultTemplateSet().Get(messages.DelayTemplates),
+			modify: func(a *messages.FlightAwareAlert) {
+				a.IsDelay = true
+				a.DepartureDelay = 65 * time.Minute
+			},
+			want:   []string{"Departure is delayed by 1h 5m, and is now estimated for 2022-05-31 20:35:00"},
+			absent: []string{"Arrival is delayed"},
+		},
+		{
+			name:   "cancellation",
+			tmpls:  messages.DefaultTemplateSet().Get(messages.CancellationTemplates),
+			modify: func(a *messages.FlightAwareAlert) { a.IsCancelled = true },
+			want:   []string{"Flight UA2614 from LAX to EWR, scheduled to depart at 2022-05-31 19:30:00", "has been cancelled."},
+		},
+		{
+			name:  "diversion",
+			tmpls: messages.DefaultTemplateSet().Get(messages.DiversionTemplates),
+			modify: func(a *messages.FlightAwareAlert) {
+				a.IsDiverted = true
+				a.DivertedTo = "JFK"
+			},
+			want: []string{"Flight UA2614 from LAX to EWR has been diverted to JFK."},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			a := base
+			tt.modify(&a)
+			a.SetTemplates(tt.tmpls)
+
+			formatted := a.FormatPlaintext()
+			for _, want := range tt.want {
+				assert.Contains(t, formatted, want)
+			}
+			for _, absent := range tt.absent {
+				assert.NotContains(t, formatted, absent)
+			}
+
+			assert.NotEmpty(t, a.FormatMarkdown())
+			assert.NotEmpty(t, a.FormatHTML())
 		})
 	}
 }
@@ -3,6 +3,8 @@ package messages
 import (
 	"bytes"
 	"fmt"
+	htmltemplate "html/template"
+	"io"
 	"log"
 	"text/template"
 	"time"
@@ -27,9 +29,60 @@ type Message interface {
 	MarkdownTemplate() *template.Template
 }
 
+// HTMLMessage is implemented by messages which can be rendered as HTML
+// (for example, in emails).
+type HTMLMessage interface {
+	Message
+	// FormatHTML should return the message rendered as an HTML fragment.
+	FormatHTML() string
+	// HTMLTemplate should return the template.Template (from the html/template package) used by FormatHTML.
+	HTMLTemplate() *htmltemplate.Template
+}
+
+// FormatHTML returns the HTML rendering of the passed message.
+// If the message doesn't implement HTMLMessage, or has no HTML template set,
+// its plaintext rendering is escaped and wrapped in a <pre> element.
+func FormatHTML(msg Message) string {
+	if htmlMsg, ok := msg.(HTMLMessage); ok && htmlMsg.HTMLTemplate() != nil {
+		return htmlMsg.FormatHTML()
+	}
+
+	return "<pre>" + htmltemplate.HTMLEscapeString(msg.FormatPlaintext()) + "</pre>"
+}
+
+// StructuredMessage is implemented by messages which have a structured,
+// JSON-serializable representation (for example, for webhooks).
+type StructuredMessage interface {
+	Message
+	// Kind should return a short, unique identifier for the message type, ex. "spot_price_alert".
+	Kind() string
+	// Structured should return the message's fields in a JSON-serializable form.
+	Structured() any
+}
+
+// UnstructuredMessageKind is returned by MessageKind for
+// messages which don't implement StructuredMessage.
+const UnstructuredMessageKind string = "message"
+
+// MessageKind returns the Kind of the passed message,
+// or UnstructuredMessageKind if the message doesn't implement StructuredMessage.
+func MessageKind(msg Message) string {
+	if structured, ok := msg.(StructuredMessage); ok {
+		return structured.Kind()
+	}
+
+	return UnstructuredMessageKind
+}
+
+// messageTemplate is satisfied by both text/template and html/template templates.
+type messageTemplate interface {
+	Name() string
+	Execute(wr io.Writer, data any) error
+}
+
 type baseMessage struct{}
 
-func (m baseMessage) format(tmpl *template.Template, data any) (string, error) {
+func (m baseMessage) format(tmpl messageTemplate, data any) (string, error) {
 	b := new(bytes.Buffer)
 
 	if err := tmpl.Execute(b, data); err != nil {
@@ -48,6 +101,25 @@ func formatTimeOffset(t time.Time) string {
 	return utils.FormatTime(t, true)
 }
 
+// formatDuration formats a duration rounded to the minute,
+// ex. "1h 5m" or "45m".
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	d = d.Round(time.Minute)
+
+	hours := d / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+
+	if hours == 0 {
+		return fmt.Sprintf("%[1]dm", minutes)
+	}
+
+	return fmt.Sprintf("%[1]dh %[2]dm", hours, minutes)
+}
+
 func formatDecimal(d decimal.Decimal) string {
 	return d.String()
 }
@@ -84,3 +156,15 @@ func formatTimezone(t time.Time, useLocalTime bool, timezone string) string {
 func isValidTime(t time.Time) bool {
 	return !t.IsZero()
 }
+
+// optionalTime returns nil for zero times, so that
+// they're omitted from structured message data.
+func optionalTime(t time.Time) *time.Time {
+	if !isValidTime(t) {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
@@ -1,6 +1,7 @@
 package messages
 
 import (
+	htmltemplate "html/template"
 	"text/template"
 	"time"
 
@@ -8,13 +9,18 @@ import (
 	"go.uber.org/zap"
 )
 
+const SpotPriceAlertKind string = "spot_price_alert"
+
 type SpotPriceAlert struct {
 	baseMessage
-	EventTime     time.Time
-	SpotPrice     decimal.Decimal
-	BaseAmount    decimal.Decimal
-	BaseCurrency  string
-	QuoteCurrency string
+	EventTime         time.Time
+	SpotPrice         decimal.Decimal
+	BaseAmount        decimal.Decimal
+	plaintextTemplate *template.Template
+	markdownTemplate  *template.Template
+	htmlTemplate      *htmltemplate.Template
+	BaseCurrency      string
+	QuoteCurrency     string
 }
 
 func (a SpotPriceAlert) FormatPlaintext() string {
@@ -35,10 +41,68 @@ func (a SpotPriceAlert) FormatMarkdown() string {
 	return msg
 }
 
+func (a SpotPriceAlert) FormatHTML() string {
+	msg, err := a.format(a.HTMLTemplate(), a)
+	if err != nil {
+		logger.Panic("error formatting SpotPriceAlert html template", zap.Error(err))
+	}
+
+	return msg
+}
+
+// SetTemplates sets the alert's plaintext, markdown, and html templates.
+// Any nil templates fall back to the built-in templates.
+func (a *SpotPriceAlert) SetTemplates(tmpls MessageTemplates) *SpotPriceAlert {
+	a.plaintextTemplate = tmpls.Plaintext
+	a.markdownTemplate = tmpls.Markdown
+	a.htmlTemplate = tmpls.HTML
+
+	return a
+}
+
 func (a SpotPriceAlert) PlaintextTemplate() *template.Template {
+	if a.plaintextTemplate != nil {
+		return a.plaintextTemplate
+	}
+
 	return spotPriceAlertPlaintextTemplate
 }
 
 func (a SpotPriceAlert) MarkdownTemplate() *template.Template {
+	if a.markdownTemplate != nil {
+		return a.markdownTemplate
+	}
+
 	return spotPriceAlertMarkdownTemplate
 }
+
+func (a SpotPriceAlert) HTMLTemplate() *htmltemplate.Template {
+	if a.htmlTemplate != nil {
+		return a.htmlTemplate
+	}
+
+	return spotPriceAlertHTMLTemplate
+}
+
+// SpotPriceAlertData is the structured representation of a SpotPriceAlert.
+type SpotPriceAlertData struct {
+	EventTime     time.Time       `json:"event_time"`
+	SpotPrice     decimal.Decimal `json:"spot_price"`
+	BaseAmount    decimal.Decimal `json:"base_amount"`
+	BaseCurrency  string          `json:"base_currency"`
+	QuoteCurrency string          `json:"quote_currency"`
+}
+
+func (a SpotPriceAlert) Kind() string {
+	return SpotPriceAlertKind
+}
+
+func (a SpotPriceAlert) Structured() any {
+	return SpotPriceAlertData{
+		EventTime:     a.EventTime.UTC(),
+		SpotPrice:     a.SpotPrice,
+		BaseAmount:    a.BaseAmount,
+		BaseCurrency:  a.BaseCurrency,
+		QuoteCurrency: a.QuoteCurrency,
+	}
+}
@@ -1,6 +1,7 @@
 package messages
 
 import (
+	htmltemplate "html/template"
 	"text/template"
 )
 
@@ -8,6 +9,7 @@ var tmplFnMap = template.FuncMap{
 	"FormatTime":       formatTime,
 	"FormatTimeOffset": formatTimeOffset,
 	"FormatDecimal":    formatDecimal,
+	"FormatDuration":   formatDuration,
 	"FormatPair":       formatCurrencyPair,
 	"FormatPairQuote":  formatPairQuote,
 	"FormatTimezone":   formatTimezone,
@@ -24,17 +26,34 @@ func mustParseTemplate(name, raw string) *template.Template {
 	return tmpl
 }
 
+func mustParseHTMLTemplate(name, raw string) *htmltemplate.Template {
+	tmpl, tmplErr := htmltemplate.New(name).
+		Funcs(htmltemplate.FuncMap(tmplFnMap)).
+		Parse(raw)
+
+	tmpl = htmltemplate.Must(tmpl, tmplErr)
+
+	return tmpl
+}
+
 const (
 	rawSpotPriceAlertPlaintextTemplate = `Crypto Spot Price Alert!
 
 At {{ FormatTimeOffset .EventTime }}, {{ FormatPair .BaseCurrency .QuoteCurrency }} was {{ FormatPairQuote .BaseCurrency .QuoteCurrency .BaseAmount .SpotPrice }}.
 `
-	rawSpotPriceAlertMarkdownTemplate = ``
+	rawSpotPriceAlertMarkdownTemplate = `*Crypto Spot Price Alert!*
+
+At {{ FormatTimeOffset .EventTime }}, *{{ FormatPair .BaseCurrency .QuoteCurrency }}* was *{{ FormatPairQuote .BaseCurrency .QuoteCurrency .BaseAmount .SpotPrice }}*.
+`
+	rawSpotPriceAlertHTMLTemplate = `<h3>Crypto Spot Price Alert!</h3>
+<p>At {{ FormatTimeOffset .EventTime }}, <strong>{{ FormatPair .BaseCurrency .QuoteCurrency }}</strong> was <strong>{{ FormatPairQuote .BaseCurrency .QuoteCurrency .BaseAmount .SpotPrice }}</strong>.</p>
+`
 )
 
 var (
 	spotPriceAlertPlaintextTemplate = mustParseTemplate("spotPriceAlertPlaintext", rawSpotPriceAlertPlaintextTemplate)
 	spotPriceAlertMarkdownTemplate  = mustParseTemplate("spotPriceAlertMarkdown", rawSpotPriceAlertMarkdownTemplate)
+	spotPriceAlertHTMLTemplate      = mustParseHTMLTemplate("spotPriceAlertHTML", rawSpotPriceAlertHTMLTemplate)
 )
 
 const (
@@ -94,3 +113,418 @@ var (
 	PreDepartureAlertPlaintextTemplate = mustParseTemplate("preDeparturePlaintext", rawPreDeparturePlaintextTemplate)
 	PreArrivalAlertPlaintextTemplate   = mustParseTemplate("preArrivalAlertPlaintext", rawPreArrivalPlaintextTemplate)
 )
+
+// Markdown templates use formatting common to Slack (mrkdwn) and Discord,
+// so *text* is rendered bold in Slack and italic in Discord.
+const (
+	rawDepartureMarkdownTemplate = `*Flight Information Update*
+{{ if .IsGateDeparture }}
+Flight *{{ .FlightNumber }}* departed from *{{ .Origin.Airport }}* gate *{{ .Origin.Gate }}* at {{ FormatTimezone .GateDepartureTime.Actual .UseLocalTimezone .Origin.Timezone }}.
+{{- if IsValidTime .TakeoffTime.Estimated }}
+Estimated takeoff time is {{ FormatTimezone .TakeoffTime.Estimated .UseLocalTimezone .Origin.Timezone }}.
+{{- end }}
+{{- else if .IsTakeoff }}
+Flight *{{ .FlightNumber }}* took off from *{{ .Origin.Airport }}* at {{ FormatTimezone .TakeoffTime.Actual .UseLocalTimezone .Origin.Timezone }}.
+{{- if IsValidTime .LandingTime.Estimated }}
+Estimated landing time at *{{ .Destination.Airport }}* is {{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Origin.Timezone }}).
+{{- end }}
+{{- end }}
+`
+
+	rawArrivalMarkdownTemplate = `*Flight Information Update*
+{{ if .IsGateArrival }}
+Flight *{{ .FlightNumber }}* arrived at *{{ .Destination.Airport }}* ({{ if .Destination.Terminal }}terminal *{{ .Destination.Terminal }}* {{ end }}gate *{{ .Destination.Gate }}*) at {{ FormatTimezone .GateArrivalTime.Actual .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .GateArrivalTime.Actual .UseLocalTimezone .Origin.Timezone }}).
+{{- else if .IsLanding }}
+Flight *{{ .FlightNumber }}* landed at *{{ .Destination.Airport }}* at {{ FormatTimezone .LandingTime.Actual .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .LandingTime.Actual .UseLocalTimezone .Origin.Timezone }}).
+{{- if IsValidTime .GateArrivalTime.Estimated }}
+Estimated gate arrival time is {{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Origin.Timezone }}).
+{{- end }}
+{{- end }}
+`
+
+	rawPreDepartureMarkdownTemplate = `*Flight Pre-Departure Alert*
+
+Flight *{{ .FlightNumber }}* is scheduled to depart from *{{ .Origin.Airport }}* at {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}.
+{{- if IsValidTime .GateDepartureTime.Estimated }}
+Estimated departure time from {{ if .Origin.Terminal }}terminal *{{ .Origin.Terminal }}* {{ end }}gate *{{ .Origin.Gate }}* is {{ FormatTimezone .GateDepartureTime.Estimated .UseLocalTimezone .Origin.Timezone }}.
+{{- end }}
+`
+
+	rawPreArrivalMarkdownTemplate = `*Flight Pre-Arrival Alert*
+
+Flight *{{ .FlightNumber }}* is estimated to land at *{{ .Destination.Airport }}* at {{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Origin.Timezone }}).
+{{- if IsValidTime .GateArrivalTime.Estimated }}
+Estimated arrival at {{ if .Destination.Terminal }}terminal *{{ .Destination.Terminal }}* {{ end }}gate *{{ .Destination.Gate }}* is {{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Origin.Timezone }}).
+{{- end }}
+`
+)
+
+var (
+	DepartureAlertMarkdownTemplate    = mustParseTemplate("departureAlertMarkdown", rawDepartureMarkdownTemplate)
+	ArrivalAlertMarkdownTemplate      = mustParseTemplate("arrivalAlertMarkdown", rawArrivalMarkdownTemplate)
+	PreDepartureAlertMarkdownTemplate = mustParseTemplate("preDepartureMarkdown", rawPreDepartureMarkdownTemplate)
+	PreArrivalAlertMarkdownTemplate   = mustParseTemplate("preArrivalAlertMarkdown", rawPreArrivalMarkdownTemplate)
+)
+
+const (
+	rawDepartureHTMLTemplate = `<h3>Flight Information Update</h3>
+{{ if .IsGateDeparture -}}
+<p>Flight <strong>{{ .FlightNumber }}</strong> departed from {{ .Origin.Airport }} gate {{ .Origin.Gate }} at {{ FormatTimezone .GateDepartureTime.Actual .UseLocalTimezone .Origin.Timezone }}.</p>
+{{- if IsValidTime .TakeoffTime.Estimated }}
+<p>Estimated takeoff time is {{ FormatTimezone .TakeoffTime.Estimated .UseLocalTimezone .Origin.Timezone }}.</p>
+{{- end }}
+{{- else if .IsTakeoff -}}
+<p>Flight <strong>{{ .FlightNumber }}</strong> took off from {{ .Origin.Airport }} at {{ FormatTimezone .TakeoffTime.Actual .UseLocalTimezone .Origin.Timezone }}.</p>
+{{- if IsValidTime .LandingTime.Estimated }}
+<p>Estimated landing time at {{ .Destination.Airport }} is {{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Origin.Timezone }}).</p>
+{{- end }}
+{{- end }}
+`
+
+	rawArrivalHTMLTemplate = `<h3>Flight Information Update</h3>
+{{ if .IsGateArrival -}}
+<p>Flight <strong>{{ .FlightNumber }}</strong> arrived at {{ .Destination.Airport }} ({{ if .Destination.Terminal }}terminal {{ .Destination.Terminal }} {{ end }}gate {{ .Destination.Gate }}) at {{ FormatTimezone .GateArrivalTime.Actual .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .GateArrivalTime.Actual .UseLocalTimezone .Origin.Timezone }}).</p>
+{{- else if .IsLanding -}}
+<p>Flight <strong>{{ .FlightNumber }}</strong> landed at {{ .Destination.Airport }} at {{ FormatTimezone .LandingTime.Actual .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .LandingTime.Actual .UseLocalTimezone .Origin.Timezone }}).</p>
+{{- if IsValidTime .GateArrivalTime.Estimated }}
+<p>Estimated gate arrival time is {{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Origin.Timezone }}).</p>
+{{- end }}
+{{- end }}
+`
+
+	rawPreDepartureHTMLTemplate = `<h3>Flight Pre-Departure Alert</h3>
+<p>Flight <strong>{{ .FlightNumber }}</strong> is scheduled to depart from {{ .Origin.Airport }} at {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}.</p>
+{{- if IsValidTime .GateDepartureTime.Estimated }}
+<p>Estimated departure time from {{ if .Origin.Terminal }}Terminal {{ .Origin.Terminal }} {{ end }}Gate {{ .Origin.Gate }} is {{ FormatTimezone .GateDepartureTime.Estimated .UseLocalTimezone .Origin.Timezone }}.</p>
+{{- end }}
+`
+
+	rawPreArrivalHTMLTemplate = `<h3>Flight Pre-Arrival Alert</h3>
+<p>Flight <strong>{{ .FlightNumber }}</strong> is estimated to land at {{ .Destination.Airport }} at {{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .LandingTime.Estimated .UseLocalTimezone .Origin.Timezone }}).</p>
+{{- if IsValidTime .GateArrivalTime.Estimated }}
+<p>Estimated arrival at {{ if .Destination.Terminal }}Terminal {{ .Destination.Terminal }} {{ end }}Gate {{ .Destination.Gate }} is {{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Destination.Timezone }} ({{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Origin.Timezone }}).</p>
+{{- end }}
+`
+)
+
+var (
+	DepartureAlertHTMLTemplate    = mustParseHTMLTemplate("departureAlertHTML", rawDepartureHTMLTemplate)
+	ArrivalAlertHTMLTemplate      = mustParseHTMLTemplate("arrivalAlertHTML", rawArrivalHTMLTemplate)
+	PreDepartureAlertHTMLTemplate = mustParseHTMLTemplate("preDepartureHTML", rawPreDepartureHTMLTemplate)
+	PreArrivalAlertHTMLTemplate   = mustParseHTMLTemplate("preArrivalAlertHTML", rawPreArrivalHTMLTemplate)
+)
+
+const (
+	rawGateChangePlaintextTemplate = `--- Flight Gate Change ---
+{{ if .OriginGateChanged }}
+Flight {{ .FlightNumber }} now departs from {{ .Origin.Airport }} {{ if .Origin.Terminal }}terminal {{ .Origin.Terminal }} {{ end }}gate {{ .Origin.Gate }}
+{{- if .PreviousOrigin.Gate }} (previously {{ if .PreviousOrigin.Terminal }}terminal {{ .PreviousOrigin.Terminal }} {{ end }}gate {{ .PreviousOrigin.Gate }}){{ end }}.
+{{- end }}
+{{- if .DestinationGateChanged }}
+Flight {{ .FlightNumber }} now arrives at {{ .Destination.Airport }} {{ if .Destination.Terminal }}terminal {{ .Destination.Terminal }} {{ end }}gate {{ .Destination.Gate }}
+{{- if .PreviousDestination.Gate }} (previously {{ if .PreviousDestination.Terminal }}terminal {{ .PreviousDestination.Terminal }} {{ end }}gate {{ .PreviousDestination.Gate }}){{ end }}.
+{{- end }}
+`
+
+	rawDelayPlaintextTemplate = `--- Flight Delay Alert ---
+
+Flight {{ .FlightNumber }} from {{ .Origin.Airport }} to {{ .Destination.Airport }} is delayed.
+{{- if gt .DepartureDelay 0 }}
+Departure is delayed by {{ FormatDuration .DepartureDelay }}
+{{- if IsValidTime .GateDepartureTime.Estimated }}, and is now estimated for {{ FormatTimezone .GateDepartureTime.Estimated .UseLocalTimezone .Origin.Timezone }}{{ end }}.
+{{- end }}
+{{- if gt .ArrivalDelay 0 }}
+Arrival is delayed by {{ FormatDuration .ArrivalDelay }}
+{{- if IsValidTime .GateArrivalTime.Estimated }}, and is now estimated for {{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Destination.Timezone }}{{ end }}.
+{{- end }}
+`
+
+	rawCancellationPlaintextTemplate = `--- Flight Cancellation Alert ---
+
+Flight {{ .FlightNumber }} from {{ .Origin.Airport }} to {{ .Destination.Airport }}, scheduled to depart at {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}, has been cancelled.
+`
+
+	rawDiversionPlaintextTemplate = `--- Flight Diversion Alert ---
+
+Flight {{ .FlightNumber }} from {{ .Origin.Airport }} to {{ .Destination.Airport }} has been diverted{{ if .DivertedTo }} to {{ .DivertedTo }}{{ end }}.
+`
+)
+
+var (
+	GateChangeAlertPlaintextTemplate   = mustParseTemplate("gateChangeAlertPlaintext", rawGateChangePlaintextTemplate)
+	DelayAlertPlaintextTemplate        = mustParseTemplate("delayAlertPlaintext", rawDelayPlaintextTemplate)
+	CancellationAlertPlaintextTemplate = mustParseTemplate("cancellationAlertPlaintext", rawCancellationPlaintextTemplate)
+	DiversionAlertPlaintextTemplate    = mustParseTemplate("diversionAlertPlaintext", rawDiversionPlaintextTemplate)
+)
+
+const (
+	rawGateChangeMarkdownTemplate = `*Flight Gate Change*
+{{ if .OriginGateChanged }}
+Flight *{{ .FlightNumber }}* now departs from *{{ .Origin.Airport }}* {{ if .Origin.Terminal }}terminal *{{ .Origin.Terminal }}* {{ end }}gate *{{ .Origin.Gate }}*
+{{- if .PreviousOrigin.Gate }} (previously {{ if .PreviousOrigin.Terminal }}terminal {{ .PreviousOrigin.Terminal }} {{ end }}gate {{ .PreviousOrigin.Gate }}){{ end }}.
+{{- end }}
+{{- if .DestinationGateChanged }}
+Flight *{{ .FlightNumber }}* now arrives at *{{ .Destination.Airport }}* {{ if .Destination.Terminal }}terminal *{{ .Destination.Terminal }}* {{ end }}gate *{{ .Destination.Gate }}*
+{{- if .PreviousDestination.Gate }} (previously {{ if .PreviousDestination.Terminal }}terminal {{ .PreviousDestination.Terminal }} {{ end }}gate {{ .PreviousDestination.Gate }}){{ end }}.
+{{- end }}
+`
+
+	rawDelayMarkdownTemplate = `*Flight Delay Alert*
+
+Flight *{{ .FlightNumber }}* from *{{ .Origin.Airport }}* to *{{ .Destination.Airport }}* is delayed.
+{{- if gt .DepartureDelay 0 }}
+Departure is delayed by *{{ FormatDuration .DepartureDelay }}*
+{{- if IsValidTime .GateDepartureTime.Estimated }}, and is now estimated for {{ FormatTimezone .GateDepartureTime.Estimated .UseLocalTimezone .Origin.Timezone }}{{ end }}.
+{{- end }}
+{{- if gt .ArrivalDelay 0 }}
+Arrival is delayed by *{{ FormatDuration .ArrivalDelay }}*
+{{- if IsValidTime .GateArrivalTime.Estimated }}, and is now estimated for {{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Destination.Timezone }}{{ end }}.
+{{- end }}
+`
+
+	rawCancellationMarkdownTemplate = `*Flight Cancellation Alert*
+
+Flight *{{ .FlightNumber }}* from *{{ .Origin.Airport }}* to *{{ .Destination.Airport }}*, scheduled to depart at {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}, has been *cancelled*.
+`
+
+	rawDiversionMarkdownTemplate = `*Flight Diversion Alert*
+
+Flight *{{ .FlightNumber }}* from *{{ .Origin.Airport }}* to *{{ .Destination.Airport }}* has been *diverted*{{ if .DivertedTo }} to *{{ .DivertedTo }}*{{ end }}.
+`
+)
+
+var (
+	GateChangeAlertMarkdownTemplate   = mustParseTemplate("gateChangeAlertMarkdown", rawGateChangeMarkdownTemplate)
+	DelayAlertMarkdownTemplate        = mustParseTemplate("delayAlertMarkdown", rawDelayMarkdownTemplate)
+	CancellationAlertMarkdownTemplate = mustParseTemplate("cancellationAlertMarkdown", rawCancellationMarkdownTemplate)
+	DiversionAlertMarkdownTemplate    = mustParseTemplate("diversionAlertMarkdown", rawDiversionMarkdownTemplate)
+)
+
+const (
+	rawGateChangeHTMLTemplate = `<h3>Flight Gate Change</h3>
+{{- if .OriginGateChanged }}
+<p>Flight <strong>{{ .FlightNumber }}</strong> now departs from {{ .Origin.Airport }} <strong>{{ if .Origin.Terminal }}terminal {{ .Origin.Terminal }} {{ end }}gate {{ .Origin.Gate }}</strong>
+{{- if .PreviousOrigin.Gate }} (previously {{ if .PreviousOrigin.Terminal }}terminal {{ .PreviousOrigin.Terminal }} {{ end }}gate {{ .PreviousOrigin.Gate }}){{ end }}.</p>
+{{- end }}
+{{- if .DestinationGateChanged }}
+<p>Flight <strong>{{ .FlightNumber }}</strong> now arrives at {{ .Destination.Airport }} <strong>{{ if .Destination.Terminal }}terminal {{ .Destination.Terminal }} {{ end }}gate {{ .Destination.Gate }}</strong>
+{{- if .PreviousDestination.Gate }} (previously {{ if .PreviousDestination.Terminal }}terminal {{ .PreviousDestination.Terminal }} {{ end }}gate {{ .PreviousDestination.Gate }}){{ end }}.</p>
+{{- end }}
+`
+
+	rawDelayHTMLTemplate = `<h3>Flight Delay Alert</h3>
+<p>Flight <strong>{{ .FlightNumber }}</strong> from {{ .Origin.Airport }} to {{ .Destination.Airport }} is delayed.</p>
+{{- if gt .DepartureDelay 0 }}
+<p>Departure is delayed by <strong>{{ FormatDuration .DepartureDelay }}</strong>
+{{- if IsValidTime .GateDepartureTime.Estimated }}, and is now estimated for {{ FormatTimezone .GateDepartureTime.Estimated .UseLocalTimezone .Origin.Timezone }}{{ end }}.</p>
+{{- end }}
+{{- if gt .ArrivalDelay 0 }}
+<p>Arrival is delayed by <strong>{{ FormatDuration .ArrivalDelay }}</strong>
+{{- if IsValidTime .GateArrivalTime.Estimated }}, and is now estimated for {{ FormatTimezone .GateArrivalTime.Estimated .UseLocalTimezone .Destination.Timezone }}{{ end }}.</p>
+{{- end }}
+`
+
+	rawCancellationHTMLTemplate = `<h3>Flight Cancellation Alert</h3>
+<p>Flight <strong>{{ .FlightNumber }}</strong> from {{ .Origin.Airport }} to {{ .Destination.Airport }}, scheduled to depart at {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}, has been <strong>cancelled</strong>.</p>
+`
+
+	rawDiversionHTMLTemplate = `<h3>Flight Diversion Alert</h3>
+<p>Flight <strong>{{ .FlightNumber }}</strong> from {{ .Origin.Airport }} to {{ .Destination.Airport }} has been <strong>diverted</strong>{{ if .DivertedTo }} to {{ .DivertedTo }}{{ end }}.</p>
+`
+)
+
+var (
+	GateChangeAlertHTMLTemplate   = mustParseHTMLTemplate("gateChangeAlertHTML", rawGateChangeHTMLTemplate)
+	DelayAlertHTMLTemplate        = mustParseHTMLTemplate("delayAlertHTML", rawDelayHTMLTemplate)
+	CancellationAlertHTMLTemplate = mustParseHTMLTemplate("cancellationAlertHTML", rawCancellationHTMLTemplate)
+	DiversionAlertHTMLTemplate    = mustParseHTMLTemplate("diversionAlertHTML", rawDiversionHTMLTemplate)
+)
+
+const (
+	rawConnectionPlaintextTemplate = `--- Connection Alert{{ if .ItineraryName }} ({{ .ItineraryName }}){{ end }} ---
+{{ if .IsInboundCancelled }}
+Flight {{ .InboundFlight }} has been cancelled, so the connection to flight {{ .OutboundFlight }} at {{ .Airport.Airport }} will be missed.
+{{- else if .IsInboundDiverted }}
+Flight {{ .InboundFlight }} has been diverted, so the connection to flight {{ .OutboundFlight }} at {{ .Airport.Airport }} is at risk.
+{{- else if .IsMissed }}
+The connection from flight {{ .InboundFlight }} to flight {{ .OutboundFlight }} at {{ .Airport.Airport }} will likely be missed: {{ .InboundFlight }} is estimated to arrive {{ FormatDuration .ConnectionTime }} after {{ .OutboundFlight }} departs.
+{{- else }}
+The connection from flight {{ .InboundFlight }} to flight {{ .OutboundFlight }} at {{ .Airport.Airport }} is at risk: only {{ FormatDuration .ConnectionTime }} to connect (minimum {{ FormatDuration .MinConnectionTime }}).
+{{- end }}
+{{- if and (not .IsInboundCancelled) (IsValidTime .InboundArrival) }}
+Estimated arrival of {{ .InboundFlight }}: {{ FormatTimezone .InboundArrival .UseLocalTimezone .Airport.Timezone }}.
+{{- end }}
+{{- if IsValidTime .OutboundDeparture }}
+Estimated departure of {{ .OutboundFlight }}: {{ FormatTimezone .OutboundDeparture .UseLocalTimezone .Airport.Timezone }}.
+{{- end }}
+`
+
+	rawConnectionMarkdownTemplate = `*Connection Alert{{ if .ItineraryName }} ({{ .ItineraryName }}){{ end }}*
+{{ if .IsInboundCancelled }}
+Flight *{{ .InboundFlight }}* has been *cancelled*, so the connection to flight *{{ .OutboundFlight }}* at *{{ .Airport.Airport }}* will be missed.
+{{- else if .IsInboundDiverted }}
+Flight *{{ .InboundFlight }}* has been *diverted*, so the connection to flight *{{ .OutboundFlight }}* at *{{ .Airport.Airport }}* is at risk.
+{{- else if .IsMissed }}
+The connection from flight *{{ .InboundFlight }}* to flight *{{ .OutboundFlight }}* at *{{ .Airport.Airport }}* will likely be *missed*: *{{ .InboundFlight }}* is estimated to arrive *{{ FormatDuration .ConnectionTime }}* after *{{ .OutboundFlight }}* departs.
+{{- else }}
+The connection from flight *{{ .InboundFlight }}* to flight *{{ .OutboundFlight }}* at *{{ .Airport.Airport }}* is at risk: only *{{ FormatDuration .ConnectionTime }}* to connect (minimum {{ FormatDuration .MinConnectionTime }}).
+{{- end }}
+{{- if and (not .IsInboundCancelled) (IsValidTime .InboundArrival) }}
+Estimated arrival of *{{ .InboundFlight }}*: {{ FormatTimezone .InboundArrival .UseLocalTimezone .Airport.Timezone }}.
+{{- end }}
+{{- if IsValidTime .OutboundDeparture }}
+Estimated departure of *{{ .OutboundFlight }}*: {{ FormatTimezone .OutboundDeparture .UseLocalTimezone .Airport.Timezone }}.
+{{- end }}
+`
+
+	rawConnectionHTMLTemplate = `<h3>Connection Alert{{ if .ItineraryName }} ({{ .ItineraryName }}){{ end }}</h3>
+{{- if .IsInboundCancelled }}
+<p>Flight <strong>{{ .InboundFlight }}</strong> has been <strong>cancelled</strong>, so the connection to flight <strong>{{ .OutboundFlight }}</strong> at {{ .Airport.Airport }} will be missed.</p>
+{{- else if .IsInboundDiverted }}
+<p>Flight <strong>{{ .InboundFlight }}</strong> has been <strong>diverted</strong>, so the connection to flight <strong>{{ .OutboundFlight }}</strong> at {{ .Airport.Airport }} is at risk.</p>
+{{- else if .IsMissed }}
+<p>The connection from flight <strong>{{ .InboundFlight }}</strong> to flight <strong>{{ .OutboundFlight }}</strong> at {{ .Airport.Airport }} will likely be <strong>missed</strong>: {{ .InboundFlight }} is estimated to arrive {{ FormatDuration .ConnectionTime }} after {{ .OutboundFlight }} departs.</p>
+{{- else }}
+<p>The connection from flight <strong>{{ .InboundFlight }}</strong> to flight <strong>{{ .OutboundFlight }}</strong> at {{ .Airport.Airport }} is at risk: only <strong>{{ FormatDuration .ConnectionTime }}</strong> to connect (minimum {{ FormatDuration .MinConnectionTime }}).</p>
+{{- end }}
+{{- if and (not .IsInboundCancelled) (IsValidTime .InboundArrival) }}
+<p>Estimated arrival of {{ .InboundFlight }}: {{ FormatTimezone .InboundArrival .UseLocalTimezone .Airport.Timezone }}.</p>
+{{- end }}
+{{- if IsValidTime .OutboundDeparture }}
+<p>Estimated departure of {{ .OutboundFlight }}: {{ FormatTimezone .OutboundDeparture .UseLocalTimezone .Airport.Timezone }}.</p>
+{{- end }}
+`
+)
+
+var (
+	ConnectionAlertPlaintextTemplate = mustParseTemplate("connectionAlertPlaintext", rawConnectionPlaintextTemplate)
+	ConnectionAlertMarkdownTemplate  = mustParseTemplate("connectionAlertMarkdown", rawConnectionMarkdownTemplate)
+	ConnectionAlertHTMLTemplate      = mustParseHTMLTemplate("connectionAlertHTML", rawConnectionHTMLTemplate)
+)
+
+const (
+	rawInboundAircraftPlaintextTemplate = `--- Aircraft Running Late ---
+
+The aircraft for flight {{ .FlightNumber }} from {{ .Origin.Airport }} to {{ .Destination.Airport }} is running late.
+{{- if IsValidTime .InboundArrival }}
+It is arriving{{ if .InboundFlight }} as flight {{ .InboundFlight }}{{ end }} at {{ FormatTimezone .InboundArrival .UseLocalTimezone .Origin.Timezone }}.
+{{- end }}
+Departure, scheduled for {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}, will likely be delayed by about {{ FormatDuration .PredictedDepartureDelay }}.
+`
+
+	rawInboundAircraftMarkdownTemplate = `*Aircraft Running Late*
+
+The aircraft for flight *{{ .FlightNumber }}* from *{{ .Origin.Airport }}* to *{{ .Destination.Airport }}* is running late.
+{{- if IsValidTime .InboundArrival }}
+It is arriving{{ if .InboundFlight }} as flight *{{ .InboundFlight }}*{{ end }} at {{ FormatTimezone .InboundArrival .UseLocalTimezone .Origin.Timezone }}.
+{{- end }}
+Departure, scheduled for {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}, will likely be delayed by about *{{ FormatDuration .PredictedDepartureDelay }}*.
+`
+
+	rawInboundAircraftHTMLTemplate = `<h3>Aircraft Running Late</h3>
+<p>The aircraft for flight <strong>{{ .FlightNumber }}</strong> from {{ .Origin.Airport }} to {{ .Destination.Airport }} is running late.</p>
+{{- if IsValidTime .InboundArrival }}
+<p>It is arriving{{ if .InboundFlight }} as flight <strong>{{ .InboundFlight }}</strong>{{ end }} at {{ FormatTimezone .InboundArrival .UseLocalTimezone .Origin.Timezone }}.</p>
+{{- end }}
+<p>Departure, scheduled for {{ FormatTimezone .GateDepartureTime.Scheduled .UseLocalTimezone .Origin.Timezone }}, will likely be delayed by about <strong>{{ FormatDuration .PredictedDepartureDelay }}</strong>.</p>
+`
+)
+
+var (
+	InboundAircraftAlertPlaintextTemplate = mustParseTemplate("inboundAircraftAlertPlaintext", rawInboundAircraftPlaintextTemplate)
+	InboundAircraftAlertMarkdownTemplate  = mustParseTemplate("inboundAircraftAlertMarkdown", rawInboundAircraftMarkdownTemplate)
+	InboundAircraftAlertHTMLTemplate      = mustParseHTMLTemplate("inboundAircraftAlertHTML", rawInboundAircraftHTMLTemplate)
+)
+
+const (
+	rawBaggageClaimPlaintextTemplate = `--- Baggage Claim ---
+
+Baggage from flight {{ .FlightNumber }} will be delivered to carousel {{ .BaggageClaim }}{{ if .Destination.Terminal }} in terminal {{ .Destination.Terminal }}{{ end }} at {{ .Destination.Airport }}.
+`
+
+	rawBaggageClaimMarkdownTemplate = `*Baggage Claim*
+
+Baggage from flight *{{ .FlightNumber }}* will be delivered to carousel *{{ .BaggageClaim }}*{{ if .Destination.Terminal }} in terminal *{{ .Destination.Terminal }}*{{ end }} at *{{ .Destination.Airport }}*.
+`
+
+	rawBaggageClaimHTMLTemplate = `<h3>Baggage Claim</h3>
+<p>Baggage from flight <strong>{{ .FlightNumber }}</strong> will be delivered to <strong>carousel {{ .BaggageClaim }}</strong>{{ if .Destination.Terminal }} in <strong>terminal {{ .Destination.Terminal }}</strong>{{ end }} at {{ .Destination.Airport }}.</p>
+`
+)
+
+var (
+	BaggageClaimAlertPlaintextTemplate = mustParseTemplate("baggageClaimAlertPlaintext", rawBaggageClaimPlaintextTemplate)
+	BaggageClaimAlertMarkdownTemplate  = mustParseTemplate("baggageClaimAlertMarkdown", rawBaggageClaimMarkdownTemplate)
+	BaggageClaimAlertHTMLTemplate      = mustParseHTMLTemplate("baggageClaimAlertHTML", rawBaggageClaimHTMLTemplate)
+)
+
+// Catch-up templates summarize every lifecycle event of a flight
+// which happened between two polls, in the order they happened.
+const (
+	rawCatchUpPlaintextTemplate = `--- Flight Status Catch-Up ---
+
+Since the last update, flight {{ .FlightNumber }}:
+{{- if .IsGateDeparture }}
+- departed from {{ .Origin.Airport }} gate {{ .Origin.Gate }} at {{ FormatTimezone .GateDepartureTime.Actual .UseLocalTimezone .Origin.Timezone }}
+{{- end }}
+{{- if .IsTakeoff }}
+- took off from {{ .Origin.Airport }} at {{ FormatTimezone .TakeoffTime.Actual .UseLocalTimezone .Origin.Timezone }}
+{{- end }}
+{{- if .IsDiverted }}
+- was diverted{{ if .DivertedTo }} to {{ .DivertedTo }}{{ end }}
+{{- end }}
+{{- if .IsLanding }}
+- landed at {{ .Destination.Airport }} at {{ FormatTimezone .LandingTime.Actual .UseLocalTimezone .Destination.Timezone }}
+{{- end }}
+{{- if .IsGateArrival }}
+- arrived at {{ .Destination.Airport }} ({{ if .Destination.Terminal }}terminal {{ .Destination.Terminal }} {{ end }}gate {{ .Destination.Gate }}) at {{ FormatTimezone .GateArrivalTime.Actual .UseLocalTimezone .Destination.Timezone }}
+{{- end }}
+`
+
+	rawCatchUpMarkdownTemplate = `*Flight Status Catch-Up*
+
+Since the last update, flight *{{ .FlightNumber }}*:
+{{- if .IsGateDeparture }}
+• departed from *{{ .Origin.Airport }}* gate *{{ .Origin.Gate }}* at {{ FormatTimezone .GateDepartureTime.Actual .UseLocalTimezone .Origin.Timezone }}
+{{- end }}
+{{- if .IsTakeoff }}
+• took off from *{{ .Origin.Airport }}* at {{ FormatTimezone .TakeoffTime.Actual .UseLocalTimezone .Origin.Timezone }}
+{{- end }}
+{{- if .IsDiverted }}
+• was *diverted*{{ if .DivertedTo }} to *{{ .DivertedTo }}*{{ end }}
+{{- end }}
+{{- if .IsLanding }}
+• landed at *{{ .Destination.Airport }}* at {{ FormatTimezone .LandingTime.Actual .UseLocalTimezone .Destination.Timezone }}
+{{- end }}
+{{- if .IsGateArrival }}
+• arrived at *{{ .Destination.Airport }}* ({{ if .Destination.Terminal }}terminal *{{ .Destination.Terminal }}* {{ end }}gate *{{ .Destination.Gate }}*) at {{ FormatTimezone .GateArrivalTime.Actual .UseLocalTimezone .Destination.Timezone }}
+{{- end }}
+`
+
+	rawCatchUpHTMLTemplate = `<h3>Flight Status Catch-Up</h3>
+<p>Since the last update, flight <strong>{{ .FlightNumber }}</strong>:</p>
+<ul>
+{{- if .IsGateDeparture }}
+<li>departed from {{ .Origin.Airport }} gate {{ .Origin.Gate }} at {{ FormatTimezone .GateDepartureTime.Actual .UseLocalTimezone .Origin.Timezone }}</li>
+{{- end }}
+{{- if .IsTakeoff }}
+<li>took off from {{ .Origin.Airport }} at {{ FormatTimezone .TakeoffTime.Actual .UseLocalTimezone .Origin.Timezone }}</li>
+{{- end }}
+{{- if .IsDiverted }}
+<li>was <strong>diverted</strong>{{ if .DivertedTo }} to {{ .DivertedTo }}{{ end }}</li>
+{{- end }}
+{{- if .IsLanding }}
+<li>landed at {{ .Destination.Airport }} at {{ FormatTimezone .LandingTime.Actual .UseLocalTimezone .Destination.Timezone }}</li>
+{{- end }}
+{{- if .IsGateArrival }}
+<li>arrived at {{ .Destination.Airport }} ({{ if .Destination.Terminal }}terminal {{ .Destination.Terminal }} {{ end }}gate {{ .Destination.Gate }}) at {{ FormatTimezone .GateArrivalTime.Actual .UseLocalTimezone .Destination.Timezone }}</li>
+{{- end }}
+</ul>
+`
+)
+
+var (
+	CatchUpAlertPlaintextTemplate = mustParseTemplate("catchUpAlertPlaintext", rawCatchUpPlaintextTemplate)
+	CatchUpAlertMarkdownTemplate  = mustParseTemplate("catchUpAlertMarkdown", rawCatchUpMarkdownTemplate)
+	CatchUpAlertHTMLTemplate      = mustParseHTMLTemplate("catchUpAlertHTML", rawCatchUpHTMLTemplate)
+)
@@ -0,0 +1,273 @@
+package messages
+
+import (
+	htmltemplate "html/template"
+	"io/ioutil"
+	"sort"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+// Template kinds, used as keys in a TemplatesConfig.
+const (
+	DepartureTemplates       string = "departure"
+	ArrivalTemplates         string = "arrival"
+	PreDepartureTemplates    string = "pre_departure"
+	PreArrivalTemplates      string = "pre_arrival"
+	SpotPriceTemplates       string = "spot_price"
+	GateChangeTemplates      string = "gate_change"
+	DelayTemplates           string = "delay"
+	CancellationTemplates    string = "cancellation"
+	DiversionTemplates       string = "diversion"
+	ConnectionTemplates      string = "connection"
+	InboundAircraftTemplates string = "inbound_aircraft"
+	BaggageClaimTemplates    string = "baggage_claim"
+	CatchUpTemplates         string = "catch_up"
+)
+
+// MessageTemplates contains the templates used to format a single kind of message.
+type MessageTemplates struct {
+	Plaintext *template.Template
+	Markdown  *template.Template
+	HTML      *htmltemplate.Template
+}
+
+// templateKind is a kind of message which can have its templates overridden.
+// sample is executed with every user-supplied template during validation.
+type templateKind struct {
+	sample    any
+	templates MessageTemplates
+}
+
+// builtinTemplates contains the built-in templates of every template kind.
+// Adding a new kind of message only requires adding an entry here.
+var builtinTemplates = map[string]templateKind{
+	DepartureTemplates: {
+		sample: FlightAwareAlert{IsGateDeparture: true},
+		templates: MessageTemplates{
+			Plaintext: DepartureAlertPlaintextTemplate,
+			Markdown:  DepartureAlertMarkdownTemplate,
+			HTML:      DepartureAlertHTMLTemplate,
+		},
+	},
+	ArrivalTemplates: {
+		sample: FlightAwareAlert{IsGateArrival: true},
+		templates: MessageTemplates{
+			Plaintext: ArrivalAlertPlaintextTemplate,
+			Markdown:  ArrivalAlertMarkdownTemplate,
+			HTML:      ArrivalAlertHTMLTemplate,
+		},
+	},
+	PreDepartureTemplates: {
+		sample: FlightAwareAlert{},
+		templates: MessageTemplates{
+			Plaintext: PreDepartureAlertPlaintextTemplate,
+			Markdown:  PreDepartureAlertMarkdownTemplate,
+			HTML:      PreDepartureAlertHTMLTemplate,
+		},
+	},
+	PreArrivalTemplates: {
+		sample: FlightAwareAlert{},
+		templates: MessageTemplates{
+			Plaintext: PreArrivalAlertPlaintextTemplate,
+			Markdown:  PreArrivalAlertMarkdownTemplate,
+			HTML:      PreArrivalAlertHTMLTemplate,
+		},
+	},
+	GateChangeTemplates: {
+		sample: FlightAwareAlert{IsGateChange: true},
+		templates: MessageTemplates{
+			Plaintext: GateChangeAlertPlaintextTemplate,
+			Markdown:  GateChangeAlertMarkdownTemplate,
+			HTML:      GateChangeAlertHTMLTemplate,
+		},
+	},
+	DelayTemplates: {
+		sample: FlightAwareAlert{IsDelay: true},
+		templates: MessageTemplates{
+			Plaintext: DelayAlertPlaintextTemplate,
+			Markdown:  DelayAlertMarkdownTemplate,
+			HTML:      DelayAlertHTMLTemplate,
+		},
+	},
+	CancellationTemplates: {
+		sample: FlightAwareAlert{IsCancelled: true},
+		templates: MessageTemplates{
+			Plaintext: CancellationAlertPlaintextTemplate,
+			Markdown:  CancellationAlertMarkdownTemplate,
+			HTML:      CancellationAlertHTMLTemplate,
+		},
+	},
+	DiversionTemplates: {
+		sample: FlightAwareAlert{IsDiverted: true},
+		templates: MessageTemplates{
+			Plaintext: DiversionAlertPlaintextTemplate,
+			Markdown:  DiversionAlertMarkdownTemplate,
+			HTML:      DiversionAlertHTMLTemplate,
+		},
+	},
+	InboundAircraftTemplates: {
+		sample: FlightAwareAlert{IsInboundLate: true},
+		templates: MessageTemplates{
+			Plaintext: InboundAircraftAlertPlaintextTemplate,
+			Markdown:  InboundAircraftAlertMarkdownTemplate,
+			HTML:      InboundAircraftAlertHTMLTemplate,
+		},
+	},
+	BaggageClaimTemplates: {
+		sample: FlightAwareAlert{IsBaggageClaim: true, BaggageClaim: "4"},
+		templates: MessageTemplates{
+			Plaintext: BaggageClaimAlertPlaintextTemplate,
+			Markdown:  BaggageClaimAlertMarkdownTemplate,
+			HTML:      BaggageClaimAlertHTMLTemplate,
+		},
+	},
+	CatchUpTemplates: {
+		sample: FlightAwareAlert{IsCatchUp: true, IsGateDeparture: true, IsTakeoff: true},
+		templates: MessageTemplates{
+			Plaintext: CatchUpAlertPlaintextTemplate,
+			Markdown:  CatchUpAlertMarkdownTemplate,
+			HTML:      CatchUpAlertHTMLTemplate,
+		},
+	},
+	ConnectionTemplates: {
+		sample: ConnectionAlert{IsAtRisk: true},
+		templates: MessageTemplates{
+			Plaintext: ConnectionAlertPlaintextTemplate,
+			Markdown:  ConnectionAlertMarkdownTemplate,
+			HTML:      ConnectionAlertHTMLTemplate,
+		},
+	},
+	SpotPriceTemplates: {
+		sample: SpotPriceAlert{},
+		templates: MessageTemplates{
+			Plaintext: spotPriceAlertPlaintextTemplate,
+			Markdown:  spotPriceAlertMarkdownTemplate,
+			HTML:      spotPriceAlertHTMLTemplate,
+		},
+	},
+}
+
+// TemplateKinds returns the names of all template kinds, sorted alphabetically.
+func TemplateKinds() []string {
+	kinds := make([]string, 0, len(builtinTemplates))
+	for kind := range builtinTemplates {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// TemplateFiles contains paths to template files for each message format.
+// Formats without a file use the built-in template.
+type TemplateFiles struct {
+	Plaintext string `json:"plaintext,omitempty" yaml:"plaintext,omitempty" toml:"Plaintext,omitempty"`
+	Markdown  string `json:"markdown,omitempty" yaml:"markdown,omitempty" toml:"Markdown,omitempty"`
+	HTML      string `json:"html,omitempty" yaml:"html,omitempty" toml:"HTML,omitempty"`
+}
+
+// TemplatesConfig maps template kinds (see TemplateKinds) to template files.
+type TemplatesConfig map[string]TemplateFiles
+
+// TemplateSet contains the templates for every template kind.
+type TemplateSet struct {
+	templates map[string]MessageTemplates
+}
+
+// DefaultTemplateSet returns a TemplateSet containing only the built-in templates.
+func DefaultTemplateSet() *TemplateSet {
+	s := &TemplateSet{templates: make(map[string]MessageTemplates, len(builtinTemplates))}
+
+	for kind, builtin := range builtinTemplates {
+		s.templates[kind] = builtin.templates
+	}
+
+	return s
+}
+
+// LoadTemplateSet returns a TemplateSet containing the built-in templates,
+// overridden by the template files in the passed TemplatesConfig.
+// Template files are parsed with the same functions available to the built-in templates,
+// and are validated by executing them with a sample message.
+func LoadTemplateSet(conf TemplatesConfig) (*TemplateSet, error) {
+	s := DefaultTemplateSet()
+
+	for kind, files := range conf {
+		builtin, ok := builtinTemplates[kind]
+		if !ok {
+			return nil, errors.Errorf("unknown template kind %[1]s", kind)
+		}
+
+		tmpls := builtin.templates
+
+		if files.Plaintext != "" {
+			tmpl, err := loadTemplateFile(kind+"Plaintext", files.Plaintext, builtin.sample, parseTextTemplate)
+			if err != nil {
+				return nil, err
+			}
+
+			tmpls.Plaintext = tmpl
+		}
+
+		if files.Markdown != "" {
+			tmpl, err := loadTemplateFile(kind+"Markdown", files.Markdown, builtin.sample, parseTextTemplate)
+			if err != nil {
+				return nil, err
+			}
+
+			tmpls.Markdown = tmpl
+		}
+
+		if files.HTML != "" {
+			tmpl, err := loadTemplateFile(kind+"HTML", files.HTML, builtin.sample, parseHTMLTemplate)
+			if err != nil {
+				return nil, err
+			}
+
+			tmpls.HTML = tmpl
+		}
+
+		s.templates[kind] = tmpls
+	}
+
+	return s, nil
+}
+
+// Get returns the templates for the passed template kind.
+func (s *TemplateSet) Get(kind string) MessageTemplates {
+	return s.templates[kind]
+}
+
+func parseTextTemplate(name, raw string) (*template.Template, error) {
+	return template.New(name).Funcs(tmplFnMap).Parse(raw)
+}
+
+func parseHTMLTemplate(name, raw string) (*htmltemplate.Template, error) {
+	return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(tmplFnMap)).Parse(raw)
+}
+
+func loadTemplateFile[T messageTemplate](name, path string, sample any, parse func(name, raw string) (T, error)) (tmpl T, err error) {
+	raw, readErr := ioutil.ReadFile(path)
+	if readErr != nil {
+		err = errs.ReadFileError(readErr, path)
+		return
+	}
+
+	tmpl, err = parse(name, string(raw))
+	if err != nil {
+		err = errors.WithMessagef(err, "error parsing template file %[1]s", path)
+		return
+	}
+
+	if _, execErr := (baseMessage{}).format(tmpl, sample); execErr != nil {
+		err = errors.WithMessagef(execErr, "error validating template file %[1]s", path)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,111 @@
+package messages_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+)
+
+func TestLoadTemplateSet(t *testing.T) {
+	dir := t.TempDir()
+
+	writeTemplate := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		return path
+	}
+
+	var (
+		shortPlaintext = writeTemplate("short.txt", `{{ FormatPair .BaseCurrency .QuoteCurrency }}: {{ FormatDecimal .SpotPrice }}`)
+		linkHTML       = writeTemplate("link.html", `<a href="https://internal.example.com/{{ .BaseCurrency }}">{{ .BaseCurrency }}</a>`)
+		badSyntax      = writeTemplate("bad_syntax.txt", `{{ .BaseCurrency `)
+		badField       = writeTemplate("bad_field.txt", `{{ .NotAField }}`)
+	)
+
+	msg := messages.SpotPriceAlert{
+		EventTime:     time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+		SpotPrice:     decimal.NewFromInt(1800),
+		BaseAmount:    decimal.NewFromInt(1),
+		BaseCurrency:  "ETH",
+		QuoteCurrency: "USD",
+	}
+
+	tests := []struct {
+		conf          messages.TemplatesConfig
+		name          string
+		wantPlaintext string
+		wantHTML      string
+		wantErr       bool
+	}{
+		{
+			name:          "defaults",
+			wantPlaintext: msg.FormatPlaintext(),
+			wantHTML:      msg.FormatHTML(),
+		},
+		{
+			name: "overrides",
+			conf: messages.TemplatesConfig{
+				messages.SpotPriceTemplates: {Plaintext: shortPlaintext, HTML: linkHTML},
+			},
+			wantPlaintext: "ETH - USD: 1800",
+			wantHTML:      `<a href="https://internal.example.com/ETH">ETH</a>`,
+		},
+		{
+			name:    "unknown kind",
+			conf:    messages.TemplatesConfig{"not_a_kind": {Plaintext: shortPlaintext}},
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			conf:    messages.TemplatesConfig{messages.SpotPriceTemplates: {Plaintext: filepath.Join(dir, "missing.txt")}},
+			wantErr: true,
+		},
+		{
+			name:    "bad syntax",
+			conf:    messages.TemplatesConfig{messages.SpotPriceTemplates: {Markdown: badSyntax}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			conf:    messages.TemplatesConfig{messages.DepartureTemplates: {Plaintext: badField}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := messages.LoadTemplateSet(tt.conf)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, kind := range messages.TemplateKinds() {
+				tmpls := set.Get(kind)
+				assert.NotNil(t, tmpls.Plaintext, kind)
+				assert.NotNil(t, tmpls.Markdown, kind)
+				assert.NotNil(t, tmpls.HTML, kind)
+			}
+
+			m := msg
+			m.SetTemplates(set.Get(messages.SpotPriceTemplates))
+
+			assert.Equal(t, tt.wantPlaintext, m.FormatPlaintext())
+			assert.Equal(t, tt.wantHTML, m.FormatHTML())
+			assert.Equal(t, msg.FormatMarkdown(), m.FormatMarkdown())
+		})
+	}
+}
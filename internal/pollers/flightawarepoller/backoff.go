@@ -0,0 +1,74 @@
+package flightawarepoller
+
+import (
+	"time"
+
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+const (
+	// maxPollInterval is the longest interval between polls
+	// while fetches are failing, unless the interval in use is longer.
+	maxPollInterval = 30 * time.Minute
+)
+
+// fetchBackoff tracks the interval between polls
+// while fetches are failing with transient errors.
+// The interval is backed off from the base interval, which is
+// the interval in use while fetches succeed.
+type fetchBackoff struct {
+	base    time.Duration
+	current time.Duration
+}
+
+func newFetchBackoff(base time.Duration) *fetchBackoff {
+	return &fetchBackoff{
+		base:    base,
+		current: base,
+	}
+}
+
+// SetBase sets the interval in use while fetches succeed, ex. after a flight's
+// poll interval has been adapted to its phase, resetting any backoff.
+func (b *fetchBackoff) SetBase(base time.Duration) {
+	b.base = base
+	b.current = base
+}
+
+// Failure returns the interval to wait before the next poll after a failed fetch:
+// the error's Retry-After duration if it has one, otherwise double the current interval,
+// up to maxPollInterval or the base interval, whichever is longer.
+func (b *fetchBackoff) Failure(err error) time.Duration {
+	if retryAfter, ok := errs.RetryAfter(err); ok {
+		b.current = retryAfter
+		if b.current < b.base {
+			b.current = b.base
+		}
+
+		return b.current
+	}
+
+	maxInterval := maxPollInterval
+	if b.base > maxInterval {
+		maxInterval = b.base
+	}
+
+	b.current *= 2
+	if b.current > maxInterval {
+		b.current = maxInterval
+	}
+
+	return b.current
+}
+
+// Success resets the interval after a successful fetch, returning
+// the base interval and true if the interval had been backed off.
+func (b *fetchBackoff) Success() (time.Duration, bool) {
+	if b.current == b.base {
+		return b.base, false
+	}
+
+	b.current = b.base
+
+	return b.base, true
+}
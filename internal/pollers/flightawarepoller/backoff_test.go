@@ -0,0 +1,79 @@
+package flightawarepoller
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+func TestFetchBackoff(t *testing.T) {
+	transientErr := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		base      time.Duration
+		setBase   time.Duration
+		err       error
+		wantPolls []time.Duration
+	}{
+		{
+			name:      "poll interval",
+			base:      time.Minute,
+			err:       transientErr,
+			wantPolls: []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, maxPollInterval, maxPollInterval},
+		},
+		{
+			name:      "adapted interval",
+			base:      time.Minute,
+			setBase:   10 * time.Minute,
+			err:       transientErr,
+			wantPolls: []time.Duration{20 * time.Minute, maxPollInterval, maxPollInterval},
+		},
+		{
+			name:      "interval longer than max",
+			base:      time.Minute,
+			setBase:   4 * time.Hour,
+			err:       transientErr,
+			wantPolls: []time.Duration{4 * time.Hour, 4 * time.Hour},
+		},
+		{
+			name:      "retry after",
+			base:      time.Minute,
+			err:       errs.NewApiError("flightaware", http.StatusTooManyRequests, "300"),
+			wantPolls: []time.Duration{5 * time.Minute, 5 * time.Minute},
+		},
+		{
+			name:      "retry after shorter than interval",
+			base:      time.Minute,
+			setBase:   15 * time.Minute,
+			err:       errs.NewApiError("flightaware", http.StatusTooManyRequests, "300"),
+			wantPolls: []time.Duration{15 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			backoff := newFetchBackoff(tt.base)
+
+			wantBase := tt.base
+			if tt.setBase > 0 {
+				backoff.SetBase(tt.setBase)
+				wantBase = tt.setBase
+			}
+
+			for _, want := range tt.wantPolls {
+				assert.Equal(t, want, backoff.Failure(tt.err))
+			}
+
+			interval, backedOff := backoff.Success()
+			assert.Equal(t, wantBase, interval)
+			assert.Equal(t, tt.wantPolls[len(tt.wantPolls)-1] != wantBase, backedOff)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package flightawarepoller
+
+import (
+	"context"
+	"time"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+// sendChangeAlerts sends notifications for any changes to a flight's data
+// since recipients were last notified about it. It returns true if the flight has been cancelled,
+// in which case no further change notifications will be sent for it.
+func (p *Poller) sendChangeAlerts(
+	ctx context.Context,
+	curr *flightaware.FlightData,
+	originInfo, destinationInfo *flightaware.AirportData,
+	notifsSent *SentNotifications,
+	recipients []string,
+) bool {
+
+	notifsConf := p.FlightAwareConfig().Notifications
+
+	// Gates seen on a flight's first poll aren't a change.
+	if !notifsSent.GatesNotified {
+		notifsSent.SetGatesNotified(curr)
+	}
+
+	for _, notifType := range detectFlightChanges(curr, notifsConf, notifsSent) {
+		msg := newAlertMsg(curr.Identifiers.IATA, curr, originInfo, destinationInfo, notifsConf.UseLocalTime)
+		msg = setMsgChangeData(notifType, notifsSent, curr, destinationInfo, msg, p.Templates())
+
+		sendRes := p.SendMessageTo(ctx, msg, recipients...)
+		p.logSendErrors(sendRes)
+
+		if !sendRes.Ok() {
+			continue
+		}
+
+		// Changes are only recorded once they've been notified about,
+		// so that a failed notification is retried on the next poll.
+		switch notifType {
+		case DelayNotification:
+			notifsSent.SetDelaysNotified(msg.DepartureDelay, msg.ArrivalDelay, notifsConf.Delay)
+		case GateChangeNotification:
+			notifsSent.SetGatesNotified(curr)
+		default:
+			notifsSent.SetSent(notifType)
+		}
+	}
+
+	return curr.Cancelled
+}
+
+// detectFlightChanges compares a flight's current data against what recipients
+// have been notified about, returning the types of change notifications
+// which should be sent.
+// Cancellations and diversions are phases of the flight's lifecycle (see FlightLifecycle),
+// and aren't returned.
+func detectFlightChanges(
+	curr *flightaware.FlightData,
+	notifsConf flightaware.NotificationsConfig,
+	notifsSent *SentNotifications,
+) []notificationType {
+
+	// Nothing about a cancelled flight is worth notifying about,
+	// other than its cancellation.
+	if curr.Cancelled {
+		return nil
+	}
+
+	var changes []notificationType
+
+	if notifsConf.GateChange && notifsSent.GatesNotified {
+		if originGateChanged(notifsSent.OriginGate, curr) || destinationGateChanged(notifsSent.DestinationGate, curr) {
+			changes = append(changes, GateChangeNotification)
+		}
+	}
+
+	if departureDelay, arrivalDelay := currentDelays(curr); notifsConf.Delay.ShouldNotify(departureDelay, notifsSent.DepartureDelay) ||
+		notifsConf.Delay.ShouldNotify(arrivalDelay, notifsSent.ArrivalDelay) {
+
+		changes = append(changes, DelayNotification)
+	}
+
+	return changes
+}
+
+// currentDelays returns the flight's departure delay (only if it hasn't departed its gate)
+// and arrival delay (only if it hasn't arrived at its gate).
+func currentDelays(flightData *flightaware.FlightData) (departureDelay, arrivalDelay time.Duration) {
+	if !validTimestampActual(flightData.GateDepartureTime) {
+		departureDelay = flightData.DepartureDelay
+	}
+
+	if !validTimestampActual(flightData.GateArrivalTime) {
+		arrivalDelay = flightData.ArrivalDelay
+	}
+
+	return
+}
+
+func originGateChanged(notified NotifiedGate, curr *flightaware.FlightData) bool {
+	if validTimestampActual(curr.GateDepartureTime) {
+		return false
+	}
+
+	return gateChanged(notified, curr.Origin)
+}
+
+func destinationGateChanged(notified NotifiedGate, curr *flightaware.FlightData) bool {
+	if validTimestampActual(curr.GateArrivalTime) {
+		return false
+	}
+
+	return gateChanged(notified, curr.Destination)
+}
+
+// gateChanged returns true if a gate or terminal was assigned or changed.
+// Gates and terminals being unset isn't considered a change.
+func gateChanged(prev NotifiedGate, curr flightaware.FlightOriginDestinationData) bool {
+	return (curr.Gate != "" && curr.Gate != prev.Gate) ||
+		(curr.Terminal != "" && curr.Terminal != prev.Terminal)
+}
+
+// setMsgChangeData sets the change notification's data. notifsSent is only used for gate changes.
+func setMsgChangeData(
+	notifType notificationType,
+	notifsSent *SentNotifications,
+	curr *flightaware.FlightData,
+	destinationInfo *flightaware.AirportData,
+	msg *messages.FlightAwareAlert,
+	templates *messages.TemplateSet,
+) *messages.FlightAwareAlert {
+
+	switch notifType {
+	case GateChangeNotification:
+		msg.IsGateChange = true
+		msg.PreviousOrigin = msg.Origin
+		msg.PreviousDestination = msg.Destination
+
+		if originGateChanged(notifsSent.OriginGate, curr) {
+			msg.PreviousOrigin.Gate = notifsSent.OriginGate.Gate
+			msg.PreviousOrigin.Terminal = notifsSent.OriginGate.Terminal
+		}
+
+		if destinationGateChanged(notifsSent.DestinationGate, curr) {
+			msg.PreviousDestination.Gate = notifsSent.DestinationGate.Gate
+			msg.PreviousDestination.Terminal = notifsSent.DestinationGate.Terminal
+		}
+
+		msg.SetTemplates(templates.Get(messages.GateChangeTemplates))
+	case DelayNotification:
+		msg.IsDelay = true
+		msg.DepartureDelay, msg.ArrivalDelay = currentDelays(curr)
+		msg.SetTemplates(templates.Get(messages.DelayTemplates))
+	case CancellationNotification:
+		msg.IsCancelled = true
+		msg.SetTemplates(templates.Get(messages.CancellationTemplates))
+	case DiversionNotification:
+		msg.IsDiverted = true
+		msg.DivertedTo = divertedTo(curr, destinationInfo)
+
+		msg.SetTemplates(templates.Get(messages.DiversionTemplates))
+	}
+
+	return msg
+}
+
+// divertedTo returns the code of the airport a diverted flight is now flying to,
+// or an empty string if it's still listed as flying to its original destination.
+func divertedTo(flightData *flightaware.FlightData, destinationInfo *flightaware.AirportData) string {
+	identifiers := flightData.Destination.Identifiers
+	if identifiers.ICAO == destinationInfo.Identifiers.ICAO {
+		return ""
+	}
+
+	if identifiers.IATA != "" {
+		return identifiers.IATA
+	}
+
+	return identifiers.Code
+}
@@ -0,0 +1,168 @@
+package flightawarepoller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestDetectFlightChanges(t *testing.T) {
+	departed := flightaware.FlightTimestamp{Actual: time.Date(2022, 5, 31, 19, 35, 0, 0, time.UTC)}
+
+	newFlightData := func(modify func(d *flightaware.FlightData)) *flightaware.FlightData {
+		d := &flightaware.FlightData{
+			Origin:      flightaware.FlightOriginDestinationData{Gate: "37", Terminal: "7"},
+			Destination: flightaware.FlightOriginDestinationData{Gate: "C71", Terminal: "C"},
+		}
+
+		if modify != nil {
+			modify(d)
+		}
+
+		return d
+	}
+
+	tests := []struct {
+		// Flight data recipients were last notified about, if any.
+		prev       *flightaware.FlightData
+		curr       *flightaware.FlightData
+		notifsSent SentNotifications
+		name       string
+		want       []notificationType
+	}{
+		{
+			name: "no changes",
+			prev: newFlightData(nil),
+			curr: newFlightData(nil),
+		},
+		{
+			name: "no previous data",
+			curr: newFlightData(nil),
+		},
+		{
+			name: "origin gate change",
+			prev: newFlightData(nil),
+			curr: newFlightData(func(d *flightaware.FlightData) { d.Origin.Gate = "41" }),
+			want: []notificationType{GateChangeNotification},
+		},
+		{
+			name: "destination terminal assigned",
+			prev: newFlightData(func(d *flightaware.FlightData) { d.Destination.Terminal = "" }),
+			curr: newFlightData(nil),
+			want: []notificationType{GateChangeNotification},
+		},
+		{
+			name: "gate unset",
+			prev: newFlightData(nil),
+			curr: newFlightData(func(d *flightaware.FlightData) { d.Origin.Gate = "" }),
+		},
+		{
+			name: "origin gate change after departure",
+			prev: newFlightData(nil),
+			curr: newFlightData(func(d *flightaware.FlightData) {
+				d.Origin.Gate = "41"
+				d.GateDepartureTime = departed
+			}),
+		},
+		{
+			name: "delay below threshold",
+			curr: newFlightData(func(d *flightaware.FlightData) { d.DepartureDelay = 10 * time.Minute }),
+		},
+		{
+			name: "delay crosses threshold",
+			curr: newFlightData(func(d *flightaware.FlightData) { d.DepartureDelay = 20 * time.Minute }),
+			want: []notificationType{DelayNotification},
+		},
+		{
+			name:       "delay already notified",
+			curr:       newFlightData(func(d *flightaware.FlightData) { d.DepartureDelay = 25 * time.Minute }),
+			notifsSent: SentNotifications{DepartureDelay: 20 * time.Minute},
+		},
+		{
+			name:       "delay slips further",
+			curr:       newFlightData(func(d *flightaware.FlightData) { d.ArrivalDelay = 40 * time.Minute }),
+			notifsSent: SentNotifications{ArrivalDelay: 20 * time.Minute},
+			want:       []notificationType{DelayNotification},
+		},
+		{
+			name: "departure delay after departure",
+			curr: newFlightData(func(d *flightaware.FlightData) {
+				d.DepartureDelay = 20 * time.Minute
+				d.GateDepartureTime = departed
+			}),
+		},
+		{
+			name: "cancelled",
+			prev: newFlightData(nil),
+			curr: newFlightData(func(d *flightaware.FlightData) {
+				d.Cancelled = true
+				d.Origin.Gate = "41"
+				d.DepartureDelay = time.Hour
+			}),
+		},
+		{
+			name: "diverted",
+			curr: newFlightData(func(d *flightaware.FlightData) { d.Diverted = true }),
+		},
+	}
+
+	notifsConf := flightaware.DefaultNotificationsConfig()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prev != nil {
+				tt.notifsSent.SetGatesNotified(tt.prev)
+			}
+
+			got := detectFlightChanges(tt.curr, notifsConf, &tt.notifsSent)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSentNotifications_SetDelaysNotified(t *testing.T) {
+	delayConf := flightaware.DefaultNotificationsConfig().Delay
+
+	var notifsSent SentNotifications
+
+	notifsSent.SetDelaysNotified(5*time.Minute, 20*time.Minute, delayConf)
+	assert.Equal(t, time.Duration(0), notifsSent.DepartureDelay)
+	assert.Equal(t, 20*time.Minute, notifsSent.ArrivalDelay)
+
+	// The departure delay wasn't recorded, so it notifies as soon as it crosses the threshold.
+	assert.True(t, delayConf.ShouldNotify(16*time.Minute, notifsSent.DepartureDelay))
+	assert.False(t, delayConf.ShouldNotify(30*time.Minute, notifsSent.ArrivalDelay))
+	assert.True(t, delayConf.ShouldNotify(35*time.Minute, notifsSent.ArrivalDelay))
+}
+
+func TestSentNotifications_SetGatesNotified(t *testing.T) {
+	notifsConf := flightaware.DefaultNotificationsConfig()
+
+	notifsSent := SentNotifications{}
+	notifsSent.SetGatesNotified(&flightaware.FlightData{
+		Origin: flightaware.FlightOriginDestinationData{Gate: "37", Terminal: "7"},
+	})
+
+	changed := &flightaware.FlightData{
+		Origin: flightaware.FlightOriginDestinationData{Gate: "41", Terminal: "7"},
+	}
+
+	// Until the change has been notified about (ex. its notification failed to send),
+	// it's detected on every poll.
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, []notificationType{GateChangeNotification}, detectFlightChanges(changed, notifsConf, &notifsSent))
+	}
+
+	notifsSent.SetGatesNotified(changed)
+	assert.Equal(t, NotifiedGate{Gate: "41", Terminal: "7"}, notifsSent.OriginGate)
+	assert.Empty(t, detectFlightChanges(changed, notifsConf, &notifsSent))
+
+	// Unset gates aren't recorded.
+	notifsSent.SetGatesNotified(&flightaware.FlightData{})
+	assert.Equal(t, NotifiedGate{Gate: "41", Terminal: "7"}, notifsSent.OriginGate)
+}
@@ -0,0 +1,486 @@
+package flightawarepoller_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jalavosus/stuffnotifier/internal/datastore"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/flightawarepoller"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+	"github.com/jalavosus/stuffnotifier/internal/testservers"
+	"github.com/jalavosus/stuffnotifier/pkg/clock"
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+	"github.com/jalavosus/stuffnotifier/pkg/slack"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
+)
+
+const (
+	e2ePollInterval = 20 * time.Millisecond
+	e2eTimeout      = 10 * time.Second
+)
+
+var (
+	e2eOrigin = flightaware.AirportData{
+		Identifiers: flightaware.AirportIdentifiers{Code: "KEWR", ICAO: "KEWR", IATA: "EWR"},
+		Name:        "Newark Liberty Intl",
+		Timezone:    "America/New_York",
+		CountryCode: "US",
+	}
+	e2eDestination = flightaware.AirportData{
+		Identifiers: flightaware.AirportIdentifiers{Code: "KSFO", ICAO: "KSFO", IATA: "SFO"},
+		Name:        "San Francisco Int'l",
+		Timezone:    "America/Los_Angeles",
+		CountryCode: "US",
+	}
+)
+
+func TestPoller_Start_EndToEnd(t *testing.T) {
+	var (
+		aeroApi  = testservers.NewAeroAPI(t)
+		slackApi = testservers.NewSlack(t)
+	)
+
+	timeline := e2eTimeline()
+
+	aeroApi.AddFlight(timeline).AddAirport(e2eOrigin).AddAirport(e2eDestination)
+
+	conf := e2eConfig(aeroApi.URL(), slackApi.URL())
+
+	p, err := flightawarepoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	err = p.Start(ctx, flightaware.FlightConfig{
+		Identifier:     timeline.Current().FlightId,
+		IdentifierType: flightaware.FlightAwareIdIdentifierType,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ctx.Err(), "poller should stop once every notification has been sent")
+
+	assertE2EMessages(t, slackApi.Messages())
+}
+
+func TestPoller_Start_RecordReplay(t *testing.T) {
+	var (
+		aeroApi      = testservers.NewAeroAPI(t)
+		timeline     = e2eTimeline()
+		cassettePath = filepath.Join(t.TempDir(), "aeroapi.jsonl")
+		recorded     []testservers.SlackMessage
+	)
+
+	aeroApi.AddFlight(timeline).AddAirport(e2eOrigin).AddAirport(e2eDestination)
+
+	startFlight := flightaware.FlightConfig{
+		Identifier:     timeline.Current().FlightId,
+		IdentifierType: flightaware.FlightAwareIdIdentifierType,
+	}
+
+	t.Run("record", func(t *testing.T) {
+		slackApi := testservers.NewSlack(t)
+
+		conf := e2eConfig(aeroApi.URL(), slackApi.URL())
+		conf.FlightAware.Cassette = transport.CassetteConfig{Mode: transport.CassetteRecord, Path: cassettePath}
+
+		p, err := flightawarepoller.NewPoller(conf, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+		defer cancel()
+
+		require.NoError(t, p.Start(ctx, startFlight))
+
+		recorded = slackApi.Messages()
+		assertE2EMessages(t, recorded)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		slackApi := testservers.NewSlack(t)
+		requestsBefore := aeroApi.Requests()
+
+		conf := e2eConfig(aeroApi.URL(), slackApi.URL())
+		conf.FlightAware.PollInterval = time.Hour
+		conf.FlightAware.Cassette = transport.CassetteConfig{Mode: transport.CassetteReplay, Path: cassettePath}
+
+		p, err := flightawarepoller.NewPoller(conf, nil)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+		defer cancel()
+
+		require.NoError(t, p.Start(ctx, startFlight))
+		require.NoError(t, ctx.Err())
+
+		assert.Equal(t, recorded, slackApi.Messages())
+		assert.Equal(t, requestsBefore, aeroApi.Requests(), "replayed requests shouldn't be sent")
+	})
+}
+
+func TestPoller_Start_FakeClock(t *testing.T) {
+	const pollInterval = 5 * time.Minute
+
+	var (
+		aeroApi   = testservers.NewAeroAPI(t)
+		slackApi  = testservers.NewSlack(t)
+		departure = time.Date(2022, 6, 1, 14, 0, 0, 0, time.UTC)
+		fakeClock = clock.NewFake(departure.Add(-50 * time.Minute))
+	)
+
+	timeline := testservers.NewFlightTimeline(
+		testservers.ScheduledFlight("UAL2614-1650000000-airline-0001", "UA2614", e2eOrigin, e2eDestination, departure, 6*time.Hour),
+	).
+		Then(testservers.DepartureGate("C", "C71"), testservers.GateOut(departure)).
+		Then(testservers.WheelsOff(departure.Add(20*time.Minute))).
+		Then(testservers.WheelsOn(departure.Add(5*time.Hour+40*time.Minute))).
+		Then(testservers.ArrivalGate("3", "F12"), testservers.GateIn(departure.Add(5*time.Hour+50*time.Minute))).
+		Then(testservers.BaggageClaim("7")).
+		SetRequestsPerStage(0)
+
+	aeroApi.AddFlight(timeline).AddAirport(e2eOrigin).AddAirport(e2eDestination)
+
+	conf := e2eConfig(aeroApi.URL(), slackApi.URL())
+	conf.FlightAware.PollInterval = pollInterval
+	conf.FlightAware.Notifications.PreDeparture = flightaware.PreEventConfig{
+		Enabled:   true,
+		Estimated: true,
+		Offset:    time.Hour,
+	}
+
+	p, err := flightawarepoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	p.SetClock(fakeClock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		// The flight is found by its flight number alone,
+		// which only works if it's looked up using the fake clock's date.
+		done <- p.Start(ctx, flightaware.FlightConfig{Identifier: "UA2614"})
+	}()
+
+	require.Eventually(t, func() bool { return fakeClock.Waiters() > 0 }, e2eTimeout, time.Millisecond, "poller never started")
+
+	// stepTo moves the clock to the passed time, which fires the poller's ticker,
+	// and waits for the poller to have sent the passed number of messages.
+	stepTo := func(at time.Time, wantMsgs int) {
+		t.Helper()
+
+		fakeClock.Set(at)
+
+		require.Eventually(t, func() bool {
+			return len(slackApi.Messages()) >= wantMsgs
+		}, e2eTimeout, time.Millisecond, "expected %[1]d messages at %[2]s", wantMsgs, at)
+	}
+
+	stepTo(departure.Add(-45*time.Minute), 1)
+
+	for i, at := range []time.Duration{
+		5 * time.Minute,
+		25 * time.Minute,
+		5*time.Hour + 45*time.Minute,
+		5*time.Hour + 55*time.Minute,
+		6*time.Hour + 5*time.Minute,
+	} {
+		require.True(t, timeline.Advance())
+		stepTo(departure.Add(at), i+2)
+	}
+
+	// Every notification has been sent, so the next poll stops the poller.
+	fakeClock.Advance(pollInterval)
+
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("poller didn't stop once every notification was sent")
+	}
+
+	msgs := slackApi.Messages()
+	require.Len(t, msgs, 6)
+	assert.Contains(t, msgs[0].Attachments[0].Text, "Pre-Departure Alert")
+	assertE2EMessages(t, msgs[1:])
+}
+
+func TestPoller_Start_CatchUp(t *testing.T) {
+	var (
+		aeroApi   = testservers.NewAeroAPI(t)
+		slackApi  = testservers.NewSlack(t)
+		departure = time.Now().UTC().Truncate(time.Minute).Add(-6 * time.Hour)
+	)
+
+	// Each of the flight's departure and arrival happen between two polls.
+	timeline := testservers.NewFlightTimeline(
+		testservers.ScheduledFlight("UAL2614-1650000000-airline-0001", "UA2614", e2eOrigin, e2eDestination, departure, 6*time.Hour),
+	).
+		Then(
+			testservers.DepartureGate("C", "C71"),
+			testservers.GateOut(departure),
+			testservers.WheelsOff(departure.Add(20*time.Minute)),
+		).
+		Then(
+			testservers.WheelsOn(departure.Add(5*time.Hour+40*time.Minute)),
+			testservers.ArrivalGate("3", "F12"),
+			testservers.GateIn(departure.Add(5*time.Hour+50*time.Minute)),
+		).
+		Then(testservers.BaggageClaim("7"))
+
+	aeroApi.AddFlight(timeline).AddAirport(e2eOrigin).AddAirport(e2eDestination)
+
+	p, err := flightawarepoller.NewPoller(e2eConfig(aeroApi.URL(), slackApi.URL()), nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	err = p.Start(ctx, flightaware.FlightConfig{
+		Identifier:     timeline.Current().FlightId,
+		IdentifierType: flightaware.FlightAwareIdIdentifierType,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ctx.Err(), "poller should stop once every notification has been sent")
+
+	want := [][]string{
+		{"Flight Status Catch-Up", "departed from *Newark Liberty Intl* gate *C71*", "took off from *Newark Liberty Intl*"},
+		{"Flight Status Catch-Up", "landed at *San Francisco Int'l*", "arrived at *San Francisco Int'l* (terminal *3* gate *F12*)"},
+		{"delivered to carousel *7* in terminal *3*"},
+	}
+
+	msgs := slackApi.Messages()
+	require.Len(t, msgs, len(want))
+
+	for i, msg := range msgs {
+		require.Len(t, msg.Attachments, 1)
+
+		for _, text := range want[i] {
+			assert.Contains(t, msg.Attachments[0].Text, text)
+		}
+	}
+}
+
+func TestPoller_Start_Resume(t *testing.T) {
+	var (
+		aeroApi  = testservers.NewAeroAPI(t)
+		slackApi = testservers.NewSlack(t)
+		timeline = e2eTimeline().SetRequestsPerStage(0)
+	)
+
+	aeroApi.AddFlight(timeline).AddAirport(e2eOrigin).AddAirport(e2eDestination)
+
+	// Stands in for a persistent cache shared by both runs.
+	cache, err := datastore.NewInMemoryDatastore[flightawarepoller.CacheEntry](nil)
+	require.NoError(t, err)
+
+	// advanceTo moves the flight to its next stage,
+	// and waits for the passed number of messages to have been sent.
+	advanceTo := func(wantMsgs int) {
+		t.Helper()
+
+		require.True(t, timeline.Advance())
+		require.Eventually(t, func() bool {
+			return len(slackApi.Messages()) >= wantMsgs
+		}, e2eTimeout, time.Millisecond, "expected %[1]d messages", wantMsgs)
+	}
+
+	conf := e2eConfig(aeroApi.URL(), slackApi.URL())
+
+	first, err := flightawarepoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	first.SetDatastore(cache)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	firstCtx, stopFirst := context.WithCancel(ctx)
+	firstDone := make(chan error, 1)
+
+	go func() {
+		firstDone <- first.Start(firstCtx, flightaware.FlightConfig{
+			Identifier:     timeline.Current().FlightId,
+			IdentifierType: flightaware.FlightAwareIdIdentifierType,
+		})
+	}()
+
+	advanceTo(1)
+	advanceTo(2)
+
+	// Stop once the takeoff notification has been recorded as sent,
+	// rather than while it's being sent.
+	require.Eventually(t, func() bool {
+		entry, ok, _ := cache.Get(ctx, "flightdata:"+timeline.Current().FlightId)
+		return ok && entry.NotificationsSent.Takeoff
+	}, e2eTimeout, time.Millisecond)
+
+	stopFirst()
+	require.NoError(t, <-firstDone)
+
+	// The restarted poller isn't told about the flight, and notifies about it
+	// as configured when the flight was first polled.
+	conf.Resume = true
+	conf.FlightAware.Notifications.Landing = false
+
+	second, err := flightawarepoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	second.SetDatastore(cache)
+
+	secondDone := make(chan error, 1)
+
+	go func() {
+		secondDone <- second.Start(ctx)
+	}()
+
+	advanceTo(3)
+	advanceTo(4)
+	advanceTo(5)
+
+	select {
+	case err = <-secondDone:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("resumed poller didn't stop once every notification was sent")
+	}
+
+	assertE2EMessages(t, slackApi.Messages())
+}
+
+func TestPoller_Start_Resume_UnknownRecipients(t *testing.T) {
+	var (
+		aeroApi  = testservers.NewAeroAPI(t)
+		slackApi = testservers.NewSlack(t)
+		timeline = e2eTimeline()
+		origin   = e2eOrigin
+		dest     = e2eDestination
+	)
+
+	cache, err := datastore.NewInMemoryDatastore[flightawarepoller.CacheEntry](nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	// Stored by a previous run whose config had a recipient group which has since been removed.
+	flightData := timeline.Current()
+	require.NoError(t, cache.Insert(ctx, "flightdata:"+flightData.FlightId, flightawarepoller.CacheEntry{
+		FlightData:      &flightData,
+		OriginData:      &origin,
+		DestinationData: &dest,
+		InternalId:      flightData.FlightId,
+		Recipients:      []string{"removed"},
+	}))
+
+	conf := e2eConfig(aeroApi.URL(), slackApi.URL())
+	conf.Resume = true
+
+	p, err := flightawarepoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	p.SetDatastore(cache)
+
+	assert.EqualError(t, p.Start(ctx), "no flights to track")
+	assert.Empty(t, slackApi.Messages())
+}
+
+func TestPoller_Start_EndToEnd_Unauthorized(t *testing.T) {
+	var (
+		aeroApi  = testservers.NewAeroAPI(t)
+		slackApi = testservers.NewSlack(t)
+	)
+
+	aeroApi.FailNext(401)
+
+	conf := poller.Config{
+		FlightAware: &flightaware.Config{
+			Auth:         &flightaware.AuthConfig{ApiKey: "revoked-key"},
+			BaseUrl:      aeroApi.URL(),
+			PollInterval: e2ePollInterval,
+			Transport:    transport.Config{RateLimit: -1},
+		},
+		Slack: &slack.Config{
+			Auth:     &slack.AuthConfig{Token: "xoxb-test"},
+			Channels: []string{"C0000000001"},
+			BaseUrl:  slackApi.URL(),
+		},
+	}
+
+	p, err := flightawarepoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	err = p.Start(ctx, flightaware.FlightConfig{Identifier: "UA2614"})
+	assert.Error(t, err)
+	assert.Equal(t, 1, aeroApi.Requests(), "unauthorized requests should not be retried")
+	assert.Empty(t, slackApi.Messages())
+}
+
+func e2eTimeline() *testservers.FlightTimeline {
+	departure := time.Now().UTC().Truncate(time.Minute).Add(-time.Hour)
+
+	return testservers.NewFlightTimeline(
+		testservers.ScheduledFlight("UAL2614-1650000000-airline-0001", "UA2614", e2eOrigin, e2eDestination, departure, 6*time.Hour),
+	).
+		Then(testservers.DepartureGate("C", "C71"), testservers.GateOut(departure)).
+		Then(testservers.WheelsOff(departure.Add(20*time.Minute))).
+		Then(testservers.WheelsOn(departure.Add(5*time.Hour+40*time.Minute))).
+		Then(testservers.ArrivalGate("3", "F12"), testservers.GateIn(departure.Add(5*time.Hour+50*time.Minute))).
+		Then(testservers.BaggageClaim("7"))
+}
+
+func e2eConfig(aeroApiUrl, slackUrl string) poller.Config {
+	return poller.Config{
+		FlightAware: &flightaware.Config{
+			Auth:         &flightaware.AuthConfig{ApiKey: "test-key"},
+			BaseUrl:      aeroApiUrl,
+			PollInterval: e2ePollInterval,
+			// Flights are polled every e2ePollInterval, whichever phase they're in.
+			PollSchedule: flightaware.PollScheduleConfig{Disabled: true},
+			MaxPages:     1,
+			Transport:    transport.Config{RateLimit: -1},
+			Notifications: flightaware.NotificationsConfig{
+				GateDeparture: true,
+				Takeoff:       true,
+				Landing:       true,
+				GateArrival:   true,
+				BaggageClaim:  true,
+			},
+		},
+		Slack: &slack.Config{
+			Auth:     &slack.AuthConfig{Token: "xoxb-test"},
+			Channels: []string{"C0000000001"},
+			BaseUrl:  slackUrl,
+		},
+	}
+}
+
+// assertE2EMessages asserts that the passed messages are the notifications
+// sent for the flight returned by e2eTimeline, in order.
+func assertE2EMessages(t *testing.T, msgs []testservers.SlackMessage) {
+	t.Helper()
+
+	want := []string{
+		"departed from *Newark Liberty Intl* gate *C71*",
+		"took off from *Newark Liberty Intl*",
+		"landed at *San Francisco Int'l*",
+		"arrived at *San Francisco Int'l* (terminal *3* gate *F12*)",
+		"delivered to carousel *7* in terminal *3*",
+	}
+
+	require.Len(t, msgs, len(want))
+
+	for i, msg := range msgs {
+		assert.Equal(t, "C0000000001", msg.Channel)
+		require.Len(t, msg.Attachments, 1)
+		assert.Contains(t, msg.Attachments[0].Text, want[i])
+	}
+}
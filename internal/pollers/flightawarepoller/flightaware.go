@@ -3,18 +3,35 @@ package flightawarepoller
 import (
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/jalavosus/stuffnotifier/internal/datastore"
 	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
 	"github.com/jalavosus/stuffnotifier/pkg/authdata"
 	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 )
 
 const (
 	fetchDataTimeout = time.Minute
+	// Time to wait after a flight arrives at its gate (or lands, if
+	// its gate arrival isn't known) for its baggage claim to be posted.
+	baggageClaimTimeout = time.Hour
+	// Flights filed for a watched registration are only tracked
+	// once they are scheduled to depart within this window.
+	registrationLookahead = 24 * time.Hour
+	// Time to keep a flight's cached data after it's expected
+	// to arrive at its gate.
+	cacheRetention = 12 * time.Hour
+	// Number of polls of a cancelled flight on which sending its
+	// cancellation notification is attempted before giving up.
+	maxCancellationAttempts = 5
 )
 
 type Poller struct {
 	datastore datastore.Datastore[CacheEntry]
+	// Stores the alerts sent for each itinerary's connections.
+	connectionStore datastore.Datastore[[]connectionState]
 	*poller.BasePoller
 	flightawareClient *flightaware.Client
 	flightawareConfig flightaware.Config
@@ -22,8 +39,9 @@ type Poller struct {
 
 func NewPoller(conf poller.Config, flightawareConf *flightaware.Config) (*Poller, error) {
 	var (
-		faConf       = flightaware.DefaultConfig()
-		datastoreErr error
+		faConf        = flightaware.DefaultConfig()
+		basePollerErr error
+		datastoreErr  error
 	)
 
 	switch {
@@ -33,16 +51,27 @@ func NewPoller(conf poller.Config, flightawareConf *flightaware.Config) (*Poller
 		faConf = *flightawareConf
 	}
 
+	faConf.Notifications = faConf.Notifications.WithDefaultTriggers()
+
 	p := &Poller{
-		BasePoller:        poller.NewBasePoller(conf),
 		flightawareConfig: faConf,
 	}
 
+	p.BasePoller, basePollerErr = poller.NewBasePoller(conf)
+	if basePollerErr != nil {
+		return nil, basePollerErr
+	}
+
 	p.datastore, datastoreErr = datastore.NewDatastore[CacheEntry](conf.Cache)
 	if datastoreErr != nil {
 		return nil, datastoreErr
 	}
 
+	p.connectionStore, datastoreErr = datastore.NewDatastore[[]connectionState](conf.Cache)
+	if datastoreErr != nil {
+		return nil, datastoreErr
+	}
+
 	p.SetPollInterval(faConf.PollInterval)
 
 	return p, nil
@@ -56,10 +85,44 @@ func (p *Poller) Datastore() datastore.Datastore[CacheEntry] {
 	return p.datastore
 }
 
+// SetDatastore sets the datastore which flight data is cached in,
+// ex. to share one between pollers.
+func (p *Poller) SetDatastore(ds datastore.Datastore[CacheEntry]) *Poller {
+	p.datastore = ds
+
+	return p
+}
+
 func (p *Poller) FlightAwareClient() *flightaware.Client {
 	return p.flightawareClient
 }
 
-func (p *Poller) initFlightAwareClient(authData authdata.AuthData) {
-	p.flightawareClient = flightaware.NewClient(authData)
+func (p *Poller) initFlightAwareClient(authData authdata.AuthData) error {
+	var (
+		faConf        = p.FlightAwareConfig()
+		transportConf = faConf.Transport
+	)
+
+	baseTransport, err := p.CassetteTransport(faConf.Cassette)
+	if err != nil {
+		return err
+	}
+
+	if faConf.Cassette.Mode == transport.CassetteReplay {
+		// Replayed responses don't count against AeroAPI's rate limits.
+		transportConf.RateLimit = -1
+	}
+
+	client, err := flightaware.NewClient(authData).SetBaseUrl(faConf.BaseUrl)
+	if err != nil {
+		return errors.WithMessage(err, "invalid flightaware config")
+	}
+
+	p.flightawareClient = client.
+		SetMaxPages(faConf.MaxPages).
+		SetClock(p.Clock()).
+		SetTransportConfig(transportConf).
+		SetBaseTransport(baseTransport)
+
+	return nil
 }
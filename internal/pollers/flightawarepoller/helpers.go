@@ -4,14 +4,22 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jalavosus/stuffnotifier/internal/datastore"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 )
 
 func (p Poller) buildCacheEntry(
 	flightData *flightaware.FlightData,
 	origin, destination *flightaware.AirportData,
 	notificationsSent *SentNotifications,
+	lifecycle FlightLifecycle,
+	flight trackedFlight,
 ) CacheEntry {
 
 	return CacheEntry{
@@ -25,21 +33,24 @@ func (p Poller) buildCacheEntry(
 		PollInterval:      p.FlightAwareConfig().PollInterval,
 		Notifications:     p.FlightAwareConfig().Notifications,
 		RecipientConfig:   p.BuildRecipientConfig(),
+		Recipients:        flight.recipients,
+		Registration:      flight.registration,
 		NotificationsSent: notificationsSent,
+		Lifecycle:         lifecycle,
 	}
 }
 
 func (p Poller) fetchFlightIdentifiers(
 	ctx context.Context,
-	flightId string,
-	flightIdType flightaware.IdentifierType,
+	params flightaware.FlightInformationParams,
 ) (*flightaware.FlightIdentifiers, string, error) {
 
-	params := buildFlightInformationParams(flightId, flightIdType)
-
 	ctx, cancel := context.WithTimeout(ctx, fetchDataTimeout)
 	defer cancel()
 
+	ctx, logUsage := p.trackUsage(ctx, "flight_identifiers")
+	defer logUsage()
+
 	identifiers, apiId, err := p.flightawareClient.FlightIdentifiers(ctx, params)
 	if err != nil {
 		return nil, "", err
@@ -56,9 +67,23 @@ func (p Poller) fetchFlight(
 
 	params := buildFlightInformationParams(flightId, flightIdType)
 
+	return p.fetchFlightWithParams(ctx, params, zap.String("flight_id", flightId))
+}
+
+// fetchFlightWithParams returns the flight matching the passed params.
+// The passed fields are included in the call's usage log.
+func (p Poller) fetchFlightWithParams(
+	ctx context.Context,
+	params flightaware.FlightInformationParams,
+	fields ...zap.Field,
+) (*flightaware.FlightData, error) {
+
 	ctx, cancel := context.WithTimeout(ctx, fetchDataTimeout)
 	defer cancel()
 
+	ctx, logUsage := p.trackUsage(ctx, "flight_information", fields...)
+	defer logUsage()
+
 	data, err := p.flightawareClient.FlightInformation(ctx, params)
 	if err != nil {
 		return nil, err
@@ -68,7 +93,7 @@ func (p Poller) fetchFlight(
 		return nil, flightaware.ErrNoFlightsFound
 	}
 
-	flight, ok := flightaware.FindFlightFromParams(data.Flights, params)
+	flight, ok := flightaware.FindFlightFromParamsAt(data.Flights, params, p.Now())
 	if ok {
 		return flight, nil
 	}
@@ -76,10 +101,31 @@ func (p Poller) fetchFlight(
 	return nil, flightaware.ErrNoFlightsFound
 }
 
+// fetchRegistrationFlights returns every flight known for the aircraft with the passed registration.
+func (p Poller) fetchRegistrationFlights(ctx context.Context, registration string) ([]flightaware.FlightData, error) {
+	params := buildFlightInformationParams(registration, flightaware.RegistrationIdent)
+
+	ctx, cancel := context.WithTimeout(ctx, fetchDataTimeout)
+	defer cancel()
+
+	ctx, logUsage := p.trackUsage(ctx, "flight_information", zap.String("registration", registration))
+	defer logUsage()
+
+	data, err := p.flightawareClient.FlightInformation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Flights, nil
+}
+
 func (p Poller) fetchAirport(ctx context.Context, airportId string) (*flightaware.AirportData, error) {
 	ctx, cancel := context.WithTimeout(ctx, fetchDataTimeout)
 	defer cancel()
 
+	ctx, logUsage := p.trackUsage(ctx, "airport_information", zap.String("airport", airportId))
+	defer logUsage()
+
 	data, err := p.flightawareClient.AirportInformation(ctx, airportId)
 	if err != nil {
 		return nil, err
@@ -88,6 +134,27 @@ func (p Poller) fetchAirport(ctx context.Context, airportId string) (*flightawar
 	return data, nil
 }
 
+// trackUsage returns a context which tracks the AeroAPI requests sent with it,
+// and a function which logs their usage once the call is done.
+func (p Poller) trackUsage(ctx context.Context, call string, fields ...zap.Field) (context.Context, func()) {
+	ctx, usage := transport.WithUsage(ctx)
+
+	return ctx, func() {
+		stats := usage.Stats()
+
+		p.LogDebug(
+			"aeroapi usage",
+			append(
+				fields,
+				zap.String("call", call),
+				zap.Int("requests", stats.Requests),
+				zap.Int("retries", stats.Retries),
+				zap.Float64("cost", stats.Cost),
+			)...,
+		)
+	}
+}
+
 func (p Poller) fetchAll(
 	ctx context.Context,
 	flightId string,
@@ -112,11 +179,11 @@ func (p Poller) fetchAll(
 	return
 }
 
-func (p Poller) fetchCacheEntry(ctx context.Context, cacheKey string) (cacheData *CacheEntry, ok bool, err error) {
+func (p Poller) fetchCacheEntry(ctx context.Context, cacheKey string) (cacheData *CacheEntry, version uint64, ok bool, err error) {
 	ctx, cancel := context.WithTimeout(ctx, fetchDataTimeout)
 	defer cancel()
 
-	cacheData, ok, err = p.Datastore().Get(ctx, cacheKey)
+	cacheData, version, ok, err = p.Datastore().GetVersioned(ctx, cacheKey)
 	if err != nil {
 		ok = false
 	}
@@ -124,20 +191,115 @@ func (p Poller) fetchCacheEntry(ctx context.Context, cacheKey string) (cacheData
 	return
 }
 
+// setCacheEntry stores the flight's data in the cache, expiring it a while after the flight arrives.
+// If the passed version is non-nil, the entry is only stored if the cached entry is still at that version,
+// and the version is updated once it's stored. If the cached entry has since expired or been evicted,
+// it's re-created; if another poller has stored the flight since, datastore.ErrVersionMismatch is returned.
 func (p *Poller) setCacheEntry(
 	ctx context.Context,
 	cacheKey string,
+	version *uint64,
 	flightData *flightaware.FlightData,
 	origin, dest *flightaware.AirportData,
 	notificationsSent *SentNotifications,
+	lifecycle FlightLifecycle,
+	flight trackedFlight,
 ) error {
 
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	cacheData := p.buildCacheEntry(flightData, origin, dest, notificationsSent)
+	cacheData := p.buildCacheEntry(flightData, origin, dest, notificationsSent, lifecycle, flight)
 
-	return p.Datastore().Insert(ctx, cacheKey, cacheData)
+	var opts []datastore.InsertOption
+
+	if ttl, ok := cacheTtl(flightData, p.Now()); ok {
+		opts = append(opts, datastore.WithTtl(ttl))
+	}
+
+	if version == nil {
+		return p.Datastore().Insert(ctx, cacheKey, cacheData, opts...)
+	}
+
+	err := p.Datastore().Insert(ctx, cacheKey, cacheData, append(opts, datastore.IfVersion(*version))...)
+	if err == nil {
+		*version++
+		return nil
+	} else if !errors.Is(err, datastore.ErrVersionMismatch) {
+		return err
+	}
+
+	_, current, ok, getErr := p.Datastore().GetVersioned(ctx, cacheKey)
+	if getErr != nil {
+		return errors.WithMessage(getErr, "error checking flight's cache entry version")
+	} else if ok {
+		return errors.WithMessagef(err, "flight's cache entry is at version %[1]d", current)
+	}
+
+	p.LogWarning("flight's cache entry expired or was evicted, re-creating it", zap.String("cache_key", cacheKey))
+
+	// Another poller could re-create the entry first, in which case it owns the flight.
+	if insertErr := p.Datastore().Insert(ctx, cacheKey, cacheData, append(opts, datastore.IfAbsent())...); insertErr != nil {
+		if errors.Is(insertErr, datastore.ErrKeyExists) {
+			return err
+		}
+
+		return insertErr
+	}
+
+	*version = 1
+
+	return nil
+}
+
+// logSendErrors logs every notifier which failed to send a notification.
+// A notification delivered by any notifier is recorded as sent (see poller.SendResult.Ok),
+// so notifiers which failed alongside one which succeeded aren't retried.
+func (p Poller) logSendErrors(sendRes poller.SendResult) {
+	partial := sendRes.Ok()
+
+	for _, res := range sendRes.Failed() {
+		p.LogError(
+			"error sending notification",
+			zap.String("notifier", res.Notifier),
+			zap.Int("sent", res.Sent),
+			zap.Bool("partial_delivery", partial),
+			zap.Error(res.Err),
+		)
+	}
+}
+
+// cacheTtl returns how long the passed flight's cache entry should be kept at `now`:
+// until cacheRetention after the flight's (expected) gate arrival.
+// Returns false if the arrival time isn't known or the retention period has passed,
+// in which case the datastore's default TTL should be used.
+func cacheTtl(flightData *flightaware.FlightData, now time.Time) (time.Duration, bool) {
+	arrival := flightData.GateArrivalTime.Actual
+	if arrival.IsZero() {
+		arrival = flightData.GateArrivalTime.Estimated
+	}
+	if arrival.IsZero() {
+		arrival = flightData.GateArrivalTime.Scheduled
+	}
+
+	if arrival.IsZero() {
+		return 0, false
+	}
+
+	ttl := arrival.Add(cacheRetention).Sub(now)
+
+	return ttl, ttl > 0
+}
+
+// baggageClaimTimedOut returns true if baggageClaimTimeout has passed since the flight arrived at its gate,
+// or since it landed if FlightAware has no record of its gate arrival.
+func baggageClaimTimedOut(flightData *flightaware.FlightData, now time.Time) bool {
+	arrival := flightData.GateArrivalTime.Actual
+	if arrival.IsZero() {
+		arrival = flightData.RunwayArrivalTime.Actual
+	}
+
+	return !arrival.IsZero() && now.Sub(arrival) >= baggageClaimTimeout
 }
 
 func buildFlightInformationParams(flightId string, idType flightaware.IdentifierType) (params flightaware.FlightInformationParams) {
@@ -148,6 +310,8 @@ func buildFlightInformationParams(flightId string, idType flightaware.Identifier
 		params.FlightId = utils.ToPointer(flightId)
 	case flightaware.DesignatorIdent:
 		params.FlightDesignator = utils.ToPointer(flightId)
+	case flightaware.RegistrationIdent:
+		params.Registration = utils.ToPointer(flightId)
 	}
 
 	return
@@ -156,3 +320,19 @@ func buildFlightInformationParams(flightId string, idType flightaware.Identifier
 func validTimestampActual(ts flightaware.FlightTimestamp) bool {
 	return !ts.Actual.IsZero()
 }
+
+// replayEnded returns true if the passed error signals that every response
+// recorded for a request has been replayed, which ends polling without an error.
+func replayEnded(err error) bool {
+	return errors.Is(err, transport.ErrCassetteExhausted)
+}
+
+// ignoreReplayEnd returns nil if the passed error signals the end of a replay,
+// and the error otherwise.
+func ignoreReplayEnd(err error) error {
+	if replayEnded(err) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,158 @@
+package flightawarepoller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jalavosus/stuffnotifier/internal/datastore"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestCacheTtl(t *testing.T) {
+	var (
+		now       = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		scheduled = now.Add(5 * time.Hour)
+		estimated = scheduled.Add(time.Hour)
+	)
+
+	tests := []struct {
+		name    string
+		arrival flightaware.FlightTimestamp
+		want    time.Duration
+		wantOk  bool
+	}{
+		{
+			name:    "scheduled",
+			arrival: flightaware.FlightTimestamp{Scheduled: scheduled},
+			want:    5*time.Hour + cacheRetention,
+			wantOk:  true,
+		},
+		{
+			name:    "estimated",
+			arrival: flightaware.FlightTimestamp{Scheduled: scheduled, Estimated: estimated},
+			want:    6*time.Hour + cacheRetention,
+			wantOk:  true,
+		},
+		{
+			name:    "actual",
+			arrival: flightaware.FlightTimestamp{Scheduled: scheduled, Estimated: estimated, Actual: now.Add(-time.Hour)},
+			want:    cacheRetention - time.Hour,
+			wantOk:  true,
+		},
+		{
+			name:    "unknown",
+			arrival: flightaware.FlightTimestamp{},
+			wantOk:  false,
+		},
+		{
+			name:    "retention passed",
+			arrival: flightaware.FlightTimestamp{Scheduled: now.Add(-cacheRetention)},
+			wantOk:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, ok := cacheTtl(&flightaware.FlightData{GateArrivalTime: tt.arrival}, now)
+			assert.Equal(t, tt.wantOk, ok)
+
+			if tt.wantOk {
+				assert.Equal(t, tt.want, ttl)
+			}
+		})
+	}
+}
+
+func TestBaggageClaimTimedOut(t *testing.T) {
+	var (
+		now     = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		landed  = flightaware.FlightTimestamp{Actual: now.Add(-baggageClaimTimeout - 10*time.Minute)}
+		atGate  = flightaware.FlightTimestamp{Actual: now.Add(-baggageClaimTimeout + 5*time.Minute)}
+		pending = flightaware.FlightTimestamp{Scheduled: now.Add(-2 * baggageClaimTimeout)}
+	)
+
+	tests := []struct {
+		name        string
+		landing     flightaware.FlightTimestamp
+		gateArrival flightaware.FlightTimestamp
+		want        bool
+	}{
+		{
+			name: "not landed",
+		},
+		{
+			name:        "at gate",
+			landing:     landed,
+			gateArrival: atGate,
+		},
+		{
+			name:        "no gate arrival",
+			landing:     landed,
+			gateArrival: pending,
+			want:        true,
+		},
+		{
+			name:        "gate arrival timed out",
+			landing:     landed,
+			gateArrival: flightaware.FlightTimestamp{Actual: landed.Actual.Add(5 * time.Minute)},
+			want:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			flightData := &flightaware.FlightData{RunwayArrivalTime: tt.landing, GateArrivalTime: tt.gateArrival}
+			assert.Equal(t, tt.want, baggageClaimTimedOut(flightData, now))
+		})
+	}
+}
+
+func TestPoller_SetCacheEntry(t *testing.T) {
+	ctx := context.Background()
+
+	p, err := NewPoller(poller.Config{}, nil)
+	require.NoError(t, err)
+
+	var (
+		flight     = trackedFlight{apiId: "UAL1-1653955200-airline-0123"}
+		cacheKey   = flight.cacheKey()
+		flightData = &flightaware.FlightData{FlightId: flight.apiId}
+		airport    = new(flightaware.AirportData)
+		version    uint64
+	)
+
+	setCacheEntry := func() error {
+		return p.setCacheEntry(ctx, cacheKey, &version, flightData, airport, airport, new(SentNotifications), FlightLifecycle{}, flight)
+	}
+
+	require.NoError(t, setCacheEntry())
+	assert.Equal(t, uint64(1), version)
+
+	// Another poller stores the flight.
+	require.NoError(t, p.Datastore().Insert(ctx, cacheKey, CacheEntry{InternalId: flight.apiId}, datastore.IfVersion(1)))
+
+	assert.ErrorIs(t, setCacheEntry(), datastore.ErrVersionMismatch)
+	assert.Equal(t, uint64(1), version)
+
+	// The entry expires or is evicted, so it's re-created.
+	require.NoError(t, p.Datastore().Delete(ctx, cacheKey))
+
+	require.NoError(t, setCacheEntry())
+	assert.Equal(t, uint64(1), version)
+
+	require.NoError(t, setCacheEntry())
+	assert.Equal(t, uint64(2), version)
+
+	_, cachedVersion, ok, err := p.Datastore().GetVersioned(ctx, cacheKey)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), cachedVersion)
+}
@@ -0,0 +1,107 @@
+package flightawarepoller
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+// sendInboundAlert follows the flight's inbound flight, sending a notification
+// if the inbound aircraft is predicted to arrive too late for the flight to depart on time.
+// Nothing is sent once the flight has departed its gate, or if the airline has
+// already posted a departure delay at least as long as the predicted one.
+// The inbound flight stops being fetched once it arrives at its gate, or once its notification
+// has been sent if no further notifications are configured. Fatal API errors are returned.
+func (p *Poller) sendInboundAlert(
+	ctx context.Context,
+	flightData *flightaware.FlightData,
+	originInfo, destinationInfo *flightaware.AirportData,
+	notifsSent *SentNotifications,
+	recipients []string,
+) error {
+
+	if !p.followsInbound(flightData, notifsSent) {
+		return nil
+	}
+
+	notifsConf := p.FlightAwareConfig().Notifications
+	inboundConf := notifsConf.InboundAircraft
+
+	inbound, err := p.fetchFlight(ctx, flightData.InboundFlightId, flightaware.FaFlightIdIdent)
+	if err != nil {
+		if errs.IsFatalApiError(err) {
+			return errors.WithMessagef(err, "inbound flight %[1]s", flightData.InboundFlightId)
+		}
+
+		p.LogError(
+			"error fetching inbound flight data",
+			zap.String("inbound_flight_id", flightData.InboundFlightId),
+			zap.Error(err),
+		)
+
+		return nil
+	}
+
+	// The inbound aircraft's arrival can't change once it's at the gate.
+	inboundArrived := validTimestampActual(inbound.GateArrivalTime)
+
+	arrival, predictedDelay, ok := predictInboundDelay(inbound, flightData, inboundConf.MinTurnaround)
+	if !ok || predictedDelay <= flightData.DepartureDelay || !inboundConf.ShouldNotify(predictedDelay, notifsSent.InboundDelay) {
+		notifsSent.InboundDone = inboundArrived
+		return nil
+	}
+
+	msg := newAlertMsg(flightData.Identifiers.IATA, flightData, originInfo, destinationInfo, notifsConf.UseLocalTime)
+	msg.IsInboundLate = true
+	msg.InboundFlight = inbound.Identifiers.IATA
+	msg.InboundArrival = arrival
+	msg.PredictedDepartureDelay = predictedDelay
+	msg.SetTemplates(p.Templates().Get(messages.InboundAircraftTemplates))
+
+	sendRes := p.SendMessageTo(ctx, msg, recipients...)
+	p.logSendErrors(sendRes)
+
+	if sendRes.Ok() {
+		notifsSent.InboundDelay = predictedDelay
+		notifsSent.InboundDone = inboundArrived || inboundConf.Increment <= 0
+	}
+
+	return nil
+}
+
+// followsInbound returns true if the flight's inbound flight is fetched on each poll.
+func (p Poller) followsInbound(flightData *flightaware.FlightData, notifsSent *SentNotifications) bool {
+	return p.FlightAwareConfig().Notifications.InboundAircraft.Enabled &&
+		flightData.InboundFlightId != "" &&
+		!notifsSent.InboundDone &&
+		!validTimestampActual(flightData.GateDepartureTime)
+}
+
+// predictInboundDelay returns the inbound flight's estimated arrival time, and the departure delay
+// of the flight which it predicts: the time by which the inbound arrival plus minTurnaround
+// exceeds the flight's scheduled departure.
+// ok is false if either time is unknown, or if the inbound flight has been cancelled.
+func predictInboundDelay(
+	inbound, flightData *flightaware.FlightData,
+	minTurnaround time.Duration,
+) (arrival time.Time, predictedDelay time.Duration, ok bool) {
+
+	if inbound.Cancelled {
+		return
+	}
+
+	arrival = estimatedArrival(inbound)
+	scheduledDeparture := flightData.GateDepartureTime.Scheduled
+
+	if arrival.IsZero() || scheduledDeparture.IsZero() {
+		return
+	}
+
+	return arrival, arrival.Add(minTurnaround).Sub(scheduledDeparture), true
+}
@@ -0,0 +1,169 @@
+package flightawarepoller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+	"github.com/jalavosus/stuffnotifier/internal/testservers"
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+	"github.com/jalavosus/stuffnotifier/pkg/slack"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
+)
+
+func TestPredictInboundDelay(t *testing.T) {
+	var (
+		departure     = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		minTurnaround = 45 * time.Minute
+		flightData    = &flightaware.FlightData{
+			GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure},
+		}
+	)
+
+	tests := []struct {
+		inbound *flightaware.FlightData
+		name    string
+		want    time.Duration
+		wantOk  bool
+	}{
+		{
+			name: "on time",
+			inbound: &flightaware.FlightData{
+				GateArrivalTime: flightaware.FlightTimestamp{Estimated: departure.Add(-time.Hour)},
+			},
+			want:   -15 * time.Minute,
+			wantOk: true,
+		},
+		{
+			name: "running late",
+			inbound: &flightaware.FlightData{
+				GateArrivalTime: flightaware.FlightTimestamp{Estimated: departure.Add(-15 * time.Minute)},
+			},
+			want:   30 * time.Minute,
+			wantOk: true,
+		},
+		{
+			name: "runway arrival fallback",
+			inbound: &flightaware.FlightData{
+				RunwayArrivalTime: flightaware.FlightTimestamp{Estimated: departure},
+			},
+			want:   minTurnaround,
+			wantOk: true,
+		},
+		{
+			name: "inbound cancelled",
+			inbound: &flightaware.FlightData{
+				GateArrivalTime: flightaware.FlightTimestamp{Estimated: departure},
+				Cancelled:       true,
+			},
+		},
+		{
+			name:    "unknown arrival",
+			inbound: &flightaware.FlightData{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, ok := predictInboundDelay(tt.inbound, flightData, minTurnaround)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPoller_SendInboundAlert(t *testing.T) {
+	var (
+		aeroApi   = testservers.NewAeroAPI(t)
+		slackApi  = testservers.NewSlack(t)
+		departure = time.Now().UTC().Truncate(time.Minute).Add(2 * time.Hour)
+		airport   = flightaware.AirportData{Identifiers: flightaware.AirportIdentifiers{Code: "KEWR"}, Timezone: "UTC"}
+		ctx       = context.Background()
+	)
+
+	// The inbound aircraft arrives 15 minutes before the flight's departure,
+	// so the flight is predicted to depart 30 minutes late.
+	inbound := testservers.NewFlightTimeline(
+		testservers.ScheduledFlight("UAL100-1650000000-airline-0001", "UA100", airport, airport, departure.Add(-3*time.Hour), 165*time.Minute),
+	).
+		Then(testservers.GateIn(departure.Add(-15 * time.Minute))).
+		SetRequestsPerStage(0)
+
+	aeroApi.AddFlight(inbound)
+
+	flightData := testservers.ScheduledFlight("UAL200-1650000000-airline-0002", "UA200", airport, airport, departure, time.Hour)
+	flightData.InboundFlightId = inbound.Current().FlightId
+
+	notifsConf := flightaware.DefaultNotificationsConfig()
+
+	p, err := NewPoller(poller.Config{
+		FlightAware: &flightaware.Config{
+			Auth:          &flightaware.AuthConfig{ApiKey: "test-key"},
+			BaseUrl:       aeroApi.URL(),
+			MaxPages:      1,
+			Transport:     transport.Config{RateLimit: -1},
+			Notifications: notifsConf,
+		},
+		Slack: &slack.Config{
+			Auth:     &slack.AuthConfig{Token: "xoxb-test"},
+			Channels: []string{"C0000000001"},
+			BaseUrl:  slackApi.URL(),
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.NoError(t, p.initFlightAwareClient(p.FlightAwareConfig().Auth))
+
+	var notifsSent SentNotifications
+
+	sendInboundAlert := func() {
+		t.Helper()
+		require.NoError(t, p.sendInboundAlert(ctx, &flightData, &airport, &airport, &notifsSent, nil))
+	}
+
+	sendInboundAlert()
+	assert.Equal(t, 1, aeroApi.Requests())
+	assert.Len(t, slackApi.Messages(), 1)
+	assert.Equal(t, 30*time.Minute, notifsSent.InboundDelay)
+
+	// Further notifications are sent if the predicted delay grows,
+	// so the inbound flight is followed until it arrives at its gate.
+	sendInboundAlert()
+	assert.Equal(t, 2, aeroApi.Requests())
+	assert.False(t, notifsSent.InboundDone)
+
+	require.True(t, inbound.Advance())
+
+	sendInboundAlert()
+	assert.Equal(t, 3, aeroApi.Requests())
+	assert.True(t, notifsSent.InboundDone)
+
+	sendInboundAlert()
+	assert.Equal(t, 3, aeroApi.Requests())
+	assert.Len(t, slackApi.Messages(), 1)
+
+	// Without further notifications, an inbound flight which is still en route
+	// is no longer followed once its notification has been sent.
+	enRoute := testservers.NewFlightTimeline(
+		testservers.ScheduledFlight("UAL101-1650000000-airline-0003", "UA101", airport, airport, departure.Add(-3*time.Hour), 165*time.Minute),
+	)
+
+	aeroApi.AddFlight(enRoute)
+
+	flightData.InboundFlightId = enRoute.Current().FlightId
+	notifsSent = SentNotifications{}
+	p.flightawareConfig.Notifications.InboundAircraft.Increment = 0
+
+	sendInboundAlert()
+	assert.Equal(t, 4, aeroApi.Requests())
+	assert.Len(t, slackApi.Messages(), 2)
+	assert.True(t, notifsSent.InboundDone)
+
+	sendInboundAlert()
+	assert.Equal(t, 4, aeroApi.Requests())
+}
@@ -11,4 +11,8 @@ const (
 	PreDepartureNotification
 	PreArrivalNotification
 	BaggageClaimNotification
+	GateChangeNotification
+	DelayNotification
+	CancellationNotification
+	DiversionNotification
 )
@@ -0,0 +1,490 @@
+package flightawarepoller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+type connectionAlertType uint8
+
+const (
+	NoConnectionAlert connectionAlertType = iota
+	ConnectionAtRiskAlert
+	InboundCancelledAlert
+	InboundDivertedAlert
+)
+
+// trackedItinerary is an itinerary whose legs have been resolved
+// to their FlightAware flight IDs.
+type trackedItinerary struct {
+	name              string
+	legIds            []string
+	recipients        []string
+	minConnectionTime time.Duration
+}
+
+func (i trackedItinerary) cacheKey() string {
+	return "itinerary:" + strings.Join(i.legIds, ",")
+}
+
+// connectionState tracks the alerts sent for the connection
+// between two consecutive legs of an itinerary.
+type connectionState struct {
+	// If true, a connection at risk alert has been sent, and the
+	// connection time hasn't recovered above the minimum since.
+	AtRisk           bool
+	InboundCancelled bool
+	InboundDiverted  bool
+	// If true, nothing more can happen to the connection
+	// which is worth alerting about.
+	Resolved bool
+}
+
+func (s *connectionState) SetSent(alertType connectionAlertType) {
+	switch alertType {
+	case ConnectionAtRiskAlert:
+		s.AtRisk = true
+	case InboundCancelledAlert:
+		s.InboundCancelled = true
+	case InboundDivertedAlert:
+		s.InboundDiverted = true
+	}
+}
+
+// resolveItineraries validates the passed itineraries, then resolves each of their legs
+// to its FlightAware flight ID, checking that each leg departs from the airport
+// the leg before it arrives at.
+// The itineraries' legs, which are each also tracked as a regular flight, are returned alongside them.
+func (p *Poller) resolveItineraries(
+	ctx context.Context,
+	itineraries []flightaware.ItineraryConfig,
+) ([]trackedItinerary, []trackedFlight, error) {
+
+	for _, itinerary := range itineraries {
+		if err := itinerary.Validate(); err != nil {
+			return nil, nil, err
+		}
+
+		for _, leg := range itinerary.LegFlights() {
+			for _, recipients := range leg.Recipients {
+				if !p.HasRecipients(recipients) {
+					return nil, nil, errors.Errorf("unknown recipients %[1]s for itinerary %[2]s", recipients, itinerary.Name)
+				}
+			}
+		}
+	}
+
+	var (
+		tracked = make([]trackedItinerary, 0, len(itineraries))
+		legs    []trackedFlight
+	)
+
+	for _, itinerary := range itineraries {
+		var (
+			legConfigs = itinerary.LegFlights()
+			legIds     = make([]string, len(legConfigs))
+			prev       *flightaware.FlightData
+		)
+
+		for i, leg := range legConfigs {
+			legData, err := p.resolveLeg(ctx, leg)
+			if err != nil {
+				return nil, nil, errors.WithMessagef(err, "itinerary %[1]s", itinerary.Name)
+			}
+
+			if prev != nil && !legsConnect(prev, legData) {
+				return nil, nil, errors.Errorf(
+					"itinerary %[1]s: leg %[2]s departs from %[3]s, but leg %[4]s arrives at %[5]s",
+					itinerary.Name,
+					leg.Identifier,
+					legData.Origin.Identifiers.Code,
+					legConfigs[i-1].Identifier,
+					prev.Destination.Identifiers.Code,
+				)
+			}
+
+			legIds[i] = legData.FlightId
+			legs = append(legs, trackedFlight{apiId: legData.FlightId, recipients: leg.Recipients})
+
+			prev = legData
+		}
+
+		tracked = append(tracked, trackedItinerary{
+			name:              itinerary.Name,
+			legIds:            legIds,
+			recipients:        itinerary.Recipients,
+			minConnectionTime: itinerary.ConnectionTime(),
+		})
+	}
+
+	return tracked, legs, nil
+}
+
+// resolveLeg returns the flight data of the passed itinerary leg.
+func (p *Poller) resolveLeg(ctx context.Context, leg flightaware.FlightConfig) (*flightaware.FlightData, error) {
+	params, err := leg.InformationParams()
+	if err != nil {
+		return nil, err
+	}
+
+	legData, err := p.fetchFlightWithParams(ctx, params, zap.String("flight_identifier", leg.Identifier))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error fetching flight data for leg %[1]s", leg.Identifier)
+	}
+
+	return legData, nil
+}
+
+// legsConnect returns true if the outbound leg departs from the airport the inbound leg arrives at.
+// Diverted inbound legs are assumed to connect, as they no longer arrive at their original destination.
+func legsConnect(inbound, outbound *flightaware.FlightData) bool {
+	return inbound.Diverted || inbound.Destination.Identifiers.Code == outbound.Origin.Identifiers.Code
+}
+
+// fetchConnectionStates returns the itinerary's stored connection states,
+// so that alerts sent before a restart aren't sent again.
+// New states are returned if none are stored.
+func (p *Poller) fetchConnectionStates(ctx context.Context, itinerary trackedItinerary) []connectionState {
+	ctx, cancel := context.WithTimeout(ctx, fetchDataTimeout)
+	defer cancel()
+
+	numConnections := len(itinerary.legIds) - 1
+
+	stored, ok, err := p.connectionStore.Get(ctx, itinerary.cacheKey())
+	if err != nil {
+		p.LogError("error fetching stored connection states", zap.String("itinerary", itinerary.name), zap.Error(err))
+	}
+
+	if ok && len(*stored) == numConnections {
+		return *stored
+	}
+
+	return make([]connectionState, numConnections)
+}
+
+func (p *Poller) storeConnectionStates(ctx context.Context, itinerary trackedItinerary, states []connectionState) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	if err := p.connectionStore.Insert(ctx, itinerary.cacheKey(), states); err != nil {
+		p.LogError("error storing connection states", zap.String("itinerary", itinerary.name), zap.Error(err))
+	}
+}
+
+// pollItinerary checks the connections between each of an itinerary's legs,
+// sending alerts when a connection is at risk, until every connection has been resolved.
+func (p *Poller) pollItinerary(
+	ctx context.Context,
+	itinerary trackedItinerary,
+	pollerParams *poller.ConcurrentParams,
+) {
+
+	var (
+		states   = p.fetchConnectionStates(ctx, itinerary)
+		airports = make(map[string]*flightaware.AirportData)
+	)
+
+	ticker := p.Clock().NewTicker(p.PollInterval())
+	backoff := newFetchBackoff(p.PollInterval())
+	cleanup := func(err error) {
+		if err != nil {
+			err = errors.WithMessagef(err, "itinerary %[1]s", itinerary.name)
+		}
+
+		pollerParams.Cleanup(err, ticker)
+	}
+
+	p.LogInfo(
+		"starting itinerary poller",
+		zap.String("itinerary", itinerary.name),
+		zap.Strings("legs", itinerary.legIds),
+		zap.String("min_connection_time", itinerary.minConnectionTime.String()),
+		zap.String("check_interval", p.PollInterval().String()),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			cleanup(nil)
+			return
+		case <-ticker.C():
+			// Legs are fetched at most once per poll,
+			// as they can be part of two connections.
+			legData := make(map[string]*flightaware.FlightData, len(itinerary.legIds))
+
+			fetchLeg := func(legId string) (*flightaware.FlightData, error) {
+				if data, ok := legData[legId]; ok {
+					return data, nil
+				}
+
+				data, err := p.fetchLeg(ctx, legId)
+				if err != nil {
+					return nil, err
+				}
+
+				legData[legId] = data
+
+				return data, nil
+			}
+
+			var (
+				allResolved = true
+				fetchErr    error
+			)
+
+			for i := range states {
+				if states[i].Resolved {
+					continue
+				}
+
+				resolved, err := p.checkConnection(ctx, itinerary, i, &states[i], fetchLeg, airports)
+				if err != nil {
+					allResolved = false
+					fetchErr = err
+
+					break
+				}
+
+				if !resolved {
+					allResolved = false
+				}
+			}
+
+			if fetchErr != nil {
+				if replayEnded(fetchErr) {
+					cleanup(nil)
+					return
+				}
+
+				if errs.IsFatalApiError(fetchErr) {
+					cleanup(fetchErr)
+					return
+				}
+
+				nextPoll := backoff.Failure(fetchErr)
+				ticker.Reset(nextPoll)
+
+				p.LogError(
+					"error fetching itinerary flight data",
+					zap.String("itinerary", itinerary.name),
+					zap.String("next_poll", nextPoll.String()),
+					zap.Error(fetchErr),
+				)
+
+				continue
+			}
+
+			if interval, backedOff := backoff.Success(); backedOff {
+				ticker.Reset(interval)
+			}
+
+			p.storeConnectionStates(ctx, itinerary, states)
+
+			if allResolved {
+				cleanup(nil)
+				return
+			}
+		}
+	}
+}
+
+// fetchLeg returns the data of the itinerary leg with the passed FlightAware flight ID.
+// Each leg is also polled as a regular flight, so the data cached by its poller
+// (which polls it on its own schedule) is used, and the leg is only fetched
+// if nothing has been cached for it yet.
+func (p *Poller) fetchLeg(ctx context.Context, legId string) (*flightaware.FlightData, error) {
+	cached, _, ok, err := p.fetchCacheEntry(ctx, trackedFlight{apiId: legId}.cacheKey())
+	if err != nil {
+		p.LogError("error checking for cached leg data", zap.String("flight_id", legId), zap.Error(err))
+	}
+
+	if ok && cached.FlightData != nil {
+		return cached.FlightData, nil
+	}
+
+	return p.fetchFlight(ctx, legId, flightaware.FaFlightIdIdent)
+}
+
+// checkConnection checks the connection between the itinerary's leg at index `legIdx` and the leg after it,
+// sending an alert if needed. It returns true if the connection has been resolved,
+// or an error if either leg's data couldn't be fetched.
+func (p *Poller) checkConnection(
+	ctx context.Context,
+	itinerary trackedItinerary,
+	legIdx int,
+	state *connectionState,
+	fetchLeg func(string) (*flightaware.FlightData, error),
+	airports map[string]*flightaware.AirportData,
+) (bool, error) {
+
+	inbound, err := fetchLeg(itinerary.legIds[legIdx])
+	if err != nil {
+		return false, errors.WithMessage(err, "error fetching inbound flight data")
+	}
+
+	outbound, err := fetchLeg(itinerary.legIds[legIdx+1])
+	if err != nil {
+		return false, errors.WithMessage(err, "error fetching outbound flight data")
+	}
+
+	alertType := evaluateConnection(inbound, outbound, itinerary.minConnectionTime, state)
+	if alertType != NoConnectionAlert {
+		airportId := outbound.Origin.Identifiers.ICAO
+
+		airport, ok := airports[airportId]
+		if !ok {
+			airport, err = p.fetchAirport(ctx, airportId)
+			if err != nil {
+				p.LogError("error fetching connecting airport data", zap.String("airport", airportId), zap.Error(err))
+				return false, nil
+			}
+
+			airports[airportId] = airport
+		}
+
+		msg := newConnectionAlertMsg(alertType, itinerary, inbound, outbound, airport, p.FlightAwareConfig().Notifications.UseLocalTime)
+		msg.SetTemplates(p.Templates().Get(messages.ConnectionTemplates))
+
+		sendRes := p.SendMessageTo(ctx, msg, itinerary.recipients...)
+		p.logSendErrors(sendRes)
+
+		if sendRes.Ok() {
+			state.SetSent(alertType)
+		}
+	}
+
+	state.Resolved = connectionResolved(inbound, outbound, state)
+
+	return state.Resolved, nil
+}
+
+// evaluateConnection returns the type of alert which should be sent for the connection
+// between the passed inbound and outbound legs, if any.
+// If the connection time has recovered to at least minConnectionTime since an at risk alert was sent,
+// the state is reset so that a further alert is sent if it drops below it again.
+func evaluateConnection(
+	inbound, outbound *flightaware.FlightData,
+	minConnectionTime time.Duration,
+	state *connectionState,
+) connectionAlertType {
+
+	switch {
+	case validTimestampActual(outbound.GateDepartureTime), outbound.Cancelled:
+		// Cancellation of the outbound leg is alerted by its own poller.
+		return NoConnectionAlert
+	case inbound.Cancelled:
+		if !state.InboundCancelled {
+			return InboundCancelledAlert
+		}
+
+		return NoConnectionAlert
+	case inbound.Diverted:
+		if !state.InboundDiverted {
+			return InboundDivertedAlert
+		}
+
+		return NoConnectionAlert
+	}
+
+	_, _, connTime, ok := connectionTime(inbound, outbound)
+	if !ok {
+		return NoConnectionAlert
+	}
+
+	if connTime >= minConnectionTime {
+		state.AtRisk = false
+		return NoConnectionAlert
+	}
+
+	if !state.AtRisk {
+		return ConnectionAtRiskAlert
+	}
+
+	return NoConnectionAlert
+}
+
+// connectionResolved returns true if the outbound leg has departed or been cancelled,
+// or if the inbound leg has been cancelled or diverted and an alert has been sent for it.
+func connectionResolved(inbound, outbound *flightaware.FlightData, state *connectionState) bool {
+	return validTimestampActual(outbound.GateDepartureTime) ||
+		outbound.Cancelled ||
+		(inbound.Cancelled && state.InboundCancelled) ||
+		(inbound.Diverted && !inbound.Cancelled && state.InboundDiverted)
+}
+
+// connectionTime returns the inbound leg's estimated arrival time, the outbound leg's
+// estimated departure time, and the time between them.
+// ok is false if either time is unknown.
+func connectionTime(inbound, outbound *flightaware.FlightData) (arrival, departure time.Time, connTime time.Duration, ok bool) {
+	arrival = estimatedArrival(inbound)
+	departure = bestTime(outbound.GateDepartureTime)
+
+	if arrival.IsZero() || departure.IsZero() {
+		return
+	}
+
+	return arrival, departure, departure.Sub(arrival), true
+}
+
+// estimatedArrival returns the flight's best known gate arrival time,
+// falling back to its runway arrival time.
+func estimatedArrival(flightData *flightaware.FlightData) time.Time {
+	if arrival := bestTime(flightData.GateArrivalTime); !arrival.IsZero() {
+		return arrival
+	}
+
+	return bestTime(flightData.RunwayArrivalTime)
+}
+
+// bestTime returns the timestamp's actual time if set, falling back
+// to its estimated time and then its scheduled time.
+func bestTime(ts flightaware.FlightTimestamp) time.Time {
+	switch {
+	case !ts.Actual.IsZero():
+		return ts.Actual
+	case !ts.Estimated.IsZero():
+		return ts.Estimated
+	default:
+		return ts.Scheduled
+	}
+}
+
+func newConnectionAlertMsg(
+	alertType connectionAlertType,
+	itinerary trackedItinerary,
+	inbound, outbound *flightaware.FlightData,
+	airport *flightaware.AirportData,
+	useLocalTime bool,
+) *messages.ConnectionAlert {
+
+	arrival, departure, connTime, _ := connectionTime(inbound, outbound)
+
+	return &messages.ConnectionAlert{
+		InboundArrival:    arrival,
+		OutboundDeparture: departure,
+		Airport: messages.FlightAwareAirportInfo{
+			Airport:  airport.Name,
+			Timezone: airport.Timezone,
+			Gate:     outbound.Origin.Gate,
+			Terminal: outbound.Origin.Terminal,
+		},
+		ItineraryName:      itinerary.name,
+		InboundFlight:      inbound.Identifiers.IATA,
+		OutboundFlight:     outbound.Identifiers.IATA,
+		ConnectionTime:     connTime,
+		MinConnectionTime:  itinerary.minConnectionTime,
+		IsAtRisk:           alertType == ConnectionAtRiskAlert,
+		IsInboundCancelled: alertType == InboundCancelledAlert,
+		IsInboundDiverted:  alertType == InboundDivertedAlert,
+		UseLocalTimezone:   useLocalTime,
+	}
+}
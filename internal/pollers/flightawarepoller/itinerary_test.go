@@ -0,0 +1,234 @@
+package flightawarepoller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestConnectionTime(t *testing.T) {
+	var (
+		arrival   = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		departure = arrival.Add(time.Hour)
+	)
+
+	tests := []struct {
+		inbound  *flightaware.FlightData
+		outbound *flightaware.FlightData
+		name     string
+		want     time.Duration
+		wantOk   bool
+	}{
+		{
+			name: "estimated gate times",
+			inbound: &flightaware.FlightData{
+				GateArrivalTime: flightaware.FlightTimestamp{Scheduled: arrival, Estimated: arrival.Add(20 * time.Minute)},
+			},
+			outbound: &flightaware.FlightData{
+				GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure, Estimated: departure.Add(5 * time.Minute)},
+			},
+			want:   45 * time.Minute,
+			wantOk: true,
+		},
+		{
+			name: "actual gate arrival",
+			inbound: &flightaware.FlightData{
+				GateArrivalTime: flightaware.FlightTimestamp{Estimated: arrival, Actual: arrival.Add(30 * time.Minute)},
+			},
+			outbound: &flightaware.FlightData{
+				GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure},
+			},
+			want:   30 * time.Minute,
+			wantOk: true,
+		},
+		{
+			name: "runway arrival fallback",
+			inbound: &flightaware.FlightData{
+				RunwayArrivalTime: flightaware.FlightTimestamp{Estimated: arrival},
+			},
+			outbound: &flightaware.FlightData{
+				GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure},
+			},
+			want:   time.Hour,
+			wantOk: true,
+		},
+		{
+			name: "missed connection",
+			inbound: &flightaware.FlightData{
+				GateArrivalTime: flightaware.FlightTimestamp{Estimated: departure.Add(10 * time.Minute)},
+			},
+			outbound: &flightaware.FlightData{
+				GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure},
+			},
+			want:   -10 * time.Minute,
+			wantOk: true,
+		},
+		{
+			name:    "unknown arrival",
+			inbound: &flightaware.FlightData{},
+			outbound: &flightaware.FlightData{
+				GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, got, ok := connectionTime(tt.inbound, tt.outbound)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvaluateConnection(t *testing.T) {
+	var (
+		arrival        = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		minConnTime    = 45 * time.Minute
+		outboundDepart = flightaware.FlightTimestamp{Scheduled: arrival.Add(time.Hour)}
+	)
+
+	newLegs := func(arrivalDelay time.Duration, modify func(inbound, outbound *flightaware.FlightData)) (*flightaware.FlightData, *flightaware.FlightData) {
+		inbound := &flightaware.FlightData{
+			GateArrivalTime: flightaware.FlightTimestamp{Scheduled: arrival, Estimated: arrival.Add(arrivalDelay)},
+		}
+		outbound := &flightaware.FlightData{GateDepartureTime: outboundDepart}
+
+		if modify != nil {
+			modify(inbound, outbound)
+		}
+
+		return inbound, outbound
+	}
+
+	tests := []struct {
+		modify       func(inbound, outbound *flightaware.FlightData)
+		name         string
+		state        connectionState
+		arrivalDelay time.Duration
+		want         connectionAlertType
+		wantState    connectionState
+		wantResolved bool
+	}{
+		{
+			name: "on time",
+			want: NoConnectionAlert,
+		},
+		{
+			name:         "at risk",
+			arrivalDelay: 30 * time.Minute,
+			want:         ConnectionAtRiskAlert,
+		},
+		{
+			name:         "at risk already sent",
+			arrivalDelay: 30 * time.Minute,
+			state:        connectionState{AtRisk: true},
+			want:         NoConnectionAlert,
+			wantState:    connectionState{AtRisk: true},
+		},
+		{
+			name:         "recovered",
+			arrivalDelay: 10 * time.Minute,
+			state:        connectionState{AtRisk: true},
+			want:         NoConnectionAlert,
+		},
+		{
+			name: "inbound cancelled",
+			modify: func(inbound, _ *flightaware.FlightData) {
+				inbound.Cancelled = true
+			},
+			want: InboundCancelledAlert,
+		},
+		{
+			name: "inbound cancelled already sent",
+			modify: func(inbound, _ *flightaware.FlightData) {
+				inbound.Cancelled = true
+			},
+			state:        connectionState{InboundCancelled: true},
+			want:         NoConnectionAlert,
+			wantState:    connectionState{InboundCancelled: true},
+			wantResolved: true,
+		},
+		{
+			name:         "inbound diverted",
+			arrivalDelay: 30 * time.Minute,
+			modify: func(inbound, _ *flightaware.FlightData) {
+				inbound.Diverted = true
+			},
+			want: InboundDivertedAlert,
+		},
+		{
+			name:         "outbound departed",
+			arrivalDelay: 30 * time.Minute,
+			modify: func(_, outbound *flightaware.FlightData) {
+				outbound.GateDepartureTime.Actual = outbound.GateDepartureTime.Scheduled
+			},
+			want:         NoConnectionAlert,
+			wantResolved: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			inbound, outbound := newLegs(tt.arrivalDelay, tt.modify)
+			state := tt.state
+
+			got := evaluateConnection(inbound, outbound, minConnTime, &state)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantState, state)
+			assert.Equal(t, tt.wantResolved, connectionResolved(inbound, outbound, &state))
+		})
+	}
+}
+
+func TestLegsConnect(t *testing.T) {
+	newLeg := func(origin, destination string) *flightaware.FlightData {
+		return &flightaware.FlightData{
+			Origin:      flightaware.FlightOriginDestinationData{Identifiers: flightaware.AirportIdentifiers{Code: origin}},
+			Destination: flightaware.FlightOriginDestinationData{Identifiers: flightaware.AirportIdentifiers{Code: destination}},
+		}
+	}
+
+	diverted := newLeg("KJFK", "KBOS")
+	diverted.Diverted = true
+
+	tests := []struct {
+		inbound  *flightaware.FlightData
+		outbound *flightaware.FlightData
+		name     string
+		want     bool
+	}{
+		{
+			name:     "connecting",
+			inbound:  newLeg("KJFK", "KORD"),
+			outbound: newLeg("KORD", "KSFO"),
+			want:     true,
+		},
+		{
+			name:     "not connecting",
+			inbound:  newLeg("KJFK", "KORD"),
+			outbound: newLeg("KMDW", "KSFO"),
+		},
+		{
+			name:     "inbound diverted",
+			inbound:  diverted,
+			outbound: newLeg("KORD", "KSFO"),
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, legsConnect(tt.inbound, tt.outbound))
+		})
+	}
+}
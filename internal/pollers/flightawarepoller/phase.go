@@ -0,0 +1,224 @@
+package flightawarepoller
+
+import (
+	"time"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+// FlightPhase is a phase of a flight's lifecycle.
+type FlightPhase string
+
+const (
+	PhaseScheduled FlightPhase = "scheduled"
+	// A flight enters its boarding window when it enters its poll schedule's departure window,
+	// before its scheduled or estimated gate departure (whichever is earlier).
+	PhaseBoardingWindow FlightPhase = "boarding_window"
+	PhaseDepartedGate   FlightPhase = "departed_gate"
+	PhaseAirborne       FlightPhase = "airborne"
+	PhaseDiverted       FlightPhase = "diverted"
+	PhaseLanded         FlightPhase = "landed"
+	PhaseAtGate         FlightPhase = "at_gate"
+	PhaseCancelled      FlightPhase = "cancelled"
+)
+
+// lifecyclePhases are the phases of a flight which isn't cancelled,
+// in the order they're reached. A flight can skip phases,
+// ex. if FlightAware has no gate departure time for a flight which has taken off.
+var lifecyclePhases = []FlightPhase{
+	PhaseScheduled,
+	PhaseBoardingWindow,
+	PhaseDepartedGate,
+	PhaseAirborne,
+	PhaseDiverted,
+	PhaseLanded,
+	PhaseAtGate,
+}
+
+// order returns the position of the phase in a flight's lifecycle.
+// Cancellation can happen at any point, and comes after every other phase.
+func (p FlightPhase) order() int {
+	for i, phase := range lifecyclePhases {
+		if phase == p {
+			return i
+		}
+	}
+
+	if p == PhaseCancelled {
+		return len(lifecyclePhases)
+	}
+
+	return 0
+}
+
+// notification returns the type of notification sent when a flight enters the phase,
+// or NoNotification if none is.
+func (p FlightPhase) notification() notificationType {
+	switch p {
+	case PhaseDepartedGate:
+		return GateDepartureNotification
+	case PhaseAirborne:
+		return TakeoffNotification
+	case PhaseDiverted:
+		return DiversionNotification
+	case PhaseLanded:
+		return LandingNotification
+	case PhaseAtGate:
+		return GateArrivalNotification
+	case PhaseCancelled:
+		return CancellationNotification
+	default:
+		return NoNotification
+	}
+}
+
+// PhaseTransition is a flight's transition into a phase.
+type PhaseTransition struct {
+	// Time the flight entered the phase, ex. its actual takeoff time.
+	// Cancellations and diversions happen when they're observed.
+	At time.Time
+	// Time the transition was observed by the poller.
+	ObservedAt time.Time
+	Phase      FlightPhase
+}
+
+// FlightLifecycle tracks a flight through its phases.
+type FlightLifecycle struct {
+	Phase FlightPhase
+	// Every transition made by the flight, in order.
+	Transitions []PhaseTransition
+}
+
+// Current returns the flight's current phase.
+func (l FlightLifecycle) Current() FlightPhase {
+	if l.Phase == "" {
+		return PhaseScheduled
+	}
+
+	return l.Phase
+}
+
+// Reached returns true if the flight has transitioned into the passed phase.
+func (l FlightLifecycle) Reached(phase FlightPhase) bool {
+	for _, transition := range l.Transitions {
+		if transition.Phase == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Advance moves the lifecycle to the phase of the passed flight data at `now`
+// (using the passed poll schedule's departure window as the flight's boarding window),
+// recording and returning a transition for every phase the flight has entered since
+// the lifecycle was last advanced, in order.
+// Phases which the flight data has no record of are skipped,
+// and the lifecycle never moves back to an earlier phase.
+func (l *FlightLifecycle) Advance(
+	flightData *flightaware.FlightData,
+	schedule flightaware.PollScheduleConfig,
+	now time.Time,
+) []PhaseTransition {
+
+	var (
+		current     = l.Current()
+		target      = currentPhase(flightData, schedule, now)
+		transitions []PhaseTransition
+	)
+
+	switch {
+	case current == PhaseCancelled:
+		return nil
+	case target == PhaseCancelled:
+		transitions = append(transitions, PhaseTransition{Phase: PhaseCancelled, At: now, ObservedAt: now})
+	case target.order() > current.order():
+		for _, phase := range lifecyclePhases[current.order()+1 : target.order()+1] {
+			if at, ok := phaseEnteredAt(phase, flightData, schedule, now); ok {
+				transitions = append(transitions, PhaseTransition{Phase: phase, At: at, ObservedAt: now})
+			}
+		}
+	case flightData.Diverted && !l.Reached(PhaseDiverted):
+		// A diversion can be reported after the flight has landed,
+		// in which case it's recorded without moving the flight back.
+		transitions = append(transitions, PhaseTransition{Phase: PhaseDiverted, At: now, ObservedAt: now})
+		target = current
+	default:
+		return nil
+	}
+
+	l.Phase = target
+	l.Transitions = append(l.Transitions, transitions...)
+
+	return transitions
+}
+
+// currentPhase returns the phase of the passed flight data at `now`.
+func currentPhase(flightData *flightaware.FlightData, schedule flightaware.PollScheduleConfig, now time.Time) FlightPhase {
+	if flightData.Cancelled {
+		return PhaseCancelled
+	}
+
+	for i := len(lifecyclePhases) - 1; i > 0; i-- {
+		if _, ok := phaseEnteredAt(lifecyclePhases[i], flightData, schedule, now); ok {
+			return lifecyclePhases[i]
+		}
+	}
+
+	return PhaseScheduled
+}
+
+// phaseEnteredAt returns the time at which the passed flight data shows the flight
+// entering the passed phase, and false if it hasn't entered it by `now`.
+func phaseEnteredAt(
+	phase FlightPhase,
+	flightData *flightaware.FlightData,
+	schedule flightaware.PollScheduleConfig,
+	now time.Time,
+) (at time.Time, ok bool) {
+
+	switch phase {
+	case PhaseBoardingWindow:
+		if at, ok = schedule.DepartureWindowStart(flightData); !ok {
+			return
+		}
+
+		return at, !at.After(now)
+	case PhaseDepartedGate:
+		return flightData.GateDepartureTime.Actual, validTimestampActual(flightData.GateDepartureTime)
+	case PhaseAirborne:
+		return flightData.RunwayDepartureTime.Actual, validTimestampActual(flightData.RunwayDepartureTime)
+	case PhaseDiverted:
+		return now, flightData.Diverted
+	case PhaseLanded:
+		return flightData.RunwayArrivalTime.Actual, validTimestampActual(flightData.RunwayArrivalTime)
+	case PhaseAtGate:
+		return flightData.GateArrivalTime.Actual, validTimestampActual(flightData.GateArrivalTime)
+	case PhaseCancelled:
+		return now, flightData.Cancelled
+	default:
+		return
+	}
+}
+
+// pendingTransitions returns the lifecycle's transitions
+// whose notifications are enabled and haven't been sent.
+// Once a flight is cancelled, only its cancellation is pending.
+func pendingTransitions(lifecycle FlightLifecycle, notifsSent *SentNotifications) []PhaseTransition {
+	var (
+		pending   []PhaseTransition
+		cancelled = lifecycle.Current() == PhaseCancelled
+	)
+
+	for _, transition := range lifecycle.Transitions {
+		if cancelled && transition.Phase != PhaseCancelled {
+			continue
+		}
+
+		if notifType := transition.Phase.notification(); notifType != NoNotification && !notifsSent.IsSent(notifType) {
+			pending = append(pending, transition)
+		}
+	}
+
+	return pending
+}
@@ -0,0 +1,297 @@
+package flightawarepoller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestFlightLifecycle_Advance(t *testing.T) {
+	var (
+		departure    = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		boardingAt   = departure.Add(-flightaware.DefaultDepartureWindow)
+		takeoff      = departure.Add(15 * time.Minute)
+		landing      = departure.Add(5 * time.Hour)
+		gateArrival  = landing.Add(10 * time.Minute)
+		beforeBoard  = boardingAt.Add(-time.Hour)
+		afterArrival = gateArrival.Add(time.Minute)
+	)
+
+	newFlightData := func(modify func(d *flightaware.FlightData)) *flightaware.FlightData {
+		d := &flightaware.FlightData{
+			GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure},
+		}
+
+		if modify != nil {
+			modify(d)
+		}
+
+		return d
+	}
+
+	departed := func(d *flightaware.FlightData) { d.GateDepartureTime.Actual = departure }
+	tookOff := func(d *flightaware.FlightData) {
+		departed(d)
+		d.RunwayDepartureTime.Actual = takeoff
+	}
+	landed := func(d *flightaware.FlightData) {
+		tookOff(d)
+		d.RunwayArrivalTime.Actual = landing
+	}
+	arrived := func(d *flightaware.FlightData) {
+		landed(d)
+		d.GateArrivalTime.Actual = gateArrival
+	}
+
+	tests := []struct {
+		flightData *flightaware.FlightData
+		now        time.Time
+		name       string
+		from       FlightPhase
+		wantPhase  FlightPhase
+		want       []PhaseTransition
+	}{
+		{
+			name:       "scheduled",
+			flightData: newFlightData(nil),
+			now:        beforeBoard,
+			wantPhase:  PhaseScheduled,
+		},
+		{
+			name:       "scheduled to boarding window",
+			flightData: newFlightData(nil),
+			now:        boardingAt,
+			wantPhase:  PhaseBoardingWindow,
+			want:       []PhaseTransition{{Phase: PhaseBoardingWindow, At: boardingAt}},
+		},
+		{
+			name:       "boarding window follows earlier estimated departure",
+			flightData: newFlightData(func(d *flightaware.FlightData) { d.GateDepartureTime.Estimated = departure.Add(-30 * time.Minute) }),
+			now:        boardingAt.Add(-30 * time.Minute),
+			wantPhase:  PhaseBoardingWindow,
+			want:       []PhaseTransition{{Phase: PhaseBoardingWindow, At: boardingAt.Add(-30 * time.Minute)}},
+		},
+		{
+			name:       "boarding window ignores later estimated departure",
+			flightData: newFlightData(func(d *flightaware.FlightData) { d.GateDepartureTime.Estimated = departure.Add(time.Hour) }),
+			now:        boardingAt,
+			wantPhase:  PhaseBoardingWindow,
+			want:       []PhaseTransition{{Phase: PhaseBoardingWindow, At: boardingAt}},
+		},
+		{
+			name:       "boarding window to departed gate",
+			flightData: newFlightData(departed),
+			from:       PhaseBoardingWindow,
+			now:        departure,
+			wantPhase:  PhaseDepartedGate,
+			want:       []PhaseTransition{{Phase: PhaseDepartedGate, At: departure}},
+		},
+		{
+			name:       "departed gate to airborne",
+			flightData: newFlightData(tookOff),
+			from:       PhaseDepartedGate,
+			now:        takeoff,
+			wantPhase:  PhaseAirborne,
+			want:       []PhaseTransition{{Phase: PhaseAirborne, At: takeoff}},
+		},
+		{
+			name: "airborne to diverted",
+			flightData: newFlightData(func(d *flightaware.FlightData) {
+				tookOff(d)
+				d.Diverted = true
+			}),
+			from:      PhaseAirborne,
+			now:       takeoff.Add(time.Hour),
+			wantPhase: PhaseDiverted,
+			want:      []PhaseTransition{{Phase: PhaseDiverted, At: takeoff.Add(time.Hour)}},
+		},
+		{
+			name: "diverted to landed",
+			flightData: newFlightData(func(d *flightaware.FlightData) {
+				landed(d)
+				d.Diverted = true
+			}),
+			from:      PhaseDiverted,
+			now:       landing,
+			wantPhase: PhaseLanded,
+			want:      []PhaseTransition{{Phase: PhaseLanded, At: landing}},
+		},
+		{
+			name:       "airborne to landed",
+			flightData: newFlightData(landed),
+			from:       PhaseAirborne,
+			now:        landing,
+			wantPhase:  PhaseLanded,
+			want:       []PhaseTransition{{Phase: PhaseLanded, At: landing}},
+		},
+		{
+			name:       "landed to at gate",
+			flightData: newFlightData(arrived),
+			from:       PhaseLanded,
+			now:        gateArrival,
+			wantPhase:  PhaseAtGate,
+			want:       []PhaseTransition{{Phase: PhaseAtGate, At: gateArrival}},
+		},
+		{
+			name: "diverted after landing",
+			flightData: newFlightData(func(d *flightaware.FlightData) {
+				landed(d)
+				d.Diverted = true
+			}),
+			from:      PhaseLanded,
+			now:       landing.Add(time.Minute),
+			wantPhase: PhaseLanded,
+			want:      []PhaseTransition{{Phase: PhaseDiverted, At: landing.Add(time.Minute)}},
+		},
+		{
+			name:       "gate departure and takeoff between polls",
+			flightData: newFlightData(tookOff),
+			from:       PhaseBoardingWindow,
+			now:        takeoff,
+			wantPhase:  PhaseAirborne,
+			want: []PhaseTransition{
+				{Phase: PhaseDepartedGate, At: departure},
+				{Phase: PhaseAirborne, At: takeoff},
+			},
+		},
+		{
+			name:       "every phase between polls",
+			flightData: newFlightData(arrived),
+			now:        afterArrival,
+			wantPhase:  PhaseAtGate,
+			want: []PhaseTransition{
+				{Phase: PhaseBoardingWindow, At: boardingAt},
+				{Phase: PhaseDepartedGate, At: departure},
+				{Phase: PhaseAirborne, At: takeoff},
+				{Phase: PhaseLanded, At: landing},
+				{Phase: PhaseAtGate, At: gateArrival},
+			},
+		},
+		{
+			name:       "no gate departure time",
+			flightData: newFlightData(func(d *flightaware.FlightData) { d.RunwayDepartureTime.Actual = takeoff }),
+			from:       PhaseBoardingWindow,
+			now:        takeoff,
+			wantPhase:  PhaseAirborne,
+			want:       []PhaseTransition{{Phase: PhaseAirborne, At: takeoff}},
+		},
+		{
+			name:       "no going back",
+			flightData: newFlightData(departed),
+			from:       PhaseAirborne,
+			now:        takeoff,
+			wantPhase:  PhaseAirborne,
+		},
+		{
+			name:       "scheduled to cancelled",
+			flightData: newFlightData(func(d *flightaware.FlightData) { d.Cancelled = true }),
+			now:        beforeBoard,
+			wantPhase:  PhaseCancelled,
+			want:       []PhaseTransition{{Phase: PhaseCancelled, At: beforeBoard}},
+		},
+		{
+			name: "departed gate to cancelled",
+			flightData: newFlightData(func(d *flightaware.FlightData) {
+				departed(d)
+				d.Cancelled = true
+			}),
+			from:      PhaseDepartedGate,
+			now:       departure.Add(time.Hour),
+			wantPhase: PhaseCancelled,
+			want:      []PhaseTransition{{Phase: PhaseCancelled, At: departure.Add(time.Hour)}},
+		},
+		{
+			name:       "cancelled is final",
+			flightData: newFlightData(arrived),
+			from:       PhaseCancelled,
+			now:        afterArrival,
+			wantPhase:  PhaseCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			lifecycle := FlightLifecycle{Phase: tt.from}
+
+			for i := range tt.want {
+				tt.want[i].ObservedAt = tt.now
+			}
+
+			got := lifecycle.Advance(tt.flightData, flightaware.DefaultPollScheduleConfig(), tt.now)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPhase, lifecycle.Current())
+			assert.Equal(t, tt.want, lifecycle.Transitions)
+		})
+	}
+}
+
+func TestSentNotifications_SetPhase(t *testing.T) {
+	var (
+		takeoff    = time.Date(2022, 5, 31, 19, 15, 0, 0, time.UTC)
+		notifsSent = new(SentNotifications)
+		lifecycle  FlightLifecycle
+	)
+
+	// FlightAware has no record of the flight's gate departure.
+	lifecycle.Advance(&flightaware.FlightData{
+		RunwayDepartureTime: flightaware.FlightTimestamp{Actual: takeoff},
+	}, flightaware.DefaultPollScheduleConfig(), takeoff)
+
+	notifsSent.SetPhase(lifecycle)
+
+	assert.True(t, notifsSent.GateDeparture, "skipped phases shouldn't be notified")
+	assert.True(t, notifsSent.PreDeparture)
+	assert.False(t, notifsSent.Takeoff)
+	assert.False(t, notifsSent.PreArrival)
+	assert.False(t, notifsSent.Diversion)
+
+	pending := pendingTransitions(lifecycle, notifsSent)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, PhaseAirborne, pending[0].Phase)
+	}
+
+	notifsSent.SetSent(TakeoffNotification)
+	assert.Empty(t, pendingTransitions(lifecycle, notifsSent))
+
+	lifecycle.Advance(&flightaware.FlightData{
+		RunwayDepartureTime: flightaware.FlightTimestamp{Actual: takeoff},
+		Cancelled:           true,
+	}, flightaware.DefaultPollScheduleConfig(), takeoff.Add(time.Hour))
+
+	notifsSent.SetPhase(lifecycle)
+
+	pending = pendingTransitions(lifecycle, notifsSent)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, PhaseCancelled, pending[0].Phase)
+	}
+}
+
+func TestCurrentPhase_BoardingWindow(t *testing.T) {
+	var (
+		departure  = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		flightData = &flightaware.FlightData{GateDepartureTime: flightaware.FlightTimestamp{Scheduled: departure}}
+		now        = departure.Add(-90 * time.Minute)
+	)
+
+	assert.Equal(t, PhaseScheduled, currentPhase(flightData, flightaware.DefaultPollScheduleConfig(), now))
+	assert.Equal(t, PhaseBoardingWindow, currentPhase(flightData, flightaware.PollScheduleConfig{DepartureWindow: 2 * time.Hour}, now))
+
+	// The boarding window opens at the same time as the schedule's departure window,
+	// before the earlier of the scheduled and estimated departures.
+	delayed := &flightaware.FlightData{GateDepartureTime: flightaware.FlightTimestamp{
+		Scheduled: departure,
+		Estimated: departure.Add(time.Hour),
+	}}
+	schedule := flightaware.DefaultPollScheduleConfig()
+	now = departure.Add(-flightaware.DefaultDepartureWindow)
+
+	assert.Equal(t, PhaseBoardingWindow, currentPhase(delayed, schedule, now))
+	assert.Equal(t, flightaware.PollPhaseDeparture, schedule.Phase(delayed, now))
+	assert.Equal(t, PhaseScheduled, currentPhase(delayed, schedule, now.Add(-time.Minute)))
+	assert.Equal(t, flightaware.PollPhaseScheduled, schedule.Phase(delayed, now.Add(-time.Minute)))
+}
@@ -2,18 +2,30 @@ package flightawarepoller
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"github.com/jalavosus/stuffnotifier/internal/datastore"
 	"github.com/jalavosus/stuffnotifier/internal/messages"
 	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/authdata"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
 	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
 )
 
-func (p *Poller) Start(ctx context.Context, flightId string, flightIdType flightaware.IdentifierType) error {
+// Start polls the flights and itineraries configured in the poller's FlightAware config,
+// as well as any passed flights, concurrently and using a single FlightAware client.
+// Flights configured by registration are watched until ctx is cancelled.
+// If the poller is configured to resume, flights which were being polled when
+// a previous run stopped are also polled, using the configuration they were stored with;
+// resumed flights filed for a watched registration are handed over to its watcher.
+// Start blocks until every flight has finished polling, and returns the errors
+// of any flights which stopped due to an error.
+func (p *Poller) Start(ctx context.Context, flights ...flightaware.FlightConfig) error {
 	var authData authdata.AuthData
 
 	if conf := p.FlightAwareConfig(); conf.Auth != nil {
@@ -27,29 +39,205 @@ func (p *Poller) Start(ctx context.Context, flightId string, flightIdType flight
 		authData = ad
 	}
 
-	p.initFlightAwareClient(authData)
+	if err := p.initFlightAwareClient(authData); err != nil {
+		return err
+	}
+
+	defer p.logTotalUsage()
+
+	var (
+		faConf     = p.FlightAwareConfig()
+		allFlights = utils.AppendSlices(faConf.Flights, flights)
+	)
+
+	var singleFlights, registrationFlights []flightaware.FlightConfig
+
+	for _, flight := range allFlights {
+		if flight.IsRegistration() {
+			registrationFlights = append(registrationFlights, flight)
+		} else {
+			singleFlights = append(singleFlights, flight)
+		}
+	}
+
+	if len(singleFlights) == 0 && len(registrationFlights) == 0 && len(faConf.Itineraries) == 0 && !p.Resume() {
+		return errors.New("no flights to track")
+	}
 
-	_, apiId, err := p.fetchFlightIdentifiers(ctx, flightId, flightIdType)
+	itineraries, legs, err := p.resolveItineraries(ctx, faConf.Itineraries)
 	if err != nil {
 		return err
 	}
 
-	cacheKey := "flightdata:" + apiId
-	concurrentParams := poller.NewConcurrentParams(authData, cacheKey)
+	// Each leg of an itinerary is also tracked as a regular flight.
+	tracked, err := p.resolveFlights(ctx, singleFlights, legs...)
+	if err != nil {
+		return err
+	}
+
+	registrations, err := p.resolveRegistrations(registrationFlights)
+	if err != nil {
+		return err
+	}
+
+	var resumed []resumedFlight
+
+	if p.Resume() {
+		resumed, err = p.resumeFlights(ctx, tracked)
+		if err != nil {
+			return errors.WithMessage(err, "error resuming flights")
+		}
+
+		claimResumedFlights(registrations, resumed)
+	}
+
+	numPollers := len(tracked) + len(itineraries) + len(registrations) + len(resumed)
+	if numPollers == 0 {
+		return errors.New("no flights to track")
+	}
+
+	errCh := make(chan error, numPollers)
+
+	for _, flight := range tracked {
+		go p.pollFlightData(
+			ctx,
+			flight,
+			poller.NewConcurrentParamsWithChannel(authData, flight.cacheKey(), errCh),
+		)
+	}
+
+	for _, flight := range resumed {
+		go p.withStoredConfig(flight.entry).pollFlightData(
+			ctx,
+			flight.trackedFlight(),
+			poller.NewConcurrentParamsWithChannel(authData, flight.cacheKey, errCh),
+		)
+	}
+
+	for _, itinerary := range itineraries {
+		go p.pollItinerary(
+			ctx,
+			itinerary,
+			poller.NewConcurrentParamsWithChannel(authData, itinerary.cacheKey(), errCh),
+		)
+	}
+
+	for _, registration := range registrations {
+		go p.watchRegistration(
+			ctx,
+			registration,
+			poller.NewConcurrentParamsWithChannel(authData, registration.cacheKey(), errCh),
+		)
+	}
+
+	var errMsgs []string
+
+	for i := 0; i < numPollers; i++ {
+		if pollErr := <-errCh; pollErr != nil {
+			errMsgs = append(errMsgs, pollErr.Error())
+		}
+	}
+
+	if len(errMsgs) > 0 {
+		return errors.New(strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// logTotalUsage logs the number and cost of every AeroAPI request sent by the poller.
+func (p *Poller) logTotalUsage() {
+	stats := p.FlightAwareClient().Usage()
+
+	p.LogInfo(
+		"total aeroapi usage",
+		zap.Int("requests", stats.Requests),
+		zap.Int("retries", stats.Retries),
+		zap.Float64("cost", stats.Cost),
+	)
+}
+
+// trackedFlight is a flight which has been resolved to its FlightAware flight ID.
+type trackedFlight struct {
+	apiId      string
+	recipients []string
+	// Registration of the watched aircraft the flight was filed for, if any.
+	registration string
+}
+
+func (f trackedFlight) cacheKey() string {
+	return flightCacheKeyPrefix + f.apiId
+}
+
+// resolveFlights validates the passed flights and resolves each to its FlightAware flight ID.
+// Already-resolved flights (ex. itinerary legs) are appended to the result.
+// Flights which resolve to an already-resolved flight are skipped.
+func (p *Poller) resolveFlights(
+	ctx context.Context,
+	flights []flightaware.FlightConfig,
+	resolved ...trackedFlight,
+) ([]trackedFlight, error) {
 
-	go p.pollFlightData(
-		ctx,
-		apiId,
-		concurrentParams,
+	var (
+		tracked = make([]trackedFlight, 0, len(flights)+len(resolved))
+		seen    = make(map[string]bool, len(flights)+len(resolved))
 	)
 
-	return <-concurrentParams.ErrCh
+	for _, flight := range flights {
+		for _, recipients := range flight.Recipients {
+			if !p.HasRecipients(recipients) {
+				return nil, errors.Errorf("unknown recipients %[1]s for flight %[2]s", recipients, flight.Identifier)
+			}
+		}
+
+		apiId, err := p.resolveFlight(ctx, flight)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[apiId] {
+			p.LogWarning("skipping duplicate flight", zap.String("flight_identifier", flight.Identifier))
+			continue
+		}
+
+		seen[apiId] = true
+
+		tracked = append(tracked, trackedFlight{apiId: apiId, recipients: flight.Recipients})
+	}
+
+	for _, flight := range resolved {
+		if !seen[flight.apiId] {
+			seen[flight.apiId] = true
+			tracked = append(tracked, flight)
+		}
+	}
+
+	return tracked, nil
+}
+
+// resolveFlight resolves the passed flight to its FlightAware flight ID.
+func (p *Poller) resolveFlight(ctx context.Context, flight flightaware.FlightConfig) (string, error) {
+	params, err := flight.InformationParams()
+	if err != nil {
+		return "", err
+	}
+
+	_, apiId, err := p.fetchFlightIdentifiers(ctx, params)
+	if err != nil {
+		return "", errors.WithMessagef(err, "error fetching identifiers for flight %[1]s", flight.Identifier)
+	}
+
+	if apiId == "" {
+		return "", errors.WithMessagef(flightaware.ErrNoFlightsFound, "flight %[1]s", flight.Identifier)
+	}
+
+	return apiId, nil
 }
 
 //nolint:gocognit
 func (p *Poller) pollFlightData(
 	ctx context.Context,
-	flightId string,
+	flight trackedFlight,
 	pollerParams *poller.ConcurrentParams,
 ) {
 
@@ -62,28 +250,44 @@ func (p *Poller) pollFlightData(
 
 	var (
 		notifsSent = new(SentNotifications)
+		lifecycle  FlightLifecycle
 		cacheKey   = pollerParams.CacheKey
+		// Version of the flight's cache entry, so that an entry written by
+		// another poller isn't overwritten. Nil if it couldn't be fetched.
+		cacheVersion *uint64
 	)
 
-	ticker := time.NewTicker(p.PollInterval())
+	interval := p.PollInterval()
+	ticker := p.Clock().NewTicker(interval)
+	backoff := newFetchBackoff(interval)
 	cleanup := func(err error) {
+		if err != nil {
+			err = errors.WithMessagef(err, "flight %[1]s", flight.apiId)
+		}
+
 		pollerParams.Cleanup(err, ticker)
 	}
 
 	p.LogDebug("checking for cached data...")
-	if cached, ok, cacheErr := p.fetchCacheEntry(ctx, cacheKey); cacheErr != nil {
+	cached, version, ok, cacheErr := p.fetchCacheEntry(ctx, cacheKey)
+	if cacheErr != nil {
 		p.LogError("error checking for cached data", zap.Error(cacheErr))
-	} else if ok {
+	} else {
+		cacheVersion = &version
+	}
+
+	if ok {
 		flightData = cached.FlightData
 		originInfo = cached.OriginData
 		destinationInfo = cached.DestinationData
 		notifsSent = cached.NotificationsSent
+		lifecycle = cached.Lifecycle
 
 		gotCachedData = true
 	}
 
 	if !gotCachedData {
-		flightData, originInfo, destinationInfo, fetchAllErr = p.fetchAll(ctx, flightId, flightaware.FaFlightIdIdent)
+		flightData, originInfo, destinationInfo, fetchAllErr = p.fetchAll(ctx, flight.apiId, flightaware.FaFlightIdIdent)
 		if fetchAllErr != nil {
 			cleanup(fetchAllErr)
 			return
@@ -99,147 +303,239 @@ func (p *Poller) pollFlightData(
 		zap.String("check_interval", p.PollInterval().String()),
 	)
 
-	isInitial := true
+	phase := p.pollSchedule().Phase(flightData, p.Now().UTC())
+	p.logPollSchedule("flight poll schedule", flightData, notifsSent, p.Now().UTC(), interval)
+
+	var (
+		isInitial = true
+		// Number of polls since the flight was cancelled on which
+		// its cancellation notification failed to send.
+		cancellationAttempts int
+	)
 
 	for {
 		select {
 		case <-ctx.Done():
 			cleanup(nil)
 			return
-		case t := <-ticker.C:
+		case <-ticker.C():
 			if notifsSent.SentAll() {
 				cleanup(nil)
 				return
 			}
 
+			// Data from the previous poll, kept in case fetching fails.
+			var prevFlightData *flightaware.FlightData
+
 			if !isInitial {
 				var flightDataErr error
 
-				flightData, flightDataErr = p.fetchFlight(ctx, flightId, flightaware.FaFlightIdIdent)
+				prevFlightData = flightData
+
+				flightData, flightDataErr = p.fetchFlight(ctx, flight.apiId, flightaware.FaFlightIdIdent)
 				if flightDataErr != nil {
-					p.LogError("error fetching flight data", zap.Error(flightDataErr))
+					if replayEnded(flightDataErr) {
+						cleanup(nil)
+						return
+					}
+
+					if errs.IsFatalApiError(flightDataErr) {
+						cleanup(flightDataErr)
+						return
+					}
+
+					flightData = prevFlightData
+					nextPoll := backoff.Failure(flightDataErr)
+					interval = nextPoll
+					ticker.Reset(nextPoll)
+
+					p.LogError(
+						"error fetching flight data",
+						zap.String("next_poll", nextPoll.String()),
+						zap.Error(flightDataErr),
+					)
+
 					continue
 				}
+
+				if baseInterval, backedOff := backoff.Success(); backedOff {
+					interval = baseInterval
+					ticker.Reset(interval)
+				}
 			} else {
 				isInitial = false
 			}
 
+			now := p.Now().UTC()
+
+			for _, transition := range lifecycle.Advance(flightData, p.pollSchedule(), now) {
+				p.LogInfo(
+					"flight phase changed",
+					zap.String("flight_id", flightData.FlightId),
+					zap.String("phase", string(transition.Phase)),
+					zap.Time("at", transition.At),
+				)
+			}
+
+			notifsSent.SetPhase(lifecycle)
+
+			if nextInterval := p.nextPollInterval(flightData, now, notifsSent); nextInterval != interval {
+				interval = nextInterval
+				ticker.Reset(interval)
+			}
+
+			// Failed fetches are backed off from the interval currently in use.
+			backoff.SetBase(interval)
+
+			if nextPhase := p.pollSchedule().Phase(flightData, now); nextPhase != phase {
+				phase = nextPhase
+				p.logPollSchedule("flight poll phase changed", flightData, notifsSent, now, interval)
+			}
+
+			cancelled := p.sendChangeAlerts(ctx, flightData, originInfo, destinationInfo, notifsSent, flight.recipients)
+			if !cancelled {
+				if inboundErr := p.sendInboundAlert(ctx, flightData, originInfo, destinationInfo, notifsSent, flight.recipients); inboundErr != nil {
+					cleanup(inboundErr)
+					return
+				}
+			}
+
+			p.sendFlightAlert(ctx, lifecycle, flightData, originInfo, destinationInfo, notifsSent, flight.recipients, now)
+
+			if cancelled && !notifsSent.IsSent(CancellationNotification) {
+				if cancellationAttempts++; cancellationAttempts >= maxCancellationAttempts {
+					p.LogError(
+						"giving up on sending cancellation notification",
+						zap.String("flight_id", flightData.FlightId),
+						zap.Int("attempts", cancellationAttempts),
+					)
+
+					notifsSent.SetSent(CancellationNotification)
+				}
+			}
+
 			setCacheErr := p.setCacheEntry(
 				ctx,
 				cacheKey,
+				cacheVersion,
 				flightData,
 				originInfo,
 				destinationInfo,
 				notifsSent,
+				lifecycle,
+				flight,
 			)
 
-			if setCacheErr != nil {
+			if errors.Is(setCacheErr, datastore.ErrVersionMismatch) {
+				cleanup(errors.WithMessage(setCacheErr, "flight updated in cache by another poller"))
+				return
+			} else if setCacheErr != nil {
 				p.LogError("error setting flight data in cache", zap.Error(setCacheErr))
 			}
 
-			flightIdentifier := flightData.Identifiers.IATA
-
-			var (
-				flightEvents = newPastFlightEvents(flightData)
-				notifType    = NoNotification
-				msg          = newAlertMsg(
-					flightIdentifier,
-					flightData,
-					originInfo,
-					destinationInfo,
-					p.FlightAwareConfig().Notifications.UseLocalTime,
-				)
-			)
-
-		DetermineNotify:
-			switch {
-			case flightEvents.Pending():
-				now := time.Now().UTC()
-				departureOffset := flightData.GateDepartureTime.Scheduled.
-					UTC().
-					Sub(now)
-
-				inPreDepartureWindow := departureOffset <= p.FlightAwareConfig().Notifications.PreDeparture.Offset
-				if !notifsSent.PreDeparture && inPreDepartureWindow {
-					msg.SetPlaintextTemplate(messages.PreDepartureAlertPlaintextTemplate)
-					notifType = PreDepartureNotification
-
-					break DetermineNotify
-				}
-			case flightEvents.InProgress():
-				didSendGateDeparture := flightEvents.DepartedGate && notifsSent.GateDeparture
-				didSendTakeoff := flightEvents.Takeoff && notifsSent.Takeoff
+			// A cancelled flight is polled until its cancellation notification has been sent.
+			if notifsSent.SentAll() || (cancelled && notifsSent.IsSent(CancellationNotification)) {
+				cleanup(nil)
+				return
+			}
+		}
+	}
+}
 
-				arrivalOffset := flightData.RunwayArrivalTime.Estimated.
-					UTC().
-					Sub(t.UTC())
+// sendFlightAlert sends at most one notification about the passed flight's lifecycle:
+// one for the phase it's entered since its last notification
+// (or a catch-up summary, if it's entered several), otherwise any pre-departure,
+// pre-arrival, or baggage claim notification which is due.
+func (p *Poller) sendFlightAlert(
+	ctx context.Context,
+	lifecycle FlightLifecycle,
+	flightData *flightaware.FlightData,
+	originInfo, destinationInfo *flightaware.AirportData,
+	notifsSent *SentNotifications,
+	recipients []string,
+	now time.Time,
+) {
 
-				inPreArrivalWindow := arrivalOffset <= p.FlightAwareConfig().Notifications.PreArrival.Offset
+	var (
+		notifsConf = p.FlightAwareConfig().Notifications
+		phase      = lifecycle.Current()
+		pending    = pendingTransitions(lifecycle, notifsSent)
+		notifTypes []notificationType
+		preEvent   preEventDue
+		msg        = newAlertMsg(
+			flightData.Identifiers.IATA,
+			flightData,
+			originInfo,
+			destinationInfo,
+			notifsConf.UseLocalTime,
+		)
+	)
 
-				var (
-					isGateDeparture, isTakeoff bool
-				)
+	switch {
+	case len(pending) > 0:
+		msg = setMsgTransitionData(pending, flightData, destinationInfo, msg, p.Templates())
 
-				switch {
-				case !notifsSent.PreArrival && inPreArrivalWindow:
-					msg.SetPlaintextTemplate(messages.PreArrivalAlertPlaintextTemplate)
-					notifType = PreArrivalNotification
+		for _, transition := range pending {
+			notifTypes = append(notifTypes, transition.Phase.notification())
+		}
+	case phase == PhaseCancelled:
+		return
+	case phase.order() < PhaseDepartedGate.order():
+		if notifsSent.PreDeparture {
+			return
+		}
 
-					break DetermineNotify
-				case didSendGateDeparture && didSendTakeoff:
-					continue
-				case flightEvents.DepartedGate && !flightEvents.Takeoff:
-					notifType = GateDepartureNotification
-					isGateDeparture = true
-				case flightEvents.Takeoff:
-					notifType = TakeoffNotification
-					isTakeoff = true
-				}
+		due, ok := duePreEvent(notifsConf.PreDeparture, flightData.GateDepartureTime, now, notifsSent.PreDepartureSent)
+		if !ok {
+			return
+		}
 
-				msg = setMsgDepartureData(flightData, isGateDeparture, isTakeoff, msg)
-			case flightEvents.ArrivedDestination():
-				didSendLanding := flightEvents.Landed && notifsSent.Landing
-				didSendGateArrival := flightEvents.ArrivedGate && notifsSent.GateArrival
+		msg.SetTemplates(p.Templates().Get(messages.PreDepartureTemplates))
+		notifTypes = []notificationType{PreDepartureNotification}
+		preEvent = due
+	case phase.order() < PhaseLanded.order():
+		if notifsSent.PreArrival {
+			return
+		}
 
-				var (
-					isGateArrival, isLanding bool
-				)
+		due, ok := duePreEvent(notifsConf.PreArrival, flightData.RunwayArrivalTime, now, notifsSent.PreArrivalSent)
+		if !ok {
+			return
+		}
 
-				switch {
-				case didSendGateArrival:
-					break DetermineNotify
-				case didSendLanding:
-					continue
-				case flightEvents.Landed && !flightEvents.ArrivedGate:
-					notifType = LandingNotification
-					isLanding = true
-				case flightEvents.ArrivedGate:
-					notifType = GateArrivalNotification
-					isGateArrival = true
-				}
+		msg.SetTemplates(p.Templates().Get(messages.PreArrivalTemplates))
+		notifTypes = []notificationType{PreArrivalNotification}
+		preEvent = due
+	default:
+		if notifsSent.BaggageClaim {
+			return
+		}
 
-				msg = setMsgArrivalData(flightData, isGateArrival, isLanding, msg)
-			default:
-				msg = nil
-				notifType = NoNotification
+		if flightData.BaggageClaim == "" {
+			// Stop waiting for a baggage claim which hasn't been posted.
+			if baggageClaimTimedOut(flightData, now) {
+				notifsSent.SetSent(BaggageClaimNotification)
 			}
 
-			if msg == nil || notifType == NoNotification {
-				continue
-			}
+			return
+		}
 
-			if sendMsgErr := p.SendMessage(ctx, msg); sendMsgErr != nil {
-				p.LogError("error sending notification", zap.Error(sendMsgErr))
-				continue
-			}
+		msg = setMsgBaggageClaimData(flightData, msg, p.Templates().Get(messages.BaggageClaimTemplates))
+		notifTypes = []notificationType{BaggageClaimNotification}
+	}
 
-			notifsSent.SetSent(notifType)
+	sendRes := p.SendMessageTo(ctx, msg, recipients...)
+	p.logSendErrors(sendRes)
 
-			if notifsSent.SentAll() {
-				cleanup(nil)
-				return
-			}
-		}
+	if !sendRes.Ok() {
+		return
+	}
+
+	switch notifType := notifTypes[0]; notifType {
+	case PreDepartureNotification, PreArrivalNotification:
+		notifsSent.SetPreEventSent(notifType, preEvent, notifsConf)
+	default:
+		notifsSent.SetSent(notifTypes...)
 	}
 }
 
@@ -277,13 +573,14 @@ func setMsgDepartureData(
 	isGateDeparture bool,
 	isTakeoff bool,
 	msg *messages.FlightAwareAlert,
+	tmpls messages.MessageTemplates,
 ) *messages.FlightAwareAlert {
 
 	msg.IsGateDeparture = isGateDeparture
 	msg.IsTakeoff = isTakeoff
 	msg.GateDepartureTime.Actual = flightData.GateDepartureTime.Actual
 	msg.TakeoffTime.Actual = flightData.RunwayDepartureTime.Actual
-	msg.SetPlaintextTemplate(messages.DepartureAlertPlaintextTemplate)
+	msg.SetTemplates(tmpls)
 
 	return msg
 }
@@ -293,13 +590,76 @@ func setMsgArrivalData(
 	isGateArrival bool,
 	isLanding bool,
 	msg *messages.FlightAwareAlert,
+	tmpls messages.MessageTemplates,
 ) *messages.FlightAwareAlert {
 
 	msg.IsGateArrival = isGateArrival
 	msg.IsLanding = isLanding
 	msg.GateArrivalTime.Actual = flightData.GateArrivalTime.Actual
 	msg.LandingTime.Actual = flightData.RunwayArrivalTime.Actual
-	msg.SetPlaintextTemplate(messages.ArrivalAlertPlaintextTemplate)
+	msg.SetTemplates(tmpls)
+
+	return msg
+}
+
+func setMsgBaggageClaimData(
+	flightData *flightaware.FlightData,
+	msg *messages.FlightAwareAlert,
+	tmpls messages.MessageTemplates,
+) *messages.FlightAwareAlert {
+
+	msg.IsBaggageClaim = true
+	msg.BaggageClaim = flightData.BaggageClaim
+	msg.SetTemplates(tmpls)
+
+	return msg
+}
+
+// setMsgTransitionData sets up the passed message for the passed phase transitions.
+// A single transition gets its usual notification, while several are summarized
+// in a catch-up notification.
+func setMsgTransitionData(
+	transitions []PhaseTransition,
+	flightData *flightaware.FlightData,
+	destinationInfo *flightaware.AirportData,
+	msg *messages.FlightAwareAlert,
+	templates *messages.TemplateSet,
+) *messages.FlightAwareAlert {
+
+	if len(transitions) == 1 {
+		switch phase := transitions[0].Phase; phase {
+		case PhaseDepartedGate:
+			return setMsgDepartureData(flightData, true, false, msg, templates.Get(messages.DepartureTemplates))
+		case PhaseAirborne:
+			return setMsgDepartureData(flightData, false, true, msg, templates.Get(messages.DepartureTemplates))
+		case PhaseLanded:
+			return setMsgArrivalData(flightData, false, true, msg, templates.Get(messages.ArrivalTemplates))
+		case PhaseAtGate:
+			return setMsgArrivalData(flightData, true, false, msg, templates.Get(messages.ArrivalTemplates))
+		default:
+			return setMsgChangeData(phase.notification(), nil, flightData, destinationInfo, msg, templates)
+		}
+	}
+
+	msg.IsCatchUp = true
+
+	for _, transition := range transitions {
+		switch transition.Phase {
+		case PhaseDepartedGate:
+			msg.IsGateDeparture = true
+		case PhaseAirborne:
+			msg.IsTakeoff = true
+		case PhaseDiverted:
+			msg.IsDiverted = true
+			msg.DivertedTo = divertedTo(flightData, destinationInfo)
+		case PhaseLanded:
+			msg.IsLanding = true
+		case PhaseAtGate:
+			msg.IsGateArrival = true
+		}
+	}
+
+	msg.SetTemplates(templates.Get(messages.CatchUpTemplates))
 
 	return msg
 }
@@ -0,0 +1,152 @@
+package flightawarepoller
+
+import (
+	"time"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+// PreEventSent records the offsets of the pre-event notifications
+// which have been sent for each trigger.
+type PreEventSent struct {
+	Scheduled []time.Duration
+	Estimated []time.Duration
+}
+
+// preEventDue describes a pre-event notification which is due to be sent,
+// and the offset for each trigger which it was sent for.
+type preEventDue struct {
+	ScheduledOffset time.Duration
+	EstimatedOffset time.Duration
+	Scheduled       bool
+	Estimated       bool
+}
+
+// duePreEvent returns the pre-event notification which is due at `now`
+// for an event at the passed time, if any.
+// If both the scheduled and estimated triggers are due, a single notification is returned for both.
+func duePreEvent(
+	conf flightaware.PreEventConfig,
+	eventTime flightaware.FlightTimestamp,
+	now time.Time,
+	sent PreEventSent,
+) (due preEventDue, ok bool) {
+
+	if !conf.Active() {
+		return
+	}
+
+	offsets := conf.AllOffsets()
+
+	if conf.Scheduled {
+		due.ScheduledOffset, due.Scheduled = dueOffset(eventTime.Scheduled, now, offsets, sent.Scheduled)
+	}
+
+	if conf.Estimated {
+		due.EstimatedOffset, due.Estimated = dueOffset(eventTime.Estimated, now, offsets, sent.Estimated)
+	}
+
+	return due, due.Scheduled || due.Estimated
+}
+
+// dueOffset returns the smallest of the passed offsets for which `now` is within the window
+// between eventTime minus the offset and eventTime, and which hasn't been sent.
+func dueOffset(eventTime, now time.Time, offsets, sent []time.Duration) (due time.Duration, ok bool) {
+	if eventTime.IsZero() || !now.Before(eventTime) {
+		return
+	}
+
+	for _, offset := range offsets {
+		if now.Before(eventTime.Add(-offset)) || containsDuration(sent, offset) {
+			continue
+		}
+
+		if !ok || offset < due {
+			due, ok = offset, true
+		}
+	}
+
+	return
+}
+
+// SetSent records a sent pre-event notification. Offsets greater than
+// the sent offset are also recorded, as their notifications are no longer useful.
+func (s *PreEventSent) SetSent(due preEventDue, offsets []time.Duration) {
+	for _, offset := range offsets {
+		if due.Scheduled && offset >= due.ScheduledOffset && !containsDuration(s.Scheduled, offset) {
+			s.Scheduled = append(s.Scheduled, offset)
+		}
+
+		if due.Estimated && offset >= due.EstimatedOffset && !containsDuration(s.Estimated, offset) {
+			s.Estimated = append(s.Estimated, offset)
+		}
+	}
+}
+
+// SentAll returns true if notifications have been sent
+// for every offset of every enabled trigger.
+func (s PreEventSent) SentAll(conf flightaware.PreEventConfig) bool {
+	for _, offset := range conf.AllOffsets() {
+		if conf.Scheduled && !containsDuration(s.Scheduled, offset) {
+			return false
+		}
+
+		if conf.Estimated && !containsDuration(s.Estimated, offset) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsDuration(durations []time.Duration, d time.Duration) bool {
+	for _, duration := range durations {
+		if duration == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextPreEvent returns the earliest time after `now` at which a pre-event notification
+// which hasn't been sent becomes due for an event at the passed time, if any.
+func nextPreEvent(
+	conf flightaware.PreEventConfig,
+	eventTime flightaware.FlightTimestamp,
+	now time.Time,
+	sent PreEventSent,
+) (next time.Time, ok bool) {
+
+	if !conf.Active() {
+		return
+	}
+
+	triggers := []struct {
+		eventTime time.Time
+		sent      []time.Duration
+		enabled   bool
+	}{
+		{eventTime: eventTime.Scheduled, sent: sent.Scheduled, enabled: conf.Scheduled},
+		{eventTime: eventTime.Estimated, sent: sent.Estimated, enabled: conf.Estimated},
+	}
+
+	for _, trigger := range triggers {
+		if !trigger.enabled || trigger.eventTime.IsZero() {
+			continue
+		}
+
+		for _, offset := range conf.AllOffsets() {
+			at := trigger.eventTime.Add(-offset)
+			if !at.After(now) || containsDuration(trigger.sent, offset) {
+				continue
+			}
+
+			if !ok || at.Before(next) {
+				next, ok = at, true
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,216 @@
+package flightawarepoller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestDuePreEvent(t *testing.T) {
+	var (
+		scheduled = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		eventTime = flightaware.FlightTimestamp{Scheduled: scheduled, Estimated: scheduled.Add(time.Hour)}
+		offsets   = []time.Duration{24 * time.Hour, 3 * time.Hour, 30 * time.Minute}
+	)
+
+	newConf := func(isScheduled, isEstimated bool) flightaware.PreEventConfig {
+		return flightaware.PreEventConfig{
+			Enabled:   true,
+			Scheduled: isScheduled,
+			Estimated: isEstimated,
+			Offsets:   offsets,
+		}
+	}
+
+	tests := []struct {
+		now    time.Time
+		name   string
+		sent   PreEventSent
+		conf   flightaware.PreEventConfig
+		want   preEventDue
+		wantOk bool
+	}{
+		{
+			name: "disabled",
+			now:  scheduled.Add(-time.Hour),
+			conf: flightaware.PreEventConfig{Scheduled: true, Offsets: offsets},
+		},
+		{
+			name: "no triggers",
+			now:  scheduled.Add(-time.Hour),
+			conf: newConf(false, false),
+		},
+		{
+			name: "before first offset",
+			now:  scheduled.Add(-25 * time.Hour),
+			conf: newConf(true, false),
+		},
+		{
+			name:   "scheduled",
+			now:    scheduled.Add(-2 * time.Hour),
+			conf:   newConf(true, false),
+			want:   preEventDue{Scheduled: true, ScheduledOffset: 3 * time.Hour},
+			wantOk: true,
+		},
+		{
+			name: "scheduled already sent",
+			now:  scheduled.Add(-2 * time.Hour),
+			conf: newConf(true, false),
+			sent: PreEventSent{Scheduled: []time.Duration{24 * time.Hour, 3 * time.Hour}},
+		},
+		{
+			name:   "estimated",
+			now:    scheduled.Add(-2 * time.Hour),
+			conf:   newConf(false, true),
+			want:   preEventDue{Estimated: true, EstimatedOffset: 3 * time.Hour},
+			wantOk: true,
+		},
+		{
+			name: "both",
+			now:  scheduled.Add(-20 * time.Minute),
+			conf: newConf(true, true),
+			want: preEventDue{
+				Scheduled:       true,
+				ScheduledOffset: 30 * time.Minute,
+				Estimated:       true,
+				EstimatedOffset: 3 * time.Hour,
+			},
+			wantOk: true,
+		},
+		{
+			name: "event passed",
+			now:  scheduled.Add(time.Minute),
+			conf: newConf(true, false),
+		},
+		{
+			name:   "single offset",
+			now:    scheduled.Add(-20 * time.Minute),
+			conf:   flightaware.PreEventConfig{Enabled: true, Scheduled: true, Offset: time.Hour},
+			want:   preEventDue{Scheduled: true, ScheduledOffset: time.Hour},
+			wantOk: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := duePreEvent(tt.conf, eventTime, tt.now, tt.sent)
+			assert.Equal(t, tt.wantOk, ok)
+
+			if tt.wantOk {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSentNotifications_SetPreEventSent(t *testing.T) {
+	var (
+		departure  = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		eventTime  = flightaware.FlightTimestamp{Scheduled: departure}
+		notifsConf = flightaware.NotificationsConfig{
+			PreDeparture: flightaware.PreEventConfig{
+				Enabled:   true,
+				Scheduled: true,
+				Offsets:   []time.Duration{24 * time.Hour, 3 * time.Hour, 30 * time.Minute},
+			},
+		}
+		notifsSent = new(SentNotifications)
+	)
+
+	for _, tc := range []struct {
+		now        time.Time
+		wantOffset time.Duration
+		wantAll    bool
+	}{
+		// Polling started after the 3 hour window was entered, so only
+		// the 3 hour notification is sent, and the 24 hour one is skipped.
+		{now: departure.Add(-2 * time.Hour), wantOffset: 3 * time.Hour},
+		{now: departure.Add(-10 * time.Minute), wantOffset: 30 * time.Minute, wantAll: true},
+	} {
+		due, ok := duePreEvent(notifsConf.PreDeparture, eventTime, tc.now, notifsSent.PreDepartureSent)
+		if !assert.True(t, ok) {
+			return
+		}
+
+		assert.Equal(t, tc.wantOffset, due.ScheduledOffset)
+
+		notifsSent.SetPreEventSent(PreDepartureNotification, due, notifsConf)
+		assert.Equal(t, tc.wantAll, notifsSent.PreDeparture)
+	}
+
+	_, ok := duePreEvent(notifsConf.PreDeparture, eventTime, departure.Add(-5*time.Minute), notifsSent.PreDepartureSent)
+	assert.False(t, ok)
+}
+
+func TestNextPreEvent(t *testing.T) {
+	var (
+		scheduled = time.Date(2022, 5, 31, 19, 0, 0, 0, time.UTC)
+		eventTime = flightaware.FlightTimestamp{Scheduled: scheduled, Estimated: scheduled.Add(time.Hour)}
+		offsets   = []time.Duration{24 * time.Hour, 3 * time.Hour, 30 * time.Minute}
+	)
+
+	newConf := func(isScheduled, isEstimated bool) flightaware.PreEventConfig {
+		return flightaware.PreEventConfig{
+			Enabled:   true,
+			Scheduled: isScheduled,
+			Estimated: isEstimated,
+			Offsets:   offsets,
+		}
+	}
+
+	tests := []struct {
+		now    time.Time
+		want   time.Time
+		name   string
+		sent   PreEventSent
+		conf   flightaware.PreEventConfig
+		wantOk bool
+	}{
+		{
+			name: "disabled",
+			now:  scheduled.Add(-48 * time.Hour),
+			conf: flightaware.PreEventConfig{Scheduled: true, Offsets: offsets},
+		},
+		{
+			name:   "scheduled",
+			now:    scheduled.Add(-48 * time.Hour),
+			conf:   newConf(true, false),
+			want:   scheduled.Add(-24 * time.Hour),
+			wantOk: true,
+		},
+		{
+			name:   "earliest of both triggers",
+			now:    scheduled.Add(-2 * time.Hour),
+			conf:   newConf(true, true),
+			want:   scheduled.Add(-30 * time.Minute),
+			wantOk: true,
+		},
+		{
+			name:   "skips sent offsets",
+			now:    scheduled.Add(-2 * time.Hour),
+			conf:   newConf(true, true),
+			sent:   PreEventSent{Scheduled: []time.Duration{30 * time.Minute}},
+			want:   scheduled.Add(30 * time.Minute),
+			wantOk: true,
+		},
+		{
+			name: "none left",
+			now:  scheduled.Add(-10 * time.Minute),
+			conf: newConf(true, false),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nextPreEvent(tt.conf, eventTime, tt.now, tt.sent)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
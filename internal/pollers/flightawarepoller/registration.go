@@ -0,0 +1,255 @@
+package flightawarepoller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+// watchedRegistration is an aircraft registration
+// whose flights are tracked as they are filed.
+type watchedRegistration struct {
+	registration string
+	recipients   []string
+	// IDs of flights filed for the registration which were resumed from a previous run,
+	// and are already being polled.
+	resumedFlightIds []string
+}
+
+func (r watchedRegistration) cacheKey() string {
+	return "registration:" + r.registration
+}
+
+// resolveRegistrations validates the passed registration flight configs.
+// Registrations which are already being watched are skipped.
+func (p *Poller) resolveRegistrations(flights []flightaware.FlightConfig) ([]watchedRegistration, error) {
+	var (
+		watched = make([]watchedRegistration, 0, len(flights))
+		seen    = make(map[string]bool, len(flights))
+	)
+
+	for _, flight := range flights {
+		for _, recipients := range flight.Recipients {
+			if !p.HasRecipients(recipients) {
+				return nil, errors.Errorf("unknown recipients %[1]s for registration %[2]s", recipients, flight.Identifier)
+			}
+		}
+
+		params, err := flight.InformationParams()
+		if err != nil {
+			return nil, err
+		}
+
+		registration := params.FlightIdentifier()
+
+		if seen[registration] {
+			p.LogWarning("skipping duplicate registration", zap.String("registration", registration))
+			continue
+		}
+
+		seen[registration] = true
+
+		watched = append(watched, watchedRegistration{registration: registration, recipients: flight.Recipients})
+	}
+
+	return watched, nil
+}
+
+// claimResumedFlights records the resumed flights which were filed for each of the passed registrations,
+// so that they aren't polled a second time once their registration's watcher finds them.
+func claimResumedFlights(registrations []watchedRegistration, resumed []resumedFlight) {
+	for i := range registrations {
+		for _, flight := range resumed {
+			if flight.entry.Registration == registrations[i].registration {
+				registrations[i].resumedFlightIds = append(registrations[i].resumedFlightIds, flight.flightId())
+			}
+		}
+	}
+}
+
+// watchRegistration checks for new flights filed for an aircraft registration, polling each new flight
+// with the normal notification lifecycle, until ctx is cancelled or a fatal API error occurs.
+// Errors from individual flights are logged, and don't stop the registration from being watched.
+func (p *Poller) watchRegistration(
+	ctx context.Context,
+	registration watchedRegistration,
+	pollerParams *poller.ConcurrentParams,
+) {
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		// Flights which have been tracked, and whether they're still being polled.
+		tracked = make(map[string]bool)
+	)
+
+	// Resumed flights are polled by Start, so they're only recorded
+	// as tracked (and pruned like finished flights once they're no longer listed).
+	for _, flightId := range registration.resumedFlightIds {
+		tracked[flightId] = false
+	}
+
+	ticker := p.Clock().NewTicker(p.PollInterval())
+	backoff := newFetchBackoff(p.PollInterval())
+	cleanup := func(err error) {
+		wg.Wait()
+
+		if err != nil {
+			err = errors.WithMessagef(err, "registration %[1]s", registration.registration)
+		}
+
+		pollerParams.Cleanup(err, ticker)
+	}
+
+	// checkFlights starts polling any new flights, returning
+	// an error if flights can no longer be fetched.
+	// Running out of replayed responses stops the watch without an error.
+	checkFlights := func() error {
+		flights, err := p.fetchRegistrationFlights(ctx, registration.registration)
+		if err != nil {
+			if errs.IsFatalApiError(err) || replayEnded(err) {
+				return err
+			}
+
+			nextCheck := backoff.Failure(err)
+			ticker.Reset(nextCheck)
+
+			p.LogError(
+				"error fetching flights for registration",
+				zap.String("registration", registration.registration),
+				zap.String("next_check", nextCheck.String()),
+				zap.Error(err),
+			)
+
+			return nil
+		}
+
+		if interval, backedOff := backoff.Success(); backedOff {
+			ticker.Reset(interval)
+		}
+
+		mu.Lock()
+		pruneRegistrationFlights(tracked, flights)
+		newFlights := newRegistrationFlights(flights, tracked, p.Now().UTC())
+
+		for _, flightId := range newFlights {
+			tracked[flightId] = true
+		}
+		mu.Unlock()
+
+		for _, flightId := range newFlights {
+
+			p.LogInfo(
+				"tracking new flight for registration",
+				zap.String("registration", registration.registration),
+				zap.String("flight_id", flightId),
+			)
+
+			flight := trackedFlight{
+				apiId:        flightId,
+				recipients:   registration.recipients,
+				registration: registration.registration,
+			}
+
+			flightParams := poller.NewConcurrentParams(pollerParams.AuthData, flight.cacheKey())
+
+			wg.Add(1)
+
+			go p.pollFlightData(ctx, flight, flightParams)
+
+			go func(flightId string, errCh chan error) {
+				defer wg.Done()
+
+				flightErr := <-errCh
+
+				mu.Lock()
+				tracked[flightId] = false
+				mu.Unlock()
+
+				if flightErr != nil {
+					p.LogError(
+						"error polling flight for registration",
+						zap.String("registration", registration.registration),
+						zap.String("flight_id", flightId),
+						zap.Error(flightErr),
+					)
+				}
+			}(flightId, flightParams.ErrCh)
+		}
+
+		return nil
+	}
+
+	p.LogInfo(
+		"watching registration",
+		zap.String("registration", registration.registration),
+		zap.String("check_interval", p.PollInterval().String()),
+	)
+
+	if err := checkFlights(); err != nil {
+		cleanup(ignoreReplayEnd(err))
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			cleanup(nil)
+			return
+		case <-ticker.C():
+			if err := checkFlights(); err != nil {
+				cleanup(ignoreReplayEnd(err))
+				return
+			}
+		}
+	}
+}
+
+// newRegistrationFlights returns the IDs of flights which should start being tracked:
+// flights which aren't already tracked (whether or not they're still being polled),
+// haven't been cancelled or arrived, and are scheduled to depart within registrationLookahead of `now`.
+// IDs are returned in order of scheduled departure.
+func newRegistrationFlights(flights []flightaware.FlightData, tracked map[string]bool, now time.Time) []string {
+	var flightIds []string
+
+	for _, flight := range flightaware.SortFlights(flights) {
+		scheduledDeparture := flight.GateDepartureTime.Scheduled
+		_, isTracked := tracked[flight.FlightId]
+
+		switch {
+		case flight.FlightId == "", isTracked:
+			continue
+		case flight.Cancelled, validTimestampActual(flight.GateArrivalTime), validTimestampActual(flight.RunwayArrivalTime):
+			continue
+		case scheduledDeparture.IsZero(), scheduledDeparture.After(now.Add(registrationLookahead)):
+			continue
+		}
+
+		flightIds = append(flightIds, flight.FlightId)
+	}
+
+	return flightIds
+}
+
+// pruneRegistrationFlights removes flights which have finished polling and are no longer
+// listed for the registration from tracked, so that it doesn't grow for as long as the registration is watched.
+// Finished flights which are still listed are kept, so that they aren't tracked again.
+func pruneRegistrationFlights(tracked map[string]bool, flights []flightaware.FlightData) {
+	listed := make(map[string]bool, len(flights))
+	for i := range flights {
+		listed[flights[i].FlightId] = true
+	}
+
+	for flightId, polling := range tracked {
+		if !polling && !listed[flightId] {
+			delete(tracked, flightId)
+		}
+	}
+}
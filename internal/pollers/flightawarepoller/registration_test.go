@@ -0,0 +1,73 @@
+package flightawarepoller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestNewRegistrationFlights(t *testing.T) {
+	now := time.Date(2022, 5, 31, 12, 0, 0, 0, time.UTC)
+
+	newFlight := func(flightId string, departureIn time.Duration, modify func(d *flightaware.FlightData)) flightaware.FlightData {
+		d := flightaware.FlightData{
+			FlightId:          flightId,
+			GateDepartureTime: flightaware.FlightTimestamp{Scheduled: now.Add(departureIn)},
+		}
+
+		if modify != nil {
+			modify(&d)
+		}
+
+		return d
+	}
+
+	flights := []flightaware.FlightData{
+		newFlight("later", 6*time.Hour, nil),
+		newFlight("soon", time.Hour, nil),
+		newFlight("tracked", 2*time.Hour, nil),
+		newFlight("finished", 4*time.Hour, nil),
+		newFlight("next-week", 7*24*time.Hour, nil),
+		newFlight("cancelled", 3*time.Hour, func(d *flightaware.FlightData) { d.Cancelled = true }),
+		newFlight("arrived", -5*time.Hour, func(d *flightaware.FlightData) {
+			d.GateArrivalTime.Actual = now.Add(-3 * time.Hour)
+		}),
+		newFlight("airborne", -time.Hour, func(d *flightaware.FlightData) {
+			d.RunwayDepartureTime.Actual = now.Add(-50 * time.Minute)
+		}),
+	}
+
+	got := newRegistrationFlights(flights, map[string]bool{"tracked": true, "finished": false}, now)
+	assert.Equal(t, []string{"airborne", "soon", "later"}, got)
+}
+
+func TestPruneRegistrationFlights(t *testing.T) {
+	tracked := map[string]bool{
+		"polling":          true,
+		"polling-unlisted": true,
+		"finished":         false,
+		"finished-expired": false,
+	}
+
+	flights := []flightaware.FlightData{{FlightId: "polling"}, {FlightId: "finished"}}
+
+	pruneRegistrationFlights(tracked, flights)
+	assert.Equal(t, map[string]bool{"polling": true, "polling-unlisted": true, "finished": false}, tracked)
+}
+
+func TestClaimResumedFlights(t *testing.T) {
+	registrations := []watchedRegistration{{registration: "N12345"}, {registration: "N67890"}}
+
+	resumed := []resumedFlight{
+		{entry: &CacheEntry{InternalId: "filed-for-N12345", Registration: "N12345"}},
+		{entry: &CacheEntry{InternalId: "standalone"}},
+		{entry: &CacheEntry{InternalId: "filed-for-unwatched", Registration: "N00000"}},
+	}
+
+	claimResumedFlights(registrations, resumed)
+	assert.Equal(t, []string{"filed-for-N12345"}, registrations[0].resumedFlightIds)
+	assert.Empty(t, registrations[1].resumedFlightIds)
+}
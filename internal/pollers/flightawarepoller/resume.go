@@ -0,0 +1,122 @@
+package flightawarepoller
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// flightCacheKeyPrefix is the prefix of the cache keys of polled flights.
+const flightCacheKeyPrefix = "flightdata:"
+
+// resumedFlight is a flight which was being polled when a previous run stopped.
+type resumedFlight struct {
+	entry    *CacheEntry
+	cacheKey string
+}
+
+// resumeFlights returns the flights in the datastore which were still being polled
+// when a previous run stopped, skipping those in the passed tracked flights.
+// Flights which have had every notification sent (or their cancellation notification,
+// if they've been cancelled), or which notify recipients the poller doesn't have, are also skipped.
+func (p *Poller) resumeFlights(ctx context.Context, tracked []trackedFlight) ([]resumedFlight, error) {
+	skip := make(map[string]bool, len(tracked))
+	for _, flight := range tracked {
+		skip[flight.cacheKey()] = true
+	}
+
+	var resumed []resumedFlight
+
+	err := p.Datastore().Scan(ctx, flightCacheKeyPrefix, func(key string, entry *CacheEntry) error {
+		if skip[key] || !resumable(entry) {
+			return nil
+		}
+
+		// As when starting, flights with unknown recipients (ex. groups removed
+		// from the config since the previous run) aren't polled.
+		for _, recipients := range entry.Recipients {
+			if !p.HasRecipients(recipients) {
+				p.LogWarning(
+					"skipping resumed flight with unknown recipients",
+					zap.String("cache_key", key),
+					zap.String("recipients", recipients),
+				)
+
+				return nil
+			}
+		}
+
+		p.LogInfo(
+			"resuming flight",
+			zap.String("flight_id", entry.InternalId),
+			zap.String("flight_identifier", entry.FlightId),
+			zap.String("phase", string(entry.Lifecycle.Current())),
+		)
+
+		resumed = append(resumed, resumedFlight{entry: entry, cacheKey: key})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resumed, nil
+}
+
+// resumable returns true if polling the flight in the passed cache entry
+// could still send notifications.
+func resumable(entry *CacheEntry) bool {
+	if entry.FlightData == nil || entry.OriginData == nil || entry.DestinationData == nil {
+		return false
+	}
+
+	if entry.NotificationsSent == nil {
+		return true
+	}
+
+	if entry.Lifecycle.Current() == PhaseCancelled || entry.FlightData.Cancelled {
+		return !entry.NotificationsSent.IsSent(CancellationNotification)
+	}
+
+	return !entry.NotificationsSent.SentAll()
+}
+
+// flightId returns the FlightAware flight ID of the resumed flight.
+func (f resumedFlight) flightId() string {
+	if f.entry.InternalId != "" {
+		return f.entry.InternalId
+	}
+
+	return strings.TrimPrefix(f.cacheKey, flightCacheKeyPrefix)
+}
+
+// trackedFlight returns the resumed flight, notifying the recipients it was stored with.
+func (f resumedFlight) trackedFlight() trackedFlight {
+	return trackedFlight{
+		apiId:        f.flightId(),
+		recipients:   f.entry.Recipients,
+		registration: f.entry.Registration,
+	}
+}
+
+// withStoredConfig returns a copy of the poller which polls and notifies about the flight
+// in the passed cache entry using the configuration it was stored with.
+// The copy shares the poller's FlightAware client, notifiers, and datastore.
+func (p *Poller) withStoredConfig(entry *CacheEntry) *Poller {
+	var (
+		resumed    = *p
+		basePoller = *p.BasePoller
+	)
+
+	resumed.BasePoller = &basePoller
+	resumed.flightawareConfig.Notifications = entry.Notifications.WithDefaultTriggers()
+
+	if entry.PollInterval > 0 {
+		resumed.flightawareConfig.PollInterval = entry.PollInterval
+		resumed.SetPollInterval(entry.PollInterval)
+	}
+
+	return &resumed
+}
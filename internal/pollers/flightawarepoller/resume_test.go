@@ -0,0 +1,72 @@
+package flightawarepoller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestResumable(t *testing.T) {
+	allSent := SentNotifications{
+		GateDeparture: true,
+		Takeoff:       true,
+		Landing:       true,
+		GateArrival:   true,
+		PreArrival:    true,
+		PreDeparture:  true,
+		BaggageClaim:  true,
+	}
+
+	newEntry := func(cancelled bool, notifsSent *SentNotifications) *CacheEntry {
+		return &CacheEntry{
+			FlightData:        &flightaware.FlightData{Cancelled: cancelled},
+			OriginData:        new(flightaware.AirportData),
+			DestinationData:   new(flightaware.AirportData),
+			NotificationsSent: notifsSent,
+		}
+	}
+
+	tests := []struct {
+		entry *CacheEntry
+		name  string
+		want  bool
+	}{
+		{
+			name:  "in progress",
+			entry: newEntry(false, &SentNotifications{GateDeparture: true}),
+			want:  true,
+		},
+		{
+			name:  "nothing sent",
+			entry: newEntry(false, nil),
+			want:  true,
+		},
+		{
+			name:  "every notification sent",
+			entry: newEntry(false, &allSent),
+		},
+		{
+			name:  "no flight data",
+			entry: &CacheEntry{NotificationsSent: new(SentNotifications)},
+		},
+		{
+			name:  "cancellation not sent",
+			entry: newEntry(true, &SentNotifications{GateDeparture: true}),
+			want:  true,
+		},
+		{
+			name:  "cancellation sent",
+			entry: newEntry(true, &SentNotifications{Cancellation: true}),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resumable(tt.entry))
+		})
+	}
+}
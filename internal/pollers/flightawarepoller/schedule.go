@@ -0,0 +1,113 @@
+package flightawarepoller
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
+)
+
+// pollSchedule returns the schedule which flights are polled on.
+// The schedule is disabled while replaying recorded traffic,
+// as polling then runs as fast as the recorded responses allow.
+func (p Poller) pollSchedule() flightaware.PollScheduleConfig {
+	faConf := p.FlightAwareConfig()
+
+	schedule := faConf.PollSchedule
+	if faConf.Cassette.Mode == transport.CassetteReplay {
+		schedule.Disabled = true
+	}
+
+	return schedule
+}
+
+// nextPollInterval returns the interval until the next poll of the passed flight,
+// following the poll schedule, and shortened so that the flight
+// is polled once any pre-event notification which hasn't been sent is due.
+func (p Poller) nextPollInterval(
+	flightData *flightaware.FlightData,
+	now time.Time,
+	notifsSent *SentNotifications,
+) time.Duration {
+
+	interval := p.pollSchedule().NextInterval(flightData, now, p.PollInterval())
+	notifsConf := p.FlightAwareConfig().Notifications
+
+	preEvents := []struct {
+		conf      flightaware.PreEventConfig
+		eventTime flightaware.FlightTimestamp
+		sent      PreEventSent
+		done      bool
+	}{
+		{
+			conf:      notifsConf.PreDeparture,
+			eventTime: flightData.GateDepartureTime,
+			sent:      notifsSent.PreDepartureSent,
+			done:      notifsSent.PreDeparture,
+		},
+		{
+			conf:      notifsConf.PreArrival,
+			eventTime: flightData.RunwayArrivalTime,
+			sent:      notifsSent.PreArrivalSent,
+			done:      notifsSent.PreArrival,
+		},
+	}
+
+	for _, preEvent := range preEvents {
+		if preEvent.done {
+			continue
+		}
+
+		next, ok := nextPreEvent(preEvent.conf, preEvent.eventTime, now, preEvent.sent)
+		if !ok {
+			continue
+		}
+
+		if untilNext := next.Sub(now); untilNext < interval {
+			interval = untilNext
+		}
+	}
+
+	if interval < p.PollInterval() {
+		interval = p.PollInterval()
+	}
+
+	return interval
+}
+
+// logPollSchedule logs the passed flight's poll phase and next poll interval,
+// along with the number of AeroAPI queries (and their cost, if configured)
+// projected to be sent for it until it arrives, including those for its inbound flight.
+func (p Poller) logPollSchedule(
+	msg string,
+	flightData *flightaware.FlightData,
+	notifsSent *SentNotifications,
+	now time.Time,
+	interval time.Duration,
+) {
+
+	var (
+		schedule = p.pollSchedule()
+		queries  = schedule.ProjectedQueries(flightData, now, p.PollInterval())
+	)
+
+	// The inbound flight is fetched on each poll until the flight departs, at most.
+	if p.followsInbound(flightData, notifsSent) {
+		queries += schedule.ProjectedDepartureQueries(flightData, now, p.PollInterval())
+	}
+
+	fields := []zap.Field{
+		zap.String("flight_id", flightData.FlightId),
+		zap.String("phase", string(schedule.Phase(flightData, now))),
+		zap.String("next_poll", interval.String()),
+		zap.Int("projected_queries", queries),
+	}
+
+	if costPerRequest := p.FlightAwareConfig().Transport.CostPerRequest; costPerRequest > 0 {
+		fields = append(fields, zap.Float64("projected_cost", float64(queries)*costPerRequest))
+	}
+
+	p.LogInfo(msg, fields...)
+}
@@ -17,18 +17,62 @@ type CacheEntry struct {
 	InternalId        string
 	FlightId          string
 	RecipientConfig   poller.RecipientConfig
-	Notifications     flightaware.NotificationsConfig
-	PollInterval      time.Duration
+	// Names of the recipient groups notified about the flight.
+	Recipients []string
+	// Registration of the watched aircraft the flight was filed for, if any.
+	Registration  string
+	Notifications flightaware.NotificationsConfig
+	PollInterval  time.Duration
+	Lifecycle     FlightLifecycle
 }
 
 type SentNotifications struct {
-	GateDeparture bool
-	Takeoff       bool
-	Landing       bool
-	GateArrival   bool
-	PreArrival    bool
-	PreDeparture  bool
-	BaggageClaim  bool
+	// Departure/arrival delays included in the last delay notification.
+	DepartureDelay time.Duration
+	ArrivalDelay   time.Duration
+	// Predicted departure delay included in the last inbound aircraft notification.
+	InboundDelay time.Duration
+	// Set once the flight's inbound flight no longer needs to be fetched:
+	// it has arrived at its gate, or no further inbound aircraft notifications can be sent.
+	InboundDone bool
+	// Pre-event notifications sent for each trigger and offset.
+	// PreDeparture and PreArrival are only set once none are left to send.
+	PreDepartureSent PreEventSent
+	PreArrivalSent   PreEventSent
+	GateDeparture    bool
+	Takeoff          bool
+	Landing          bool
+	GateArrival      bool
+	PreArrival       bool
+	PreDeparture     bool
+	BaggageClaim     bool
+	Cancellation     bool
+	Diversion        bool
+	// Origin/destination gates and terminals included in the last gate change notification
+	// (or seen on the flight's first poll), which gate changes are detected against.
+	// GatesNotified is false until they've first been recorded.
+	OriginGate      NotifiedGate
+	DestinationGate NotifiedGate
+	GatesNotified   bool
+}
+
+// NotifiedGate is a gate and terminal which recipients have been notified about.
+type NotifiedGate struct {
+	Gate     string
+	Terminal string
+}
+
+// update returns the gate with the passed airport's gate and terminal,
+// keeping those which are unset.
+func (g NotifiedGate) update(airport flightaware.FlightOriginDestinationData) NotifiedGate {
+	if airport.Gate != "" {
+		g.Gate = airport.Gate
+	}
+	if airport.Terminal != "" {
+		g.Terminal = airport.Terminal
+	}
+
+	return g
 }
 
 func (s *SentNotifications) SetDisabled(notifsConfig flightaware.NotificationsConfig) {
@@ -44,88 +88,148 @@ func (s *SentNotifications) SetDisabled(notifsConfig flightaware.NotificationsCo
 	if !notifsConfig.GateArrival {
 		s.GateArrival = true
 	}
-	if !notifsConfig.PreArrival.Enabled {
+	if !notifsConfig.PreArrival.Active() {
 		s.PreArrival = true
 	}
-	if !notifsConfig.PreDeparture.Enabled {
+	if !notifsConfig.PreDeparture.Active() {
 		s.PreDeparture = true
 	}
+	if !notifsConfig.BaggageClaim {
+		s.BaggageClaim = true
+	}
+	if !notifsConfig.Cancellation {
+		s.Cancellation = true
+	}
+	if !notifsConfig.Diversion {
+		s.Diversion = true
+	}
 }
 
-func (s *SentNotifications) SetSent(notifType notificationType) {
-	var allSentNotifs = []notificationType{notifType}
+// SetDelaysNotified records the delays included in a delay notification.
+// Delays below the configured threshold aren't recorded, so that they
+// can still trigger a notification once they cross it.
+func (s *SentNotifications) SetDelaysNotified(departureDelay, arrivalDelay time.Duration, delayConfig flightaware.DelayConfig) {
+	if departureDelay > 0 && departureDelay >= delayConfig.Threshold {
+		s.DepartureDelay = departureDelay
+	}
+	if arrivalDelay > 0 && arrivalDelay >= delayConfig.Threshold {
+		s.ArrivalDelay = arrivalDelay
+	}
+}
 
-	if otherNotifs, ok := notifTypesMap[notifType]; ok {
-		allSentNotifs = append(allSentNotifs, otherNotifs...)
+// SetGatesNotified records the passed flight's gates and terminals
+// as those which recipients have been notified about.
+func (s *SentNotifications) SetGatesNotified(flightData *flightaware.FlightData) {
+	s.OriginGate = s.OriginGate.update(flightData.Origin)
+	s.DestinationGate = s.DestinationGate.update(flightData.Destination)
+	s.GatesNotified = true
+}
+
+// SetPreEventSent records a sent pre-departure or pre-arrival notification,
+// marking the notification type as sent once every trigger and offset has been sent.
+func (s *SentNotifications) SetPreEventSent(notifType notificationType, due preEventDue, notifsConfig flightaware.NotificationsConfig) {
+	var (
+		sent *PreEventSent
+		conf flightaware.PreEventConfig
+	)
+
+	switch notifType {
+	case PreDepartureNotification:
+		sent, conf = &s.PreDepartureSent, notifsConfig.PreDeparture
+	case PreArrivalNotification:
+		sent, conf = &s.PreArrivalSent, notifsConfig.PreArrival
+	default:
+		return
 	}
 
-	s.setSent(allSentNotifs...)
+	sent.SetSent(due, conf.AllOffsets())
+
+	if sent.SentAll(conf) {
+		s.SetSent(notifType)
+	}
 }
 
-func (s *SentNotifications) setSent(notifTypes ...notificationType) {
+// SetSent marks the passed notification types as sent.
+func (s *SentNotifications) SetSent(notifTypes ...notificationType) {
 	for _, n := range notifTypes {
-		switch n {
-		case GateDepartureNotification:
-			s.GateDeparture = true
-		case TakeoffNotification:
-			s.Takeoff = true
-		case LandingNotification:
-			s.Landing = true
-		case GateArrivalNotification:
-			s.GateArrival = true
-		case PreDepartureNotification:
-			s.PreDeparture = true
-		case PreArrivalNotification:
-			s.PreArrival = true
-		case BaggageClaimNotification:
-			s.BaggageClaim = true
+		if sent := s.sentField(n); sent != nil {
+			*sent = true
 		}
 	}
 }
 
-func (s SentNotifications) SentAll() bool {
-	return s.GateDeparture &&
-		s.Takeoff &&
-		s.Landing &&
-		s.GateArrival &&
-		s.PreArrival &&
-		s.PreDeparture
-}
+// IsSent returns true if the passed notification type has been sent
+// (or won't be sent, ex. because it's disabled).
+// Notification types which can be sent more than once, such as gate changes, are never marked as sent.
+func (s *SentNotifications) IsSent(notifType notificationType) bool {
+	sent := s.sentField(notifType)
 
-var notifTypesMap = map[notificationType][]notificationType{
-	GateDepartureNotification: {PreDepartureNotification},
-	TakeoffNotification:       {PreDepartureNotification, GateDepartureNotification},
-	LandingNotification:       {PreDepartureNotification, GateDepartureNotification, TakeoffNotification, PreArrivalNotification},
-	GateArrivalNotification:   {PreDepartureNotification, GateDepartureNotification, TakeoffNotification, PreArrivalNotification, LandingNotification},
-	PreArrivalNotification:    {PreDepartureNotification, PreArrivalNotification},
+	return sent != nil && *sent
 }
 
-type pastFlightEvents struct {
-	pending      bool
-	DepartedGate bool
-	Takeoff      bool
-	Landed       bool
-	ArrivedGate  bool
-}
+// SetPhase marks the notifications which will no longer be sent
+// for a flight in the passed lifecycle as sent:
+// those of the phases it skipped, ex. a gate departure which FlightAware has no record of,
+// as well as pre-departure notifications once it's departed its gate
+// and pre-arrival notifications once it's landed.
+func (s *SentNotifications) SetPhase(lifecycle FlightLifecycle) {
+	current := lifecycle.Current()
 
-func newPastFlightEvents(flightData *flightaware.FlightData) pastFlightEvents {
-	return pastFlightEvents{
-		pending:      flightData.GateDepartureTime.Scheduled.UTC().After(time.Now().UTC()),
-		DepartedGate: validTimestampActual(flightData.GateDepartureTime),
-		Takeoff:      validTimestampActual(flightData.RunwayDepartureTime),
-		Landed:       validTimestampActual(flightData.RunwayArrivalTime),
-		ArrivedGate:  validTimestampActual(flightData.GateArrivalTime),
+	if current == PhaseCancelled {
+		return
+	}
+
+	for _, phase := range lifecyclePhases[:current.order()] {
+		// Diversions aren't a step every flight goes through.
+		if phase == PhaseDiverted || lifecycle.Reached(phase) {
+			continue
+		}
+
+		if notifType := phase.notification(); notifType != NoNotification {
+			s.SetSent(notifType)
+		}
 	}
-}
 
-func (e pastFlightEvents) Pending() bool {
-	return e.pending
+	if current.order() >= PhaseDepartedGate.order() {
+		s.SetSent(PreDepartureNotification)
+	}
+
+	if current.order() >= PhaseLanded.order() {
+		s.SetSent(PreArrivalNotification)
+	}
 }
 
-func (e pastFlightEvents) InProgress() bool {
-	return (e.DepartedGate || e.Takeoff) && !(e.Landed || e.ArrivedGate)
+func (s *SentNotifications) sentField(notifType notificationType) *bool {
+	switch notifType {
+	case GateDepartureNotification:
+		return &s.GateDeparture
+	case TakeoffNotification:
+		return &s.Takeoff
+	case LandingNotification:
+		return &s.Landing
+	case GateArrivalNotification:
+		return &s.GateArrival
+	case PreDepartureNotification:
+		return &s.PreDeparture
+	case PreArrivalNotification:
+		return &s.PreArrival
+	case BaggageClaimNotification:
+		return &s.BaggageClaim
+	case CancellationNotification:
+		return &s.Cancellation
+	case DiversionNotification:
+		return &s.Diversion
+	default:
+		return nil
+	}
 }
 
-func (e pastFlightEvents) ArrivedDestination() bool {
-	return e.Landed || e.ArrivedGate
+func (s SentNotifications) SentAll() bool {
+	return s.GateDeparture &&
+		s.Takeoff &&
+		s.Landing &&
+		s.GateArrival &&
+		s.PreArrival &&
+		s.PreDeparture &&
+		s.BaggageClaim
 }
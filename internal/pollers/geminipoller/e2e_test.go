@@ -0,0 +1,189 @@
+package geminipoller_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jalavosus/stuffnotifier/internal/datastore"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/geminipoller"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+	"github.com/jalavosus/stuffnotifier/internal/testservers"
+	"github.com/jalavosus/stuffnotifier/pkg/gemini"
+	"github.com/jalavosus/stuffnotifier/pkg/slack"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
+)
+
+func TestPoller_Start_EndToEnd(t *testing.T) {
+	var (
+		geminiApi = testservers.NewGemini(t)
+		slackApi  = testservers.NewSlack(t)
+	)
+
+	// Request nonces only tick once per second,
+	// which bounds how quickly the poller can get through a series.
+	prices := []decimal.Decimal{
+		decimal.RequireFromString("1834.56"),
+		decimal.RequireFromString("1850.10"),
+	}
+
+	geminiApi.SetPrices("ETHUSD", prices...)
+
+	conf := poller.Config{
+		Gemini: &gemini.Config{
+			Auth:         &gemini.AuthConfig{ApiKey: "test-key", ApiSecret: "test-secret"},
+			BaseUrl:      geminiApi.URL(),
+			PollInterval: 20 * time.Millisecond,
+			Transport:    transport.Config{RateLimit: -1},
+			Notifications: gemini.NotificationsConfig{
+				SpotPrice: []gemini.SpotPriceNotificationsConfig{
+					{BaseCurrency: "eth", QuoteCurrency: "usd"},
+				},
+			},
+		},
+		Slack: &slack.Config{
+			Auth:     &slack.AuthConfig{Token: "xoxb-test"},
+			Channels: []string{"C0000000001"},
+			BaseUrl:  slackApi.URL(),
+		},
+	}
+
+	p, err := geminipoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.Start(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(slackApi.Messages()) >= len(prices)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	msgs := slackApi.Messages()
+	for i, price := range prices {
+		require.Len(t, msgs[i].Attachments, 1)
+		assert.Contains(t, msgs[i].Attachments[0].Text, price.String())
+	}
+
+	assert.GreaterOrEqual(t, p.Usage().Requests, len(prices))
+}
+
+func TestPoller_Start_Resume(t *testing.T) {
+	var (
+		geminiApi = testservers.NewGemini(t)
+		slackApi  = testservers.NewSlack(t)
+		price     = decimal.RequireFromString("29876.54")
+	)
+
+	geminiApi.SetPrices("BTCUSD", price)
+
+	// Stands in for a persistent cache written by a previous run.
+	cache, err := datastore.NewInMemoryDatastore[geminipoller.CacheEntry](nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, cache.Insert(ctx, "gemini:BTCUSD", geminipoller.CacheEntry{
+		SpotPrice:    gemini.SpotPriceNotificationsConfig{BaseCurrency: "btc", QuoteCurrency: "usd"},
+		PollInterval: 20 * time.Millisecond,
+	}))
+
+	// The restarted poller isn't told about the symbol.
+	conf := poller.Config{
+		Gemini: &gemini.Config{
+			Auth:         &gemini.AuthConfig{ApiKey: "test-key", ApiSecret: "test-secret"},
+			BaseUrl:      geminiApi.URL(),
+			PollInterval: time.Hour,
+			Transport:    transport.Config{RateLimit: -1},
+		},
+		Slack: &slack.Config{
+			Auth:     &slack.AuthConfig{Token: "xoxb-test"},
+			Channels: []string{"C0000000001"},
+			BaseUrl:  slackApi.URL(),
+		},
+		Resume: true,
+	}
+
+	p, err := geminipoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	p.SetDatastore(cache)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.Start(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(slackApi.Messages()) >= 1
+	}, 5*time.Second, 10*time.Millisecond, "resumed symbol should be polled at its stored interval")
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	msgs := slackApi.Messages()
+	require.Len(t, msgs[0].Attachments, 1)
+	assert.Contains(t, msgs[0].Attachments[0].Text, price.String())
+}
+
+func TestPoller_Start_Forget(t *testing.T) {
+	var (
+		geminiApi = testservers.NewGemini(t)
+		slackApi  = testservers.NewSlack(t)
+	)
+
+	geminiApi.SetPrices("BTCUSD", decimal.RequireFromString("29876.54"))
+
+	cache, err := datastore.NewInMemoryDatastore[geminipoller.CacheEntry](nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Written by a previous run whose config had a symbol which has since been removed.
+	require.NoError(t, cache.Insert(ctx, "gemini:BTCUSD", geminipoller.CacheEntry{
+		SpotPrice:    gemini.SpotPriceNotificationsConfig{BaseCurrency: "btc", QuoteCurrency: "usd"},
+		PollInterval: 20 * time.Millisecond,
+	}))
+
+	conf := poller.Config{
+		Gemini: &gemini.Config{
+			Auth:         &gemini.AuthConfig{ApiKey: "test-key", ApiSecret: "test-secret"},
+			BaseUrl:      geminiApi.URL(),
+			PollInterval: time.Hour,
+			Transport:    transport.Config{RateLimit: -1},
+			Forget:       []string{"btcusd"},
+		},
+		Slack: &slack.Config{
+			Auth:     &slack.AuthConfig{Token: "xoxb-test"},
+			Channels: []string{"C0000000001"},
+			BaseUrl:  slackApi.URL(),
+		},
+		Resume: true,
+	}
+
+	p, err := geminipoller.NewPoller(conf, nil)
+	require.NoError(t, err)
+
+	p.SetDatastore(cache)
+
+	// Nothing is left to poll once the symbol has been forgotten.
+	require.NoError(t, p.Start(ctx))
+
+	_, ok, err := cache.Get(ctx, "gemini:BTCUSD")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, slackApi.Messages())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
 	"github.com/jalavosus/stuffnotifier/pkg/authdata"
 	"github.com/jalavosus/stuffnotifier/pkg/gemini"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 )
 
 type Poller struct {
@@ -23,8 +25,9 @@ type Poller struct {
 
 func NewPoller(conf poller.Config, geminiConfig *gemini.Config) (*Poller, error) {
 	var (
-		geminiConf   = gemini.DefaultConfig()
-		datastoreErr error
+		geminiConf    = gemini.DefaultConfig()
+		basePollerErr error
+		datastoreErr  error
 	)
 
 	switch {
@@ -35,10 +38,14 @@ func NewPoller(conf poller.Config, geminiConfig *gemini.Config) (*Poller, error)
 	}
 
 	p := &Poller{
-		BasePoller:   poller.NewBasePoller(conf),
 		geminiConfig: *geminiConf,
 	}
 
+	p.BasePoller, basePollerErr = poller.NewBasePoller(conf)
+	if basePollerErr != nil {
+		return nil, basePollerErr
+	}
+
 	p.SetPollInterval(geminiConf.PollInterval)
 
 	p.datastore, datastoreErr = datastore.NewDatastore[CacheEntry](conf.Cache)
@@ -57,12 +64,44 @@ func (p *Poller) Datastore() datastore.Datastore[CacheEntry] {
 	return p.datastore
 }
 
+// SetDatastore sets the datastore which polled symbols are cached in,
+// ex. to share one between pollers.
+func (p *Poller) SetDatastore(ds datastore.Datastore[CacheEntry]) *Poller {
+	p.datastore = ds
+
+	return p
+}
+
 func (p *Poller) GeminiClient() *gemini.Client {
 	return p.geminiClient
 }
 
-func (p *Poller) initGeminiClient(authData authdata.AuthData) {
-	p.geminiClient = gemini.NewClient(authData)
+func (p *Poller) initGeminiClient(authData authdata.AuthData) error {
+	var (
+		geminiConf    = p.GeminiConfig()
+		transportConf = geminiConf.Transport
+	)
+
+	baseTransport, err := p.CassetteTransport(geminiConf.Cassette)
+	if err != nil {
+		return err
+	}
+
+	if geminiConf.Cassette.Mode == transport.CassetteReplay {
+		// Replayed responses don't count against Gemini's rate limits.
+		transportConf.RateLimit = -1
+	}
+
+	client, err := gemini.NewClient(authData).SetBaseUrl(geminiConf.BaseUrl)
+	if err != nil {
+		return errors.WithMessage(err, "invalid gemini config")
+	}
+
+	p.geminiClient = client.
+		SetTransportConfig(transportConf).
+		SetBaseTransport(baseTransport)
+
+	return nil
 }
 
 func (p *Poller) Start(ctx context.Context) error {
@@ -78,13 +117,42 @@ func (p *Poller) Start(ctx context.Context) error {
 		authData = ad
 	}
 
-	p.initGeminiClient(authData)
+	if err := p.initGeminiClient(authData); err != nil {
+		return err
+	}
+
+	defer p.logTotalUsage()
+
+	spotPriceConfs := p.GeminiConfig().Notifications.SpotPrice
+	forget := p.GeminiConfig().Forget
+
+	if err := p.forgetSpotPrices(ctx, forget); err != nil {
+		return errors.WithMessage(err, "error forgetting spot price pollers")
+	}
+
+	var resumed []resumedSpotPrice
+
+	if p.Resume() {
+		var resumeErr error
+
+		resumed, resumeErr = p.resumeSpotPrices(ctx, spotPriceConfs)
+		if resumeErr != nil {
+			return errors.WithMessage(resumeErr, "error resuming spot price pollers")
+		}
+	}
+
+	if len(spotPriceConfs) == 0 && len(resumed) == 0 {
+		if len(forget) > 0 {
+			return nil
+		}
+
+		return errors.New("no spot prices to poll")
+	}
 
 	errCh := make(chan error, 1)
 
-	for _, pollerConf := range p.GeminiConfig().Notifications.SpotPrice {
-		cacheKey := "gemini:" + pollerConf.CurrencySymbol()
-		concurrentParams := poller.NewConcurrentParamsWithChannel(authData, cacheKey, errCh)
+	for _, pollerConf := range spotPriceConfs {
+		concurrentParams := poller.NewConcurrentParamsWithChannel(authData, spotPriceCacheKey(pollerConf), errCh)
 
 		go p.pollSpotPrices(
 			ctx,
@@ -93,9 +161,40 @@ func (p *Poller) Start(ctx context.Context) error {
 		)
 	}
 
+	for _, spotPrice := range resumed {
+		concurrentParams := poller.NewConcurrentParamsWithChannel(authData, spotPrice.cacheKey, errCh)
+
+		go p.withStoredConfig(spotPrice.entry).pollSpotPrices(
+			ctx,
+			spotPrice.entry.SpotPrice,
+			concurrentParams,
+		)
+	}
+
 	return <-errCh
 }
 
+// Usage returns the number and cost of every Gemini API request sent by the poller.
+func (p *Poller) Usage() transport.UsageStats {
+	if p.geminiClient == nil {
+		return transport.UsageStats{}
+	}
+
+	return p.geminiClient.Usage()
+}
+
+// logTotalUsage logs the number and cost of every Gemini API request sent by the poller.
+func (p *Poller) logTotalUsage() {
+	stats := p.Usage()
+
+	p.LogInfo(
+		"total gemini api usage",
+		zap.Int("requests", stats.Requests),
+		zap.Int("retries", stats.Retries),
+		zap.Float64("cost", stats.Cost),
+	)
+}
+
 func (p *Poller) pollSpotPrices(
 	ctx context.Context,
 	pollerConf gemini.SpotPriceNotificationsConfig,
@@ -108,7 +207,7 @@ func (p *Poller) pollSpotPrices(
 	quoteCurrency := pollerConf.QuoteCurrency
 	baseAmt := pollerConf.BaseAmt()
 
-	ticker := time.NewTicker(p.PollInterval())
+	ticker := p.Clock().NewTicker(p.PollInterval())
 	cleanup := func(err error) {
 		pollerParams.Cleanup(err, ticker)
 	}
@@ -119,13 +218,24 @@ func (p *Poller) pollSpotPrices(
 		zap.String("check_interval", p.PollInterval().String()),
 	)
 
+	p.setCacheEntry(ctx, pollerParams.CacheKey, pollerConf)
+
 	for {
 		select {
 		case <-ctx.Done():
 			cleanup(nil)
 			return
-		case t := <-ticker.C:
+		case <-ticker.C():
+			// Refreshed on every poll, so that the entry outlives the datastore's TTL
+			// for as long as the symbol is polled.
+			p.setCacheEntry(ctx, pollerParams.CacheKey, pollerConf)
+
 			spotPrice, spotPriceErr := p.fetchSpotPrice(ctx, symbol)
+			if errors.Is(spotPriceErr, transport.ErrCassetteExhausted) {
+				cleanup(nil)
+				return
+			}
+
 			if spotPriceErr != nil {
 				p.LogError("error fetching spot price", zap.String("symbol", symbol), zap.Error(spotPriceErr))
 				continue
@@ -134,14 +244,16 @@ func (p *Poller) pollSpotPrices(
 			spotPrice = spotPrice.Mul(baseAmt)
 
 			msg := messages.SpotPriceAlert{
-				EventTime:     t,
+				EventTime:     p.Now(),
 				SpotPrice:     spotPrice,
 				BaseAmount:    baseAmt,
 				BaseCurrency:  baseCurrency,
 				QuoteCurrency: quoteCurrency,
 			}
 
-			if err := p.SendMessage(ctx, msg); err != nil {
+			msg.SetTemplates(p.Templates().Get(messages.SpotPriceTemplates))
+
+			if err := p.SendMessage(ctx, msg).Err(); err != nil {
 				p.LogError("error sending notification", zap.Error(err))
 			}
 		}
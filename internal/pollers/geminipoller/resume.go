@@ -0,0 +1,110 @@
+package geminipoller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/gemini"
+)
+
+// spotPriceCacheKeyPrefix is the prefix of the cache keys of polled symbols.
+const spotPriceCacheKeyPrefix = "gemini:"
+
+func spotPriceCacheKey(conf gemini.SpotPriceNotificationsConfig) string {
+	return spotPriceCacheKeyPrefix + conf.CurrencySymbol()
+}
+
+// resumedSpotPrice is a symbol which was being polled when a previous run stopped.
+type resumedSpotPrice struct {
+	entry    *CacheEntry
+	cacheKey string
+}
+
+// resumeSpotPrices returns the symbols in the datastore which were still being polled
+// when a previous run stopped, skipping those configured by the passed spot price configs.
+func (p *Poller) resumeSpotPrices(ctx context.Context, spotPriceConfs []gemini.SpotPriceNotificationsConfig) ([]resumedSpotPrice, error) {
+	skip := make(map[string]bool, len(spotPriceConfs))
+	for _, conf := range spotPriceConfs {
+		skip[spotPriceCacheKey(conf)] = true
+	}
+
+	var resumed []resumedSpotPrice
+
+	err := p.Datastore().Scan(ctx, spotPriceCacheKeyPrefix, func(key string, entry *CacheEntry) error {
+		// Entries written before the spot price config was stored can't be resumed.
+		if skip[key] || spotPriceCacheKey(entry.SpotPrice) != key {
+			return nil
+		}
+
+		p.LogInfo("resuming gemini spot price poller", zap.String("symbol", entry.SpotPrice.CurrencySymbol()))
+
+		resumed = append(resumed, resumedSpotPrice{entry: entry, cacheKey: key})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resumed, nil
+}
+
+// forgetSpotPrices removes the passed symbols from the datastore, so that they aren't resumed.
+func (p *Poller) forgetSpotPrices(ctx context.Context, symbols []string) error {
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+
+		if err := p.Datastore().Delete(ctx, spotPriceCacheKeyPrefix+symbol); err != nil {
+			return errors.WithMessagef(err, "symbol %[1]s", symbol)
+		}
+
+		p.LogInfo("forgot gemini spot price poller", zap.String("symbol", symbol))
+	}
+
+	return nil
+}
+
+// withStoredConfig returns a copy of the poller which polls the symbol in the passed cache entry
+// using the configuration it was stored with.
+// The copy shares the poller's Gemini client, notifiers, and datastore.
+func (p *Poller) withStoredConfig(entry *CacheEntry) *Poller {
+	var (
+		resumed    = *p
+		basePoller = *p.BasePoller
+	)
+
+	resumed.BasePoller = &basePoller
+	resumed.geminiConfig.Notifications = entry.Notifications
+
+	if entry.PollInterval > 0 {
+		resumed.geminiConfig.PollInterval = entry.PollInterval
+		resumed.SetPollInterval(entry.PollInterval)
+	}
+
+	return &resumed
+}
+
+// setCacheEntry stores the polled symbol's configuration in the datastore,
+// so that it can be resumed by a later run.
+func (p *Poller) setCacheEntry(ctx context.Context, cacheKey string, spotPriceConf gemini.SpotPriceNotificationsConfig) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	entry := CacheEntry{
+		PollerId:        p.PollerIdBytes(),
+		SymbolHash:      utils.SHA3(spotPriceConf.CurrencySymbol()),
+		RecipientConfig: p.BuildRecipientConfig(),
+		Notifications:   p.GeminiConfig().Notifications,
+		SpotPrice:       spotPriceConf,
+		PollInterval:    p.PollInterval(),
+	}
+
+	if err := p.Datastore().Insert(ctx, cacheKey, entry); err != nil {
+		p.LogError("error setting spot price poller in cache", zap.String("cache_key", cacheKey), zap.Error(err))
+	}
+}
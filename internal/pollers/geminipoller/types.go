@@ -12,5 +12,7 @@ type CacheEntry struct {
 	SymbolHash      []byte
 	RecipientConfig poller.RecipientConfig
 	Notifications   gemini.NotificationsConfig
-	PollInterval    time.Duration
+	// Configuration of the symbol's spot price notifications.
+	SpotPrice    gemini.SpotPriceNotificationsConfig
+	PollInterval time.Duration
 }
@@ -0,0 +1,42 @@
+package poller
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jalavosus/stuffnotifier/pkg/clock"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
+)
+
+// ReplayPollInterval is the poll interval used while replaying recorded API traffic.
+const ReplayPollInterval = 10 * time.Millisecond
+
+// CassetteTransport returns the http.RoundTripper which records API traffic to,
+// or replays it from, the configured cassette file, or nil if neither is configured.
+// While replaying, the poller's clock follows the recorded timestamps,
+// and it polls every ReplayPollInterval.
+func (p *BasePoller) CassetteTransport(conf transport.CassetteConfig) (http.RoundTripper, error) {
+	rt, err := transport.NewCassetteTransport(nil, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := rt.(type) {
+	case *transport.Replayer:
+		p.SetClock(clock.WithNow(p.Clock(), t.Now))
+		p.SetPollInterval(ReplayPollInterval)
+
+		p.LogInfo(
+			"replaying recorded api traffic",
+			zap.String("cassette", conf.Path),
+			zap.Int("interactions", t.Remaining()),
+			zap.Time("recorded_at", t.Now()),
+		)
+	case *transport.Recorder:
+		p.LogInfo("recording api traffic", zap.String("cassette", conf.Path))
+	}
+
+	return rt, nil
+}
@@ -8,24 +8,63 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/jalavosus/stuffnotifier/internal/datastore"
+	"github.com/jalavosus/stuffnotifier/internal/messages"
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/discord"
+	"github.com/jalavosus/stuffnotifier/pkg/email"
 	"github.com/jalavosus/stuffnotifier/pkg/errs"
 	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
 	"github.com/jalavosus/stuffnotifier/pkg/gemini"
 	"github.com/jalavosus/stuffnotifier/pkg/slack"
 	"github.com/jalavosus/stuffnotifier/pkg/twilio"
+	"github.com/jalavosus/stuffnotifier/pkg/webhook"
 )
 
 type Config struct {
-	Slack        *slack.Config       `json:"slack,omitempty" yaml:"slack,omitempty" toml:"Slack,omitempty"`
-	Cache        *datastore.Config   `json:"cache,omitempty" yaml:"cache,omitempty" toml:"Cache,omitempty"`
-	Gemini       *gemini.Config      `json:"gemini,omitempty" yaml:"gemini,omitempty" toml:"Gemini,omitempty"`
-	FlightAware  *flightaware.Config `json:"flightaware,omitempty" yaml:"flightaware,omitempty" toml:"FlightAware,omitempty"`
-	Twilio       *twilio.Config      `json:"twilio,omitempty" yaml:"twilio,omitempty" toml:"Twilio,omitempty"`
-	Discord      *discord.Config     `json:"discord,omitempty" yaml:"discord,omitempty" toml:"Discord,omitempty"`
-	PollInterval time.Duration       `json:"poll_interval" yaml:"poll_interval" toml:"PollInterval"`
-	LogStdout    bool                `json:"log_stdout" yaml:"log_stdout" toml:"LogStdout"`
+	Slack       *slack.Config       `json:"slack,omitempty" yaml:"slack,omitempty" toml:"Slack,omitempty"`
+	Cache       *datastore.Config   `json:"cache,omitempty" yaml:"cache,omitempty" toml:"Cache,omitempty"`
+	Gemini      *gemini.Config      `json:"gemini,omitempty" yaml:"gemini,omitempty" toml:"Gemini,omitempty"`
+	FlightAware *flightaware.Config `json:"flightaware,omitempty" yaml:"flightaware,omitempty" toml:"FlightAware,omitempty"`
+	Twilio      *twilio.Config      `json:"twilio,omitempty" yaml:"twilio,omitempty" toml:"Twilio,omitempty"`
+	Discord     *discord.Config     `json:"discord,omitempty" yaml:"discord,omitempty" toml:"Discord,omitempty"`
+	Email       *email.Config       `json:"email,omitempty" yaml:"email,omitempty" toml:"Email,omitempty"`
+	Webhook     *webhook.Config     `json:"webhook,omitempty" yaml:"webhook,omitempty" toml:"Webhook,omitempty"`
+	// Template files overriding the built-in message templates, keyed by template kind
+	// (see messages.TemplateKinds).
+	Templates messages.TemplatesConfig `json:"templates,omitempty" yaml:"templates,omitempty" toml:"Templates,omitempty"`
+	// Named groups of recipients, which can be notified
+	// in place of the notifiers above (ex. for individual flights).
+	Recipients   map[string]RecipientsConfig `json:"recipients,omitempty" yaml:"recipients,omitempty" toml:"Recipients,omitempty"`
+	PollInterval time.Duration               `json:"poll_interval" yaml:"poll_interval" toml:"PollInterval"`
+	LogStdout    bool                        `json:"log_stdout" yaml:"log_stdout" toml:"LogStdout"`
+	// If true, pollers resume polling everything which was being polled when a previous run stopped,
+	// using the configuration stored with it in the cache.
+	// Only useful with a persistent cache, ex. Redis.
+	Resume bool `json:"resume" yaml:"resume" toml:"Resume"`
+}
+
+// RecipientsConfig contains the notifier configuration for a named group of recipients.
+type RecipientsConfig struct {
+	Slack     *slack.Config   `json:"slack,omitempty" yaml:"slack,omitempty" toml:"Slack,omitempty"`
+	Twilio    *twilio.Config  `json:"twilio,omitempty" yaml:"twilio,omitempty" toml:"Twilio,omitempty"`
+	Discord   *discord.Config `json:"discord,omitempty" yaml:"discord,omitempty" toml:"Discord,omitempty"`
+	Email     *email.Config   `json:"email,omitempty" yaml:"email,omitempty" toml:"Email,omitempty"`
+	Webhook   *webhook.Config `json:"webhook,omitempty" yaml:"webhook,omitempty" toml:"Webhook,omitempty"`
+	LogStdout bool            `json:"log_stdout" yaml:"log_stdout" toml:"LogStdout"`
+}
+
+// withRecipients returns a copy of the Config with its notifier
+// configuration replaced by that of the passed RecipientsConfig.
+func (c Config) withRecipients(r RecipientsConfig) Config {
+	c.Slack = r.Slack
+	c.Twilio = r.Twilio
+	c.Discord = r.Discord
+	c.Email = r.Email
+	c.Webhook = r.Webhook
+	c.LogStdout = r.LogStdout
+	c.Recipients = nil
+
+	return c
 }
 
 // LoadConfig reads configuration data from the file at the passed path
@@ -0,0 +1,50 @@
+package poller_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+)
+
+func TestLoadConfig_FlightAwareNotificationDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+		data string
+	}{
+		{name: "json", ext: ".json", data: `{"flightaware": {"notifications": {"takeoff": true}}}`},
+		{name: "yaml", ext: ".yaml", data: "flightaware:\n  notifications:\n    takeoff: true\n"},
+		{name: "toml", ext: ".toml", data: "[FlightAware.Notifications]\nTakeoff = true\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			confPath := filepath.Join(t.TempDir(), "poller"+tt.ext)
+			require.NoError(t, os.WriteFile(confPath, []byte(tt.data), 0o600))
+
+			got, err := poller.LoadConfig(confPath)
+			require.NoError(t, err)
+			require.NotNil(t, got.FlightAware)
+
+			assert.True(t, got.FlightAware.Notifications.Takeoff)
+			assert.True(t, got.FlightAware.Notifications.GateChange)
+			assert.True(t, got.FlightAware.Notifications.Delay.Enabled)
+			assert.True(t, got.FlightAware.Notifications.InboundAircraft.Enabled)
+		})
+	}
+
+	// Pollers without a FlightAware config use flightaware.DefaultConfig.
+	confPath := filepath.Join(t.TempDir(), "poller.yaml")
+	require.NoError(t, os.WriteFile(confPath, []byte("log_stdout: true\n"), 0o600))
+
+	got, err := poller.LoadConfig(confPath)
+	require.NoError(t, err)
+	assert.Nil(t, got.FlightAware)
+}
@@ -0,0 +1,167 @@
+package poller
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+)
+
+// Notifier is implemented by anything which can deliver a messages.Message
+// to one or more recipients (SMS, chat, email, etc.).
+type Notifier interface {
+	// Name returns a short, unique name for the Notifier, ex. "twilio".
+	Name() string
+	// Send delivers the passed message to all recipients configured for the Notifier,
+	// returning a NotifierResult describing the outcome.
+	Send(ctx context.Context, msg messages.Message) NotifierResult
+	// Close releases any resources (connections, clients, etc.) held by the Notifier.
+	Close() error
+}
+
+// NotifierFactory builds a Notifier from a poller Config.
+// If the Notifier isn't configured in the passed Config,
+// NotifierFactory should return false (and a nil Notifier).
+type NotifierFactory func(conf Config) (Notifier, bool, error)
+
+// NotifierResult is the outcome of a single Notifier.Send call.
+type NotifierResult struct {
+	// Err is any error returned while sending.
+	// If a Notifier has multiple recipients, Err
+	// should hold the error(s) for all failed recipients.
+	Err error
+	// Notifier is the Name of the Notifier which produced the result.
+	Notifier string
+	// Sent is the number of recipients the message was successfully sent to.
+	Sent int
+}
+
+func (r NotifierResult) Ok() bool {
+	return r.Err == nil
+}
+
+// SendResult contains the result of every Notifier
+// called by BasePoller.SendMessage.
+type SendResult struct {
+	Results []NotifierResult
+}
+
+// Ok returns true if the message was delivered by at least one Notifier,
+// or if no notifiers are configured.
+// Partial delivery counts as sent: pollers record a message as sent once Ok returns true,
+// and don't retry it through the notifiers which failed (see Failed),
+// which should be logged individually by the caller.
+func (r SendResult) Ok() bool {
+	if len(r.Results) == 0 {
+		return true
+	}
+
+	for _, res := range r.Results {
+		if res.Sent > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Failed returns all results which contain an error.
+func (r SendResult) Failed() []NotifierResult {
+	var failed []NotifierResult
+
+	for _, res := range r.Results {
+		if !res.Ok() {
+			failed = append(failed, res)
+		}
+	}
+
+	return failed
+}
+
+// Err returns a single error containing the errors returned by
+// all failed notifiers, or nil if every notifier succeeded.
+func (r SendResult) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &NotifyError{Failed: failed}
+}
+
+// NotifyError is returned by SendResult.Err when one or more
+// notifiers failed to send a message.
+type NotifyError struct {
+	Failed []NotifierResult
+}
+
+func (e *NotifyError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, res := range e.Failed {
+		msgs[i] = errors.WithMessagef(res.Err, "notifier %[1]s", res.Notifier).Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+type pollerIdCtxKey struct{}
+
+// ContextWithPollerId returns a copy of the passed context carrying the passed poller ID,
+// allowing notifiers to identify which poller sent a message.
+func ContextWithPollerId(ctx context.Context, pollerId string) context.Context {
+	return context.WithValue(ctx, pollerIdCtxKey{}, pollerId)
+}
+
+// PollerIdFromContext returns the poller ID set by ContextWithPollerId, if any.
+func PollerIdFromContext(ctx context.Context) (string, bool) {
+	pollerId, ok := ctx.Value(pollerIdCtxKey{}).(string)
+	return pollerId, ok
+}
+
+var notifierRegistry = struct {
+	factories map[string]NotifierFactory
+	names     []string
+	mu        sync.RWMutex
+}{
+	factories: make(map[string]NotifierFactory),
+}
+
+// RegisterNotifier registers a NotifierFactory under the passed name.
+// Registered factories are called (in registration order) by BuildNotifiers.
+// Registering a factory using an already-registered name replaces
+// the existing factory.
+func RegisterNotifier(name string, factory NotifierFactory) {
+	notifierRegistry.mu.Lock()
+	defer notifierRegistry.mu.Unlock()
+
+	if _, ok := notifierRegistry.factories[name]; !ok {
+		notifierRegistry.names = append(notifierRegistry.names, name)
+	}
+
+	notifierRegistry.factories[name] = factory
+}
+
+// BuildNotifiers returns a Notifier for every registered
+// factory which is configured in the passed Config.
+func BuildNotifiers(conf Config) ([]Notifier, error) {
+	notifierRegistry.mu.RLock()
+	defer notifierRegistry.mu.RUnlock()
+
+	var notifiers []Notifier
+
+	for _, name := range notifierRegistry.names {
+		n, ok, err := notifierRegistry.factories[name](conf)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error building notifier %[1]s", name)
+		}
+
+		if ok {
+			notifiers = append(notifiers, n)
+		}
+	}
+
+	return notifiers, nil
+}
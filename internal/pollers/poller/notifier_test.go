@@ -0,0 +1,184 @@
+package poller_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/internal/pollers/poller"
+	"github.com/jalavosus/stuffnotifier/internal/testservers"
+)
+
+type testNotifier struct {
+	err      error
+	name     string
+	received []messages.Message
+}
+
+func (n *testNotifier) Name() string {
+	return n.name
+}
+
+func (n *testNotifier) Send(_ context.Context, msg messages.Message) poller.NotifierResult {
+	n.received = append(n.received, msg)
+
+	if n.err != nil {
+		return poller.NotifierResult{Notifier: n.name, Err: n.err}
+	}
+
+	return poller.NotifierResult{Notifier: n.name, Sent: 1}
+}
+
+func (n *testNotifier) Close() error {
+	return nil
+}
+
+func TestBasePoller_SendMessage(t *testing.T) {
+	var errSend = errors.New("send failed")
+
+	tests := []struct {
+		name       string
+		notifiers  []*testNotifier
+		wantFailed []string
+		wantOk     bool
+	}{
+		{
+			name:   "no notifiers",
+			wantOk: true,
+		},
+		{
+			name: "all succeed",
+			notifiers: []*testNotifier{
+				{name: "a"},
+				{name: "b"},
+			},
+			wantOk: true,
+		},
+		{
+			name: "first fails",
+			notifiers: []*testNotifier{
+				{name: "a", err: errSend},
+				{name: "b"},
+				{name: "c"},
+			},
+			wantFailed: []string{"a"},
+			wantOk:     true,
+		},
+		{
+			name: "all fail",
+			notifiers: []*testNotifier{
+				{name: "a", err: errSend},
+				{name: "b", err: errSend},
+			},
+			wantFailed: []string{"a", "b"},
+			wantOk:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := poller.NewBasePoller(poller.Config{})
+			assert.NoError(t, err)
+			assert.Empty(t, p.Notifiers())
+
+			for _, n := range tt.notifiers {
+				p.AddNotifier(n)
+			}
+
+			msg := testservers.PlainMessage("Hello, world!")
+
+			res := p.SendMessage(context.Background(), msg)
+
+			assert.Equal(t, tt.wantOk, res.Ok())
+			assert.Len(t, res.Results, len(tt.notifiers))
+
+			for _, n := range tt.notifiers {
+				assert.Lenf(t, n.received, 1, "expected notifier %[1]s to be called once", n.name)
+			}
+
+			failed := res.Failed()
+			failedNames := make([]string, len(failed))
+			for i := range failed {
+				failedNames[i] = failed[i].Notifier
+			}
+
+			if len(tt.wantFailed) == 0 {
+				assert.NoError(t, res.Err())
+				assert.Empty(t, failedNames)
+			} else {
+				assert.Error(t, res.Err())
+				assert.Equal(t, tt.wantFailed, failedNames)
+			}
+		})
+	}
+}
+
+func TestBuildNotifiers(t *testing.T) {
+	notifiers, err := poller.BuildNotifiers(poller.Config{})
+	assert.NoError(t, err)
+	assert.Empty(t, notifiers)
+
+	notifiers, err = poller.BuildNotifiers(poller.Config{LogStdout: true})
+	assert.NoError(t, err)
+
+	if assert.Len(t, notifiers, 1) {
+		assert.Equal(t, poller.StdoutNotifierName, notifiers[0].Name())
+	}
+}
+
+func TestBasePoller_SendMessageTo(t *testing.T) {
+	p, err := poller.NewBasePoller(poller.Config{
+		Recipients: map[string]poller.RecipientsConfig{
+			"ops": {LogStdout: true},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, p.HasRecipients("ops"))
+	assert.False(t, p.HasRecipients("travelers"))
+
+	tests := []struct {
+		name        string
+		recipients  []string
+		wantResults []string
+		wantOk      bool
+	}{
+		{
+			name:   "default notifiers",
+			wantOk: true,
+		},
+		{
+			name:        "recipient group",
+			recipients:  []string{"ops"},
+			wantResults: []string{poller.StdoutNotifierName},
+			wantOk:      true,
+		},
+		{
+			name:        "unknown recipient group",
+			recipients:  []string{"travelers"},
+			wantResults: []string{"recipients:travelers"},
+			wantOk:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			res := p.SendMessageTo(context.Background(), testservers.PlainMessage("Hello, world!"), tt.recipients...)
+
+			names := make([]string, 0, len(res.Results))
+			for _, r := range res.Results {
+				names = append(names, r.Notifier)
+			}
+
+			assert.ElementsMatch(t, tt.wantResults, names)
+			assert.Equal(t, tt.wantOk, res.Ok())
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/discord"
+	"github.com/jalavosus/stuffnotifier/pkg/email"
+	"github.com/jalavosus/stuffnotifier/pkg/slack"
+	"github.com/jalavosus/stuffnotifier/pkg/twilio"
+	"github.com/jalavosus/stuffnotifier/pkg/webhook"
+)
+
+const (
+	notifierSendTimeout = 10 * time.Second
+)
+
+const (
+	StdoutNotifierName  string = "stdout"
+	TwilioNotifierName  string = "twilio"
+	SlackNotifierName   string = "slack"
+	DiscordNotifierName string = "discord"
+	EmailNotifierName   string = "email"
+	WebhookNotifierName string = "webhook"
+)
+
+func init() {
+	RegisterNotifier(StdoutNotifierName, newStdoutNotifier)
+	RegisterNotifier(TwilioNotifierName, newTwilioNotifier)
+	RegisterNotifier(SlackNotifierName, newSlackNotifier)
+	RegisterNotifier(DiscordNotifierName, newDiscordNotifier)
+	RegisterNotifier(EmailNotifierName, newEmailNotifier)
+	RegisterNotifier(WebhookNotifierName, newWebhookNotifier)
+}
+
+// joinRecipientErrors combines errors returned while sending
+// a message to multiple recipients into a single error.
+func joinRecipientErrors(recipientErrs map[string]error) error {
+	if len(recipientErrs) == 0 {
+		return nil
+	}
+
+	recipients := make([]string, 0, len(recipientErrs))
+	for recipient := range recipientErrs {
+		recipients = append(recipients, recipient)
+	}
+
+	sort.Strings(recipients)
+
+	msgs := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		msgs[i] = errors.WithMessagef(recipientErrs[recipient], "recipient %[1]s", recipient).Error()
+	}
+
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+type stdoutNotifier struct{}
+
+func newStdoutNotifier(conf Config) (Notifier, bool, error) {
+	if !conf.LogStdout {
+		return nil, false, nil
+	}
+
+	return stdoutNotifier{}, true, nil
+}
+
+func (stdoutNotifier) Name() string {
+	return StdoutNotifierName
+}
+
+func (stdoutNotifier) Send(_ context.Context, msg messages.Message) NotifierResult {
+	fmt.Println(msg.FormatPlaintext())
+
+	return NotifierResult{Notifier: StdoutNotifierName, Sent: 1}
+}
+
+func (stdoutNotifier) Close() error {
+	return nil
+}
+
+type twilioNotifier struct {
+	config *twilio.Config
+}
+
+func newTwilioNotifier(conf Config) (Notifier, bool, error) {
+	if conf.Twilio == nil {
+		return nil, false, nil
+	}
+
+	return &twilioNotifier{config: conf.Twilio}, true, nil
+}
+
+func (n *twilioNotifier) Name() string {
+	return TwilioNotifierName
+}
+
+func (n *twilioNotifier) Send(ctx context.Context, msg messages.Message) NotifierResult {
+	res := NotifierResult{Notifier: n.Name()}
+
+	ctx, cancel := context.WithTimeout(ctx, notifierSendTimeout)
+	defer cancel()
+
+	client, clientErr := twilio.ClientSingleton(n.config)
+	if clientErr != nil {
+		res.Err = clientErr
+		return res
+	}
+
+	if _, err := client.Client().SendMessage(ctx, msg, n.config.RecipientNumber); err != nil {
+		res.Err = err
+		return res
+	}
+
+	res.Sent = 1
+
+	return res
+}
+
+func (n *twilioNotifier) Close() error {
+	return nil
+}
+
+type slackNotifier struct {
+	config *slack.Config
+	client *slack.Client
+	mu     sync.Mutex
+}
+
+func newSlackNotifier(conf Config) (Notifier, bool, error) {
+	if conf.Slack == nil {
+		return nil, false, nil
+	}
+
+	return &slackNotifier{config: conf.Slack}, true, nil
+}
+
+func (n *slackNotifier) Name() string {
+	return SlackNotifierName
+}
+
+func (n *slackNotifier) Send(ctx context.Context, msg messages.Message) NotifierResult {
+	res := NotifierResult{Notifier: n.Name()}
+
+	client, clientErr := n.slackClient()
+	if clientErr != nil {
+		res.Err = clientErr
+		return res
+	}
+
+	recipientErrs := make(map[string]error)
+
+	for _, id := range utils.AppendSlices(n.config.Channels, n.config.Users) {
+		if err := n.sendMsg(ctx, msg, id, client); err != nil {
+			recipientErrs[id] = err
+			continue
+		}
+
+		res.Sent++
+	}
+
+	res.Err = joinRecipientErrors(recipientErrs)
+
+	return res
+}
+
+func (n *slackNotifier) sendMsg(ctx context.Context, msg messages.Message, recipientId string, client *slack.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, notifierSendTimeout)
+	defer cancel()
+
+	return client.SendMessage(ctx, msg, recipientId)
+}
+
+// slackClient lazily initializes the notifier's slack.Client,
+// as slack.NewClient performs an API call.
+func (n *slackNotifier) slackClient() (*slack.Client, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.client != nil {
+		return n.client, nil
+	}
+
+	client, err := slack.NewClient(n.config)
+	if err != nil {
+		return nil, err
+	}
+
+	n.client = client
+
+	return client, nil
+}
+
+func (n *slackNotifier) Close() error {
+	return nil
+}
+
+type discordNotifier struct {
+	config *discord.Config
+	client *discord.Client
+}
+
+func newDiscordNotifier(conf Config) (Notifier, bool, error) {
+	if conf.Discord == nil || !(conf.Discord.UsesBot() || conf.Discord.UsesWebhook()) {
+		return nil, false, nil
+	}
+
+	client, err := discord.NewClient(conf.Discord)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &discordNotifier{config: conf.Discord, client: client}, true, nil
+}
+
+func (n *discordNotifier) Name() string {
+	return DiscordNotifierName
+}
+
+func (n *discordNotifier) Send(ctx context.Context, msg messages.Message) NotifierResult {
+	res := NotifierResult{Notifier: n.Name()}
+
+	recipientErrs := make(map[string]error)
+
+	send := func(recipient string, sendFn func(ctx context.Context) error) {
+		ctx, cancel := context.WithTimeout(ctx, notifierSendTimeout)
+		defer cancel()
+
+		if err := sendFn(ctx); err != nil {
+			recipientErrs[recipient] = err
+			return
+		}
+
+		res.Sent++
+	}
+
+	if channelId, _ := utils.FromPointer(n.config.ChannelId); channelId != "" {
+		send("channel:"+channelId, func(ctx context.Context) error {
+			_, err := n.client.SendMessage(ctx, msg, channelId)
+			return err
+		})
+	}
+
+	if userId, _ := utils.FromPointer(n.config.UserId); userId != "" {
+		send("user:"+userId, func(ctx context.Context) error {
+			_, err := n.client.SendDirectMessage(ctx, msg, userId)
+			return err
+		})
+	}
+
+	if n.config.UsesWebhook() {
+		send("webhook", func(ctx context.Context) error {
+			return n.client.SendWebhookMessage(ctx, msg)
+		})
+	}
+
+	res.Err = joinRecipientErrors(recipientErrs)
+
+	return res
+}
+
+func (n *discordNotifier) Close() error {
+	return nil
+}
+
+type emailNotifier struct {
+	client     *email.Client
+	recipients int
+}
+
+func newEmailNotifier(conf Config) (Notifier, bool, error) {
+	if conf.Email == nil {
+		return nil, false, nil
+	}
+
+	client, err := email.NewClient(conf.Email)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &emailNotifier{client: client, recipients: len(conf.Email.Recipients())}, true, nil
+}
+
+func (n *emailNotifier) Name() string {
+	return EmailNotifierName
+}
+
+func (n *emailNotifier) Send(ctx context.Context, msg messages.Message) NotifierResult {
+	res := NotifierResult{Notifier: n.Name()}
+
+	ctx, cancel := context.WithTimeout(ctx, notifierSendTimeout)
+	defer cancel()
+
+	if err := n.client.SendMessage(ctx, msg); err != nil {
+		res.Err = err
+		return res
+	}
+
+	res.Sent = n.recipients
+
+	return res
+}
+
+func (n *emailNotifier) Close() error {
+	return nil
+}
+
+type webhookNotifier struct {
+	client *webhook.Client
+}
+
+func newWebhookNotifier(conf Config) (Notifier, bool, error) {
+	if conf.Webhook == nil {
+		return nil, false, nil
+	}
+
+	client, err := webhook.NewClient(conf.Webhook)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &webhookNotifier{client: client}, true, nil
+}
+
+func (n *webhookNotifier) Name() string {
+	return WebhookNotifierName
+}
+
+// Send doesn't apply notifierSendTimeout, as the webhook.Client
+// applies per-endpoint timeouts (and retries) itself.
+func (n *webhookNotifier) Send(ctx context.Context, msg messages.Message) NotifierResult {
+	pollerId, _ := PollerIdFromContext(ctx)
+
+	sent, endpointErrs := n.client.SendMessage(ctx, msg, pollerId)
+
+	return NotifierResult{
+		Notifier: n.Name(),
+		Sent:     sent,
+		Err:      joinRecipientErrors(endpointErrs),
+	}
+}
+
+func (n *webhookNotifier) Close() error {
+	return nil
+}
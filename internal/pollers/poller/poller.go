@@ -2,14 +2,14 @@ package poller
 
 import (
 	"context"
-	"fmt"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rs/xid"
 	"go.uber.org/zap"
 
 	"github.com/jalavosus/stuffnotifier/internal/messages"
-	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/clock"
 	"github.com/jalavosus/stuffnotifier/pkg/discord"
 	"github.com/jalavosus/stuffnotifier/pkg/slack"
 	"github.com/jalavosus/stuffnotifier/pkg/twilio"
@@ -17,20 +17,46 @@ import (
 
 type BasePoller struct {
 	logger       *zap.Logger
+	templates    *messages.TemplateSet
+	notifiers    []Notifier
+	recipients   map[string][]Notifier
+	clock        clock.Clock
 	config       Config
 	pollInterval time.Duration
 	pollerId     xid.ID
 }
 
-func NewBasePoller(conf Config) *BasePoller {
+func NewBasePoller(conf Config) (*BasePoller, error) {
+	templates, err := messages.LoadTemplateSet(conf.Templates)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error loading message templates")
+	}
+
+	notifiers, err := BuildNotifiers(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := make(map[string][]Notifier, len(conf.Recipients))
+	for name, recipientsConf := range conf.Recipients {
+		recipients[name], err = BuildNotifiers(conf.withRecipients(recipientsConf))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error building notifiers for recipients %[1]s", name)
+		}
+	}
+
 	p := &BasePoller{
 		pollerId:     xid.NewWithTime(time.Now()),
 		pollInterval: DefaultPollInterval,
+		clock:        clock.Real(),
 		config:       conf,
 		logger:       newLogger(),
+		notifiers:    notifiers,
+		recipients:   recipients,
+		templates:    templates,
 	}
 
-	return p
+	return p, nil
 }
 
 func (p *BasePoller) PollerId() string {
@@ -51,6 +77,53 @@ func (p *BasePoller) SetPollInterval(pollInterval time.Duration) *BasePoller {
 	return p
 }
 
+// Clock returns the clock used to time polling and notifications,
+// which is the system clock unless set with SetClock.
+func (p *BasePoller) Clock() clock.Clock {
+	return p.clock
+}
+
+// SetClock sets the clock used to time polling and notifications.
+// A nil clock resets it to the system clock.
+func (p *BasePoller) SetClock(c clock.Clock) *BasePoller {
+	if c == nil {
+		c = clock.Real()
+	}
+
+	p.clock = c
+
+	return p
+}
+
+// Now returns the current time according to the poller's clock.
+func (p *BasePoller) Now() time.Time {
+	return p.clock.Now()
+}
+
+// Templates returns the message templates used by the poller,
+// which include any user-supplied templates.
+func (p *BasePoller) Templates() *messages.TemplateSet {
+	return p.templates
+}
+
+// Notifiers returns the notifiers used by SendMessage.
+func (p *BasePoller) Notifiers() []Notifier {
+	return p.notifiers
+}
+
+// AddNotifier adds a Notifier to the list of notifiers used by SendMessage.
+func (p *BasePoller) AddNotifier(n Notifier) *BasePoller {
+	p.notifiers = append(p.notifiers, n)
+
+	return p
+}
+
+// Resume returns true if the poller should resume polling
+// everything stored in its cache by a previous run (see Config.Resume).
+func (p *BasePoller) Resume() bool {
+	return p.config.Resume
+}
+
 func (p *BasePoller) LogStdout() bool {
 	return p.config.LogStdout
 }
@@ -67,68 +140,80 @@ func (p *BasePoller) SlackConfig() *slack.Config {
 	return p.config.Slack
 }
 
-func (p *BasePoller) SendMessage(ctx context.Context, msg messages.Message) error {
-	if p.LogStdout() {
-		fmt.Println(msg.FormatPlaintext())
-	}
+// SendMessage sends the passed message using every configured Notifier.
+// Every Notifier is attempted, regardless of whether a previous Notifier failed;
+// the returned SendResult contains the outcome for each.
+// The BasePoller's ID is passed to notifiers via the context (see PollerIdFromContext).
+func (p *BasePoller) SendMessage(ctx context.Context, msg messages.Message) SendResult {
+	return p.sendMessage(ctx, msg, p.notifiers)
+}
 
-	if p.TwilioConfig() != nil {
-		if err := p.sendTwilio(ctx, msg); err != nil {
-			return err
-		}
+// SendMessageTo sends the passed message using the notifiers of the passed
+// recipient groups (see Config.Recipients).
+// If no recipient groups are passed, it behaves the same as SendMessage.
+func (p *BasePoller) SendMessageTo(ctx context.Context, msg messages.Message, recipients ...string) SendResult {
+	if len(recipients) == 0 {
+		return p.SendMessage(ctx, msg)
 	}
 
-	if p.DiscordConfig() != nil {
-		// TODO
-	}
+	var (
+		notifiers []Notifier
+		unknown   []NotifierResult
+	)
 
-	if p.SlackConfig() != nil {
-		if err := p.sendSlack(ctx, msg); err != nil {
-			return err
+	for _, name := range recipients {
+		recipientNotifiers, ok := p.recipients[name]
+		if !ok {
+			unknown = append(unknown, NotifierResult{
+				Notifier: "recipients:" + name,
+				Err:      errors.Errorf("unknown recipients %[1]s", name),
+			})
+
+			continue
 		}
+
+		notifiers = append(notifiers, recipientNotifiers...)
 	}
 
-	return nil
+	res := p.sendMessage(ctx, msg, notifiers)
+	res.Results = append(res.Results, unknown...)
+
+	return res
 }
 
-func (p *BasePoller) sendTwilio(ctx context.Context, msg messages.Message) error {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+// HasRecipients returns true if a recipient group with the passed name is configured.
+func (p *BasePoller) HasRecipients(name string) bool {
+	_, ok := p.recipients[name]
+	return ok
+}
 
-	client, clientErr := twilio.ClientSingleton(p.TwilioConfig())
-	if clientErr != nil {
-		return clientErr
-	}
+func (p *BasePoller) sendMessage(ctx context.Context, msg messages.Message, notifiers []Notifier) SendResult {
+	ctx = ContextWithPollerId(ctx, p.PollerId())
 
-	if _, err := client.Client().SendMessage(ctx, msg, p.TwilioConfig().RecipientNumber); err != nil {
-		return err
+	res := SendResult{Results: make([]NotifierResult, len(notifiers))}
+
+	for i, n := range notifiers {
+		res.Results[i] = n.Send(ctx, msg)
 	}
 
-	return nil
+	return res
 }
 
-func (p *BasePoller) sendSlack(ctx context.Context, msg messages.Message) error {
-	slackConf := p.SlackConfig()
+// Close closes all of the BasePoller's notifiers.
+func (p *BasePoller) Close() error {
+	var closeErr error
 
-	client, clientErr := slack.NewClient(slackConf)
-	if clientErr != nil {
-		return clientErr
+	allNotifiers := append([]Notifier{}, p.notifiers...)
+	for _, recipientNotifiers := range p.recipients {
+		allNotifiers = append(allNotifiers, recipientNotifiers...)
 	}
 
-	recipientIds := utils.AppendSlices(slackConf.Channels, slackConf.Users)
-
-	for _, id := range recipientIds {
-		if err := p.sendSlackMsg(ctx, msg, id, client); err != nil {
-			return err
+	for _, n := range allNotifiers {
+		if err := n.Close(); err != nil {
+			p.LogError("error closing notifier", zap.String("notifier", n.Name()), zap.Error(err))
+			closeErr = err
 		}
 	}
 
-	return nil
-}
-
-func (p *BasePoller) sendSlackMsg(ctx context.Context, msg messages.Message, recipientId string, client *slack.Client) error {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
-	return client.SendMessage(ctx, msg, recipientId)
+	return closeErr
 }
@@ -2,10 +2,10 @@ package poller
 
 import (
 	"sync"
-	"time"
 
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/authdata"
+	"github.com/jalavosus/stuffnotifier/pkg/clock"
 )
 
 type RecipientConfig struct {
@@ -34,6 +34,10 @@ func (p BasePoller) BuildRecipientConfig() RecipientConfig {
 			d.UseDiscord = true
 			d.DiscordRecipientChannelId = channelId
 		}
+
+		if p.DiscordConfig().UsesWebhook() {
+			d.UseDiscord = true
+		}
 	}
 
 	return d
@@ -64,7 +68,7 @@ func NewConcurrentParamsWithChannel(authData authdata.AuthData, cacheKey string,
 	return p
 }
 
-func (p *ConcurrentParams) Cleanup(err error, ticker *time.Ticker) {
+func (p *ConcurrentParams) Cleanup(err error, ticker clock.Ticker) {
 	ticker.Stop()
 	p.ErrCh <- err
 }
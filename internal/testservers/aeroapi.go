@@ -0,0 +1,283 @@
+package testservers
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+const (
+	aeroApiPathPrefix = "/aeroapi"
+	aeroApiTimeFormat = "2006-01-02T15:04:05Z"
+	aeroApiKeyHeader  = "x-apikey"
+)
+
+// AeroAPI is a fake of the FlightAware AeroAPI endpoints used by this project,
+// serving scripted flights (see FlightTimeline) and airports.
+// Requests without an API key are rejected.
+type AeroAPI struct {
+	url      string
+	airports map[string]flightaware.AirportData
+	flights  []*FlightTimeline
+	failures []int
+	requests int
+	mu       sync.Mutex
+}
+
+// NewAeroAPI starts a fake AeroAPI, which is closed once the test finishes.
+func NewAeroAPI(t testing.TB) *AeroAPI {
+	t.Helper()
+
+	a := &AeroAPI{
+		airports: make(map[string]flightaware.AirportData),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(aeroApiPathPrefix+"/flights/", a.handleFlights)
+	mux.HandleFunc(aeroApiPathPrefix+"/airports/", a.handleAirports)
+
+	server := newServer(t, a.withMiddleware(mux))
+	a.url = server.URL + aeroApiPathPrefix
+
+	return a
+}
+
+// URL returns the base URL of the fake AeroAPI, to be used as a FlightAware client's base URL.
+func (a *AeroAPI) URL() string {
+	return a.url
+}
+
+// AddFlight adds a scripted flight, which is served when queried
+// by its FlightAware flight ID, flight number, or registration.
+func (a *AeroAPI) AddFlight(timeline *FlightTimeline) *AeroAPI {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.flights = append(a.flights, timeline)
+
+	return a
+}
+
+// AddAirport adds an airport, which is served when queried by any of its identifiers.
+func (a *AeroAPI) AddAirport(airport flightaware.AirportData) *AeroAPI {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, id := range []string{airport.Identifiers.Code, airport.Identifiers.ICAO, airport.Identifiers.IATA, airport.Identifiers.LID} {
+		if id != "" {
+			a.airports[strings.ToUpper(id)] = airport
+		}
+	}
+
+	return a
+}
+
+// FailNext makes the next requests fail with the passed status codes, in order.
+func (a *AeroAPI) FailNext(statusCodes ...int) *AeroAPI {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.failures = append(a.failures, statusCodes...)
+
+	return a
+}
+
+// Requests returns the number of requests the fake has received.
+func (a *AeroAPI) Requests() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.requests
+}
+
+func (a *AeroAPI) withMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		a.requests++
+
+		var failure int
+		if len(a.failures) > 0 {
+			failure, a.failures = a.failures[0], a.failures[1:]
+		}
+		a.mu.Unlock()
+
+		switch {
+		case r.Header.Get(aeroApiKeyHeader) == "":
+			writeAeroApiError(w, http.StatusUnauthorized, "missing API key")
+		case failure != 0:
+			writeAeroApiError(w, failure, "scripted failure")
+		case r.Method != http.MethodGet:
+			writeAeroApiError(w, http.StatusMethodNotAllowed, "method not allowed")
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func (a *AeroAPI) handleFlights(w http.ResponseWriter, r *http.Request) {
+	var (
+		ident     = strings.TrimPrefix(r.URL.Path, aeroApiPathPrefix+"/flights/")
+		identType = r.URL.Query().Get("ident_type")
+		flights   = make([]map[string]any, 0)
+	)
+
+	a.mu.Lock()
+	timelines := append([]*FlightTimeline(nil), a.flights...)
+	a.mu.Unlock()
+
+	for _, timeline := range timelines {
+		if !matchesIdent(timeline.Current(), ident, identType) {
+			continue
+		}
+
+		flights = append(flights, encodeAeroApiFlight(timeline.serve()))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"links":     nil,
+		"num_pages": 1,
+		"flights":   flights,
+	})
+}
+
+func (a *AeroAPI) handleAirports(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, aeroApiPathPrefix+"/airports/")
+
+	a.mu.Lock()
+	airport, ok := a.airports[strings.ToUpper(id)]
+	a.mu.Unlock()
+
+	if !ok {
+		writeAeroApiError(w, http.StatusNotFound, "unknown airport "+id)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, encodeAeroApiAirport(airport))
+}
+
+func matchesIdent(flight flightaware.FlightData, ident, identType string) bool {
+	switch identType {
+	case flightaware.FaFlightIdIdent.String():
+		return flight.FlightId == ident
+	case flightaware.RegistrationIdent.String():
+		return strings.EqualFold(flight.Registration, ident)
+	default:
+		return strings.EqualFold(flight.Identifiers.Identifier, ident) ||
+			strings.EqualFold(flight.Identifiers.ICAO, ident) ||
+			strings.EqualFold(flight.Identifiers.IATA, ident) ||
+			flight.FlightId == ident
+	}
+}
+
+func writeAeroApiError(w http.ResponseWriter, statusCode int, detail string) {
+	writeJSON(w, statusCode, map[string]any{
+		"title":  http.StatusText(statusCode),
+		"reason": strings.ToUpper(strings.ReplaceAll(http.StatusText(statusCode), " ", "_")),
+		"detail": detail,
+		"status": statusCode,
+	})
+}
+
+// encodeAeroApiFlight encodes flight data in AeroAPI's format.
+func encodeAeroApiFlight(flight flightaware.FlightData) map[string]any {
+	encodeAirport := func(data flightaware.FlightOriginDestinationData) map[string]any {
+		return map[string]any{
+			"code":             data.Identifiers.Code,
+			"code_icao":        data.Identifiers.ICAO,
+			"code_iata":        data.Identifiers.IATA,
+			"code_lid":         data.Identifiers.LID,
+			"airport_info_url": data.InfoUrl,
+		}
+	}
+
+	return map[string]any{
+		"fa_flight_id":         flight.FlightId,
+		"ident":                flight.Identifiers.Identifier,
+		"ident_icao":           flight.Identifiers.ICAO,
+		"ident_iata":           flight.Identifiers.IATA,
+		"atc_ident":            nullableString(flight.AtcIdent),
+		"flight_number":        flight.FlightNumber,
+		"registration":         flight.Registration,
+		"inbound_fa_flight_id": flight.InboundFlightId,
+		"operator":             flight.Operator.Operator,
+		"operator_icao":        flight.Operator.ICAO,
+		"operator_iata":        flight.Operator.IATA,
+		"codeshares":           flight.Codeshares,
+		"codeshares_iata":      flight.CodesharesIata,
+		"blocked":              flight.Blocked,
+		"diverted":             flight.Diverted,
+		"cancelled":            flight.Cancelled,
+		"position_only":        flight.PositionOnly,
+		"origin":               encodeAirport(flight.Origin),
+		"destination":          encodeAirport(flight.Destination),
+		"departure_delay":      int64(flight.DepartureDelay / time.Second),
+		"arrival_delay":        int64(flight.ArrivalDelay / time.Second),
+		"flight_ete":           int64(flight.FlightTime / time.Second),
+		"scheduled_out":        aeroApiTime(flight.GateDepartureTime.Scheduled),
+		"estimated_out":        aeroApiTime(flight.GateDepartureTime.Estimated),
+		"actual_out":           aeroApiTime(flight.GateDepartureTime.Actual),
+		"scheduled_off":        aeroApiTime(flight.RunwayDepartureTime.Scheduled),
+		"estimated_off":        aeroApiTime(flight.RunwayDepartureTime.Estimated),
+		"actual_off":           aeroApiTime(flight.RunwayDepartureTime.Actual),
+		"scheduled_on":         aeroApiTime(flight.RunwayArrivalTime.Scheduled),
+		"estimated_on":         aeroApiTime(flight.RunwayArrivalTime.Estimated),
+		"actual_on":            aeroApiTime(flight.RunwayArrivalTime.Actual),
+		"scheduled_in":         aeroApiTime(flight.GateArrivalTime.Scheduled),
+		"estimated_in":         aeroApiTime(flight.GateArrivalTime.Estimated),
+		"actual_in":            aeroApiTime(flight.GateArrivalTime.Actual),
+		"flight_progress":      flight.FlightProgress,
+		"status":               flight.Status,
+		"aircraft_type":        flight.AircraftType,
+		"route_distance":       flight.RouteDistance,
+		"filed_airspeed":       flight.FiledAirspeed,
+		"filed_altitude":       flight.FiledAltitude,
+		"route":                flight.Route,
+		"baggage_claim":        nullableString(flight.BaggageClaim),
+		"gate_origin":          flight.Origin.Gate,
+		"gate_destination":     nullableString(flight.Destination.Gate),
+		"terminal_origin":      flight.Origin.Terminal,
+		"terminal_destination": nullableString(flight.Destination.Terminal),
+		"type":                 flight.FlightType.String(),
+	}
+}
+
+// encodeAeroApiAirport encodes airport data in AeroAPI's format.
+func encodeAeroApiAirport(airport flightaware.AirportData) map[string]any {
+	return map[string]any{
+		"airport_code": airport.Identifiers.Code,
+		"code_icao":    airport.Identifiers.ICAO,
+		"code_iata":    airport.Identifiers.IATA,
+		"code_lid":     airport.Identifiers.LID,
+		"name":         airport.Name,
+		"type":         airport.AirportType.String(),
+		"elevation":    airport.Elevation,
+		"city":         airport.City,
+		"state":        airport.State,
+		"longitude":    airport.Longitude,
+		"latitude":     airport.Latitude,
+		"timezone":     airport.Timezone,
+		"country_code": airport.CountryCode,
+	}
+}
+
+func aeroApiTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+
+	formatted := t.UTC().Format(aeroApiTimeFormat)
+
+	return &formatted
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
@@ -0,0 +1,226 @@
+package testservers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Gemini is a fake of the Gemini public market data endpoints used by this project,
+// serving scripted price series.
+type Gemini struct {
+	series map[string]*priceSeries
+	url    string
+	mu     sync.Mutex
+}
+
+// priceSeries is a scripted series of prices for a single symbol.
+// Every ticker request for the symbol is served the next price in the series.
+type priceSeries struct {
+	prices   []decimal.Decimal
+	requests int
+}
+
+// NewGemini starts a fake Gemini API, which is closed once the test finishes.
+func NewGemini(t testing.TB) *Gemini {
+	t.Helper()
+
+	g := &Gemini{
+		series: make(map[string]*priceSeries),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/symbols", g.handleSymbols)
+	mux.HandleFunc("/v1/symbols/details/", g.handleSymbolDetails)
+	mux.HandleFunc("/v1/pubticker/", g.handleTicker)
+	mux.HandleFunc("/v2/ticker/", g.handleTickerV2)
+	mux.HandleFunc("/v1/pricefeed", g.handlePriceFeed)
+
+	g.url = newServer(t, mux).URL
+
+	return g
+}
+
+// URL returns the base URL of the fake Gemini API, to be used as a Gemini client's base URL.
+func (g *Gemini) URL() string {
+	return g.url
+}
+
+// SetPrices sets the price series of the passed symbol (ex. ETHUSD).
+// Each ticker request for the symbol is served the next price in the series,
+// and the last price is served once the series has been exhausted.
+func (g *Gemini) SetPrices(symbol string, prices ...decimal.Decimal) *Gemini {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(prices) == 0 {
+		delete(g.series, strings.ToUpper(symbol))
+		return g
+	}
+
+	g.series[strings.ToUpper(symbol)] = &priceSeries{prices: prices}
+
+	return g
+}
+
+// Requests returns the number of ticker requests received for the passed symbol.
+func (g *Gemini) Requests(symbol string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if series, ok := g.series[strings.ToUpper(symbol)]; ok {
+		return series.requests
+	}
+
+	return 0
+}
+
+// nextPrice returns the next price in the passed symbol's series,
+// or false if the symbol has no series.
+func (g *Gemini) nextPrice(symbol string) (decimal.Decimal, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	series, ok := g.series[strings.ToUpper(symbol)]
+	if !ok {
+		return decimal.Zero, false
+	}
+
+	price := nextInSeries(series.prices, series.requests)
+	series.requests++
+
+	return price, true
+}
+
+func (g *Gemini) handleSymbols(w http.ResponseWriter, _ *http.Request) {
+	g.mu.Lock()
+	symbols := make([]string, 0, len(g.series))
+	for symbol := range g.series {
+		symbols = append(symbols, strings.ToLower(symbol))
+	}
+	g.mu.Unlock()
+
+	sort.Strings(symbols)
+
+	writeJSON(w, http.StatusOK, symbols)
+}
+
+func (g *Gemini) handleSymbolDetails(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/v1/symbols/details/"))
+
+	g.mu.Lock()
+	_, ok := g.series[symbol]
+	g.mu.Unlock()
+
+	if !ok {
+		writeGeminiError(w, symbol)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"symbol":          symbol,
+		"base_currency":   baseCurrency(symbol),
+		"quote_currency":  quoteCurrency(symbol),
+		"tick_size":       "0.01",
+		"quote_increment": "0.01",
+		"min_order_size":  "0.001",
+		"status":          "open",
+		"wrap_enabled":    false,
+	})
+}
+
+func (g *Gemini) handleTicker(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/v1/pubticker/"))
+
+	price, ok := g.nextPrice(symbol)
+	if !ok {
+		writeGeminiError(w, symbol)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"bid":  price.String(),
+		"ask":  price.String(),
+		"last": price.String(),
+		"volume": map[string]any{
+			"timestamp":           time.Now().UnixMilli(),
+			baseCurrency(symbol):  "1",
+			quoteCurrency(symbol): price.String(),
+		},
+	})
+}
+
+func (g *Gemini) handleTickerV2(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/v2/ticker/"))
+
+	price, ok := g.nextPrice(symbol)
+	if !ok {
+		writeGeminiError(w, symbol)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"symbol":  symbol,
+		"open":    price.String(),
+		"high":    price.String(),
+		"low":     price.String(),
+		"close":   price.String(),
+		"bid":     price.String(),
+		"ask":     price.String(),
+		"changes": []string{},
+	})
+}
+
+// handlePriceFeed serves the current price of every symbol,
+// without advancing their series.
+func (g *Gemini) handlePriceFeed(w http.ResponseWriter, _ *http.Request) {
+	g.mu.Lock()
+	feed := make([]map[string]any, 0, len(g.series))
+	for symbol, series := range g.series {
+		feed = append(feed, map[string]any{
+			"pair":             symbol,
+			"price":            nextInSeries(series.prices, series.requests).String(),
+			"percentChange24h": "0.0000",
+		})
+	}
+	g.mu.Unlock()
+
+	sort.Slice(feed, func(i, j int) bool {
+		return feed[i]["pair"].(string) < feed[j]["pair"].(string)
+	})
+
+	writeJSON(w, http.StatusOK, feed)
+}
+
+func writeGeminiError(w http.ResponseWriter, symbol string) {
+	writeJSON(w, http.StatusBadRequest, map[string]any{
+		"result":  "error",
+		"reason":  "InvalidSymbol",
+		"message": "Supplied value '" + symbol + "' is not a valid symbol",
+	})
+}
+
+// baseCurrency returns the base currency of a symbol,
+// assuming a three letter quote currency.
+func baseCurrency(symbol string) string {
+	if len(symbol) <= 3 {
+		return symbol
+	}
+
+	return symbol[:len(symbol)-3]
+}
+
+// quoteCurrency returns the quote currency of a symbol,
+// assuming a three letter quote currency.
+func quoteCurrency(symbol string) string {
+	if len(symbol) <= 3 {
+		return ""
+	}
+
+	return symbol[len(symbol)-3:]
+}
@@ -0,0 +1,34 @@
+package testservers
+
+import (
+	"text/template"
+)
+
+// Message is a messages.Message with fixed plaintext and markdown renderings,
+// for sending through notifiers and clients without any message templates.
+type Message struct {
+	Plaintext string
+	Markdown  string
+}
+
+// PlainMessage returns a Message rendered as the passed body
+// in both plaintext and markdown.
+func PlainMessage(body string) Message {
+	return Message{Plaintext: body, Markdown: body}
+}
+
+func (m Message) FormatPlaintext() string {
+	return m.Plaintext
+}
+
+func (m Message) FormatMarkdown() string {
+	return m.Markdown
+}
+
+func (m Message) PlaintextTemplate() *template.Template {
+	return nil
+}
+
+func (m Message) MarkdownTemplate() *template.Template {
+	return nil
+}
@@ -0,0 +1,156 @@
+package testservers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	slackApiPathPrefix = "/api/"
+	slackBotId         = "B0000000001"
+	slackUserId        = "U0000000001"
+)
+
+// SlackMessage is a message posted through a fake Slack API.
+type SlackMessage struct {
+	Channel     string
+	Text        string
+	Attachments []slack.Attachment
+}
+
+// Slack is a fake of the Slack Web API methods used by this project,
+// capturing every message posted through it.
+type Slack struct {
+	url      string
+	messages []SlackMessage
+	mu       sync.Mutex
+}
+
+// NewSlack starts a fake Slack API, which is closed once the test finishes.
+func NewSlack(t testing.TB) *Slack {
+	t.Helper()
+
+	s := new(Slack)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(slackApiPathPrefix+"auth.test", s.handleAuthTest)
+	mux.HandleFunc(slackApiPathPrefix+"chat.postMessage", s.handlePostMessage)
+	mux.HandleFunc(slackApiPathPrefix+"users.info", s.handleUserInfo)
+	mux.HandleFunc(slackApiPathPrefix, func(w http.ResponseWriter, _ *http.Request) {
+		writeSlackError(w, "unknown_method")
+	})
+
+	s.url = newServer(t, s.withAuth(mux)).URL + slackApiPathPrefix
+
+	return s
+}
+
+// URL returns the base URL of the fake Slack API, to be used as a Slack client's base URL.
+func (s *Slack) URL() string {
+	return s.url
+}
+
+// Messages returns every message posted so far, in the order they were posted.
+func (s *Slack) Messages() []SlackMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SlackMessage(nil), s.messages...)
+}
+
+// withAuth rejects requests which don't pass a token,
+// either as a form value or as a bearer token.
+func (s *Slack) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			writeSlackError(w, "invalid_form_data")
+			return
+		}
+
+		token := r.Form.Get("token")
+		if token == "" {
+			token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+
+		if token == "" {
+			writeSlackError(w, "not_authed")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Slack) handleAuthTest(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":      true,
+		"url":     "https://stuffnotifier.slack.com/",
+		"team":    "stuffnotifier",
+		"user":    "stuffnotifier",
+		"team_id": "T0000000001",
+		"user_id": slackUserId,
+		"bot_id":  slackBotId,
+	})
+}
+
+func (s *Slack) handlePostMessage(w http.ResponseWriter, r *http.Request) {
+	msg := SlackMessage{
+		Channel: r.Form.Get("channel"),
+		Text:    r.Form.Get("text"),
+	}
+
+	if msg.Channel == "" {
+		writeSlackError(w, "channel_not_found")
+		return
+	}
+
+	if attachments := r.Form.Get("attachments"); attachments != "" {
+		if err := json.Unmarshal([]byte(attachments), &msg.Attachments); err != nil {
+			writeSlackError(w, "invalid_attachments")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	ts := fmt.Sprintf("%[1]d.%06[2]d", time.Now().Unix(), len(s.messages))
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":      true,
+		"channel": msg.Channel,
+		"ts":      ts,
+	})
+}
+
+func (s *Slack) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	userId := r.Form.Get("user")
+	if userId == "" {
+		writeSlackError(w, "user_not_found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok": true,
+		"user": map[string]any{
+			"id":      userId,
+			"name":    strings.ToLower(userId),
+			"is_bot":  false,
+			"deleted": false,
+		},
+	})
+}
+
+func writeSlackError(w http.ResponseWriter, reason string) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":    false,
+		"error": reason,
+	})
+}
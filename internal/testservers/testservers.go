@@ -0,0 +1,36 @@
+package testservers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newServer starts an httptest.Server using the passed handler,
+// which is closed once the test (and all its subtests) finishes.
+func newServer(t testing.TB, handler http.Handler) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// nextInSeries returns the item of a scripted series at index i,
+// or its last item if i is past the end of the series.
+func nextInSeries[T any](series []T, i int) T {
+	if i >= len(series) {
+		i = len(series) - 1
+	}
+
+	return series[i]
+}
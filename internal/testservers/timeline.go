@@ -0,0 +1,257 @@
+package testservers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+const (
+	// Time between a flight's scheduled gate departure and takeoff,
+	// and between its scheduled landing and gate arrival.
+	scheduledTaxiOut = 15 * time.Minute
+	scheduledTaxiIn  = 10 * time.Minute
+)
+
+// FlightEvent modifies a flight's data, and is used to build the stages of a FlightTimeline.
+type FlightEvent func(flight *flightaware.FlightData)
+
+// FlightTimeline is a scripted flight served by a fake AeroAPI,
+// progressing through a list of stages.
+// By default, the timeline advances to its next stage every time the flight is served,
+// and stays at its last stage once it has been reached.
+type FlightTimeline struct {
+	stages           []flightaware.FlightData
+	stage            int
+	served           int
+	requestsPerStage int
+	mu               sync.Mutex
+}
+
+// NewFlightTimeline returns a FlightTimeline with the passed flight data as its first stage.
+func NewFlightTimeline(flight flightaware.FlightData) *FlightTimeline {
+	return &FlightTimeline{
+		stages:           []flightaware.FlightData{flight},
+		requestsPerStage: 1,
+	}
+}
+
+// Then adds a stage to the timeline: the timeline's last stage,
+// with the passed events applied to it in order.
+func (tl *FlightTimeline) Then(events ...FlightEvent) *FlightTimeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	next := tl.stages[len(tl.stages)-1]
+	for _, event := range events {
+		event(&next)
+	}
+
+	tl.stages = append(tl.stages, next)
+
+	return tl
+}
+
+// SetRequestsPerStage sets how many times each stage is served before the timeline advances.
+// Values less than 1 disable advancing automatically, leaving it to Advance.
+func (tl *FlightTimeline) SetRequestsPerStage(n int) *FlightTimeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.requestsPerStage = n
+
+	return tl
+}
+
+// Advance moves the timeline to its next stage,
+// returning false if it's already at its last stage.
+func (tl *FlightTimeline) Advance() bool {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.advance()
+}
+
+func (tl *FlightTimeline) advance() bool {
+	if tl.stage >= len(tl.stages)-1 {
+		return false
+	}
+
+	tl.stage++
+	tl.served = 0
+
+	return true
+}
+
+// Stage returns the index of the timeline's current stage.
+func (tl *FlightTimeline) Stage() int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.stage
+}
+
+// Current returns the flight data of the timeline's current stage.
+func (tl *FlightTimeline) Current() flightaware.FlightData {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.stages[tl.stage]
+}
+
+// serve returns the flight data of the timeline's current stage,
+// advancing the timeline if the stage has been served enough times.
+func (tl *FlightTimeline) serve() flightaware.FlightData {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	flight := tl.stages[tl.stage]
+
+	tl.served++
+	if tl.requestsPerStage > 0 && tl.served >= tl.requestsPerStage {
+		tl.advance()
+	}
+
+	return flight
+}
+
+// ScheduledFlight returns the data of a scheduled airline flight with the passed
+// FlightAware flight ID and IATA flight number between the passed airports,
+// scheduled to depart its gate at `departure` and arrive at its gate `duration` later.
+func ScheduledFlight(
+	faFlightId, flightNumber string,
+	origin, destination flightaware.AirportData,
+	departure time.Time,
+	duration time.Duration,
+) flightaware.FlightData {
+
+	departure = departure.UTC()
+	arrival := departure.Add(duration)
+
+	return flightaware.FlightData{
+		FlightId:     faFlightId,
+		FlightNumber: flightNumber,
+		Identifiers: flightaware.FlightIdentifiers{
+			Identifier: flightNumber,
+			IATA:       flightNumber,
+		},
+		Origin:      flightaware.FlightOriginDestinationData{Identifiers: origin.Identifiers},
+		Destination: flightaware.FlightOriginDestinationData{Identifiers: destination.Identifiers},
+		GateDepartureTime: flightaware.FlightTimestamp{
+			Scheduled: departure,
+			Estimated: departure,
+		},
+		RunwayDepartureTime: flightaware.FlightTimestamp{
+			Scheduled: departure.Add(scheduledTaxiOut),
+			Estimated: departure.Add(scheduledTaxiOut),
+		},
+		RunwayArrivalTime: flightaware.FlightTimestamp{
+			Scheduled: arrival.Add(-scheduledTaxiIn),
+			Estimated: arrival.Add(-scheduledTaxiIn),
+		},
+		GateArrivalTime: flightaware.FlightTimestamp{
+			Scheduled: arrival,
+			Estimated: arrival,
+		},
+		FlightTime: duration,
+		Status:     "Scheduled",
+		FlightType: flightaware.Airline,
+	}
+}
+
+// GateOut sets the flight's actual gate departure time.
+func GateOut(at time.Time) FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.GateDepartureTime.Actual = at.UTC()
+		flight.Status = "Taxiing / Left Gate"
+	}
+}
+
+// WheelsOff sets the flight's actual takeoff time.
+func WheelsOff(at time.Time) FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.RunwayDepartureTime.Actual = at.UTC()
+		flight.Status = "En Route"
+	}
+}
+
+// WheelsOn sets the flight's actual landing time.
+func WheelsOn(at time.Time) FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.RunwayArrivalTime.Actual = at.UTC()
+		flight.FlightProgress = 100
+		flight.Status = "Landed / Taxiing"
+	}
+}
+
+// GateIn sets the flight's actual gate arrival time.
+func GateIn(at time.Time) FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.GateArrivalTime.Actual = at.UTC()
+		flight.FlightProgress = 100
+		flight.Status = "Arrived / Gate Arrival"
+	}
+}
+
+// Delayed sets the flight's estimated times to their scheduled times plus the passed delay,
+// for every event which hasn't happened yet.
+func Delayed(delay time.Duration) FlightEvent {
+	delayTimestamp := func(ts *flightaware.FlightTimestamp) {
+		if ts.Actual.IsZero() {
+			ts.Estimated = ts.Scheduled.Add(delay)
+		}
+	}
+
+	return func(flight *flightaware.FlightData) {
+		delayTimestamp(&flight.GateDepartureTime)
+		delayTimestamp(&flight.RunwayDepartureTime)
+		delayTimestamp(&flight.RunwayArrivalTime)
+		delayTimestamp(&flight.GateArrivalTime)
+
+		if flight.GateDepartureTime.Actual.IsZero() {
+			flight.DepartureDelay = delay
+		}
+
+		flight.ArrivalDelay = delay
+	}
+}
+
+// DepartureGate sets the flight's departure terminal and gate.
+func DepartureGate(terminal, gate string) FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.Origin.Terminal = terminal
+		flight.Origin.Gate = gate
+	}
+}
+
+// ArrivalGate sets the flight's arrival terminal and gate.
+func ArrivalGate(terminal, gate string) FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.Destination.Terminal = terminal
+		flight.Destination.Gate = gate
+	}
+}
+
+// BaggageClaim sets the flight's baggage claim.
+func BaggageClaim(claim string) FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.BaggageClaim = claim
+	}
+}
+
+// Cancelled marks the flight as cancelled.
+func Cancelled() FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.Cancelled = true
+		flight.Status = "Cancelled"
+	}
+}
+
+// Diverted marks the flight as diverted.
+func Diverted() FlightEvent {
+	return func(flight *flightaware.FlightData) {
+		flight.Diverted = true
+		flight.Status = "Diverted"
+	}
+}
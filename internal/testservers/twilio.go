@@ -0,0 +1,137 @@
+package testservers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	twilioApiVersion = "/2010-04-01"
+)
+
+// SMS is a message sent through a fake Twilio API.
+type SMS struct {
+	Sid        string
+	AccountSid string
+	From       string
+	To         string
+	Body       string
+}
+
+// Twilio is a fake of the Twilio Programmable Messaging endpoints used by this project,
+// capturing every SMS sent through it.
+type Twilio struct {
+	url      string
+	messages []SMS
+	mu       sync.Mutex
+}
+
+// NewTwilio starts a fake Twilio API, which is closed once the test finishes.
+func NewTwilio(t testing.TB) *Twilio {
+	t.Helper()
+
+	tw := new(Twilio)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(twilioApiVersion+"/Accounts/", tw.handleMessages)
+
+	tw.url = newServer(t, mux).URL
+
+	return tw
+}
+
+// URL returns the base URL of the fake Twilio API, to be used as a Twilio client's base URL.
+func (tw *Twilio) URL() string {
+	return tw.url
+}
+
+// Messages returns every SMS sent so far, in the order they were sent.
+func (tw *Twilio) Messages() []SMS {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return append([]SMS(nil), tw.messages...)
+}
+
+// handleMessages handles requests to /2010-04-01/Accounts/{AccountSid}/Messages.json
+// (sending an SMS) and /2010-04-01/Accounts/{AccountSid}/Messages/{Sid}.json (fetching one).
+func (tw *Twilio) handleMessages(w http.ResponseWriter, r *http.Request) {
+	if _, _, ok := r.BasicAuth(); !ok {
+		writeTwilioError(w, http.StatusUnauthorized, 20003, "Authenticate")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, twilioApiVersion+"/Accounts/"), "/")
+
+	switch {
+	case len(parts) == 2 && parts[1] == "Messages.json" && r.Method == http.MethodPost:
+		tw.createMessage(w, r, parts[0])
+	case len(parts) == 3 && parts[1] == "Messages" && r.Method == http.MethodGet:
+		tw.fetchMessage(w, parts[0], strings.TrimSuffix(parts[2], ".json"))
+	default:
+		writeTwilioError(w, http.StatusNotFound, 20404, "The requested resource "+r.URL.Path+" was not found")
+	}
+}
+
+func (tw *Twilio) createMessage(w http.ResponseWriter, r *http.Request, accountSid string) {
+	if err := r.ParseForm(); err != nil {
+		writeTwilioError(w, http.StatusBadRequest, 20001, err.Error())
+		return
+	}
+
+	tw.mu.Lock()
+	msg := SMS{
+		Sid:        fmt.Sprintf("SM%032d", len(tw.messages)+1),
+		AccountSid: accountSid,
+		From:       r.PostForm.Get("From"),
+		To:         r.PostForm.Get("To"),
+		Body:       r.PostForm.Get("Body"),
+	}
+	tw.messages = append(tw.messages, msg)
+	tw.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, encodeTwilioMessage(msg))
+}
+
+func (tw *Twilio) fetchMessage(w http.ResponseWriter, accountSid, sid string) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	for _, msg := range tw.messages {
+		if msg.Sid == sid && msg.AccountSid == accountSid {
+			writeJSON(w, http.StatusOK, encodeTwilioMessage(msg))
+			return
+		}
+	}
+
+	writeTwilioError(w, http.StatusNotFound, 20404, "The requested resource "+sid+" was not found")
+}
+
+func encodeTwilioMessage(msg SMS) map[string]any {
+	return map[string]any{
+		"sid":          msg.Sid,
+		"account_sid":  msg.AccountSid,
+		"from":         msg.From,
+		"to":           msg.To,
+		"body":         msg.Body,
+		"status":       "queued",
+		"direction":    "outbound-api",
+		"num_segments": "1",
+		"date_created": time.Now().UTC().Format(time.RFC1123Z),
+		"date_sent":    nil,
+		"api_version":  strings.TrimPrefix(twilioApiVersion, "/"),
+	}
+}
+
+func writeTwilioError(w http.ResponseWriter, statusCode, code int, message string) {
+	writeJSON(w, statusCode, map[string]any{
+		"code":      code,
+		"message":   message,
+		"status":    statusCode,
+		"more_info": fmt.Sprintf("https://www.twilio.com/docs/errors/%[1]d", code),
+	})
+}
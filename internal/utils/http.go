@@ -3,8 +3,11 @@ package utils
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 func HttpClientWithTimeout(timeout time.Duration) *http.Client {
@@ -13,10 +16,42 @@ func HttpClientWithTimeout(timeout time.Duration) *http.Client {
 	}
 }
 
+// BuildRequestEndpoint joins the passed base endpoint and path.
+// The base endpoint may include a scheme (ex. http://localhost:8080/api),
+// and https is used if it doesn't.
 func BuildRequestEndpoint(baseEndpoint, path string) string {
+	scheme := "https"
+	if s, rest, ok := strings.Cut(baseEndpoint, "://"); ok {
+		scheme, baseEndpoint = s, rest
+	}
+
 	return fmt.Sprintf(
-		"https://%[1]s/%[2]s",
+		"%[1]s://%[2]s/%[3]s",
+		scheme,
 		strings.TrimSuffix(baseEndpoint, "/"),
 		strings.TrimPrefix(path, "/"),
 	)
 }
+
+// ParseBaseUrl parses an API base URL (scheme, host, and optional path prefix).
+// The scheme must be http or https, and https is used if the URL has none.
+func ParseBaseUrl(rawUrl string) (*url.URL, error) {
+	if !strings.Contains(rawUrl, "://") {
+		rawUrl = "https://" + rawUrl
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid base url %[1]s", rawUrl)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("invalid base url %[1]s: scheme must be http or https", rawUrl)
+	}
+
+	if u.Host == "" {
+		return nil, errors.Errorf("invalid base url %[1]s: missing host", rawUrl)
+	}
+
+	return u, nil
+}
@@ -0,0 +1,91 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+)
+
+func TestBuildRequestEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		baseEndpoint string
+		path         string
+		want         string
+	}{
+		{
+			name:         "no scheme",
+			baseEndpoint: "aeroapi.flightaware.com/aeroapi",
+			path:         "flights/UAL2614",
+			want:         "https://aeroapi.flightaware.com/aeroapi/flights/UAL2614",
+		},
+		{
+			name:         "https",
+			baseEndpoint: "https://api.gemini.com/",
+			path:         "/v1/pricefeed",
+			want:         "https://api.gemini.com/v1/pricefeed",
+		},
+		{
+			name:         "http with port",
+			baseEndpoint: "http://localhost:8080/aeroapi",
+			path:         "/airports/KSFO",
+			want:         "http://localhost:8080/aeroapi/airports/KSFO",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.BuildRequestEndpoint(tt.baseEndpoint, tt.path))
+		})
+	}
+}
+
+func TestParseBaseUrl(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawUrl  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no scheme",
+			rawUrl: "proxy.internal:3128/aeroapi",
+			want:   "https://proxy.internal:3128/aeroapi",
+		},
+		{
+			name:   "http",
+			rawUrl: "http://127.0.0.1:8080",
+			want:   "http://127.0.0.1:8080",
+		},
+		{
+			name:    "unsupported scheme",
+			rawUrl:  "ftp://example.com",
+			wantErr: true,
+		},
+		{
+			name:    "missing host",
+			rawUrl:  "http:///api",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.ParseBaseUrl(tt.rawUrl)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
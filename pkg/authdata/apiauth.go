@@ -105,6 +105,25 @@ func SlackAPIAuth() (auth AuthData, err error) {
 	return
 }
 
+// SmtpAuth returns an AuthData whose Key
+// field is populated with an SMTP username,
+// and Secret field populated with an SMTP password.
+func SmtpAuth() (auth AuthData, err error) {
+	username, err := env.FromEnv(env.SmtpUsername)
+	if err != nil {
+		return
+	}
+
+	password, err := env.FromEnv(env.SmtpPassword)
+	if err != nil {
+		return
+	}
+
+	auth = NewAuthData("", username, password)
+
+	return
+}
+
 func RedisAuth() (auth ServiceAuthData, err error) {
 	redisHost, _ := env.String(env.RedisHost, "localhost")
 	redisPort, _ := env.Int(env.RedisPort, 6379)
@@ -0,0 +1,76 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the time, and creates tickers and timers.
+// Pollers take a Clock instead of using the time package directly,
+// so that tests (see Fake) and replays can control the passage of time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a Ticker sending the time on its channel every d.
+	NewTicker(d time.Duration) Ticker
+	// After returns a channel which receives the time once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker sends the time on a channel at an interval, the same as a time.Ticker.
+type Ticker interface {
+	// C returns the channel on which ticks are sent.
+	C() <-chan time.Time
+	// Reset stops the ticker, and resets its interval to d.
+	Reset(d time.Duration)
+	// Stop turns off the ticker. No more ticks are sent after Stop returns.
+	Stop()
+}
+
+// Real returns the system clock.
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// WithNow returns a Clock which tells the time using the passed function,
+// and creates tickers and timers using the passed Clock
+// (or the system clock if c is nil).
+// It's used to follow a time source which doesn't advance on its own,
+// such as the timestamps of replayed API responses.
+func WithNow(c Clock, now func() time.Time) Clock {
+	if c == nil {
+		c = Real()
+	}
+
+	return withNow{Clock: c, now: now}
+}
+
+type withNow struct {
+	Clock
+	now func() time.Time
+}
+
+func (c withNow) Now() time.Time {
+	return c.now()
+}
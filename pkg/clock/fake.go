@@ -0,0 +1,169 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock which only advances when told to, using Advance or Set.
+// Tickers and timers created by a Fake fire as the time passes their deadlines,
+// which lets tests step through hours of polling in milliseconds.
+type Fake struct {
+	now     time.Time
+	waiters []*fakeWaiter
+	mu      sync.Mutex
+}
+
+// fakeWaiter is a ticker or timer waiting for a Fake's time to reach its deadline.
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+	// Interval of a ticker, or 0 for a timer.
+	interval time.Duration
+}
+
+// NewFake returns a Fake clock set to the passed time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now implements Clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// NewTicker implements Clock.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for clock.Fake.NewTicker")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{
+		deadline: f.now.Add(d),
+		ch:       make(chan time.Time, 1),
+		interval: d,
+	}
+
+	f.waiters = append(f.waiters, w)
+
+	return &fakeTicker{clock: f, waiter: w}
+}
+
+// After implements Clock.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &fakeWaiter{
+		deadline: f.now.Add(d),
+		ch:       make(chan time.Time, 1),
+	}
+
+	if d <= 0 {
+		w.ch <- f.now
+		return w.ch
+	}
+
+	f.waiters = append(f.waiters, w)
+
+	return w.ch
+}
+
+// Waiters returns the number of tickers and timers waiting on the clock.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.waiters)
+}
+
+// Advance moves the clock forward by d, firing every ticker and timer
+// whose deadline is reached, in order of their deadlines.
+// As with a time.Ticker, ticks are dropped for tickers whose last tick hasn't been received.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+
+	f.Set(target)
+}
+
+// Set moves the clock to the passed time, firing every ticker and timer
+// whose deadline is reached. Setting the clock to an earlier time only changes Now.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for {
+		sort.SliceStable(f.waiters, func(i, j int) bool {
+			return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+		})
+
+		if len(f.waiters) == 0 || f.waiters[0].deadline.After(t) {
+			break
+		}
+
+		w := f.waiters[0]
+		f.now = w.deadline
+
+		select {
+		case w.ch <- w.deadline:
+		default:
+		}
+
+		if w.interval > 0 {
+			w.deadline = w.deadline.Add(w.interval)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+
+	f.now = t
+}
+
+func (f *Fake) removeWaiter(w *fakeWaiter) {
+	for i := range f.waiters {
+		if f.waiters[i] == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock  *Fake
+	waiter *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.waiter.ch
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for clock.Ticker.Reset")
+	}
+
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.clock.removeWaiter(t.waiter)
+
+	t.waiter.interval = d
+	t.waiter.deadline = t.clock.now.Add(d)
+	t.clock.waiters = append(t.clock.waiters, t.waiter)
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.clock.removeWaiter(t.waiter)
+}
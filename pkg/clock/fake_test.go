@@ -0,0 +1,98 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fakeStart = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestFake_NewTicker(t *testing.T) {
+	f := NewFake(fakeStart)
+	ticker := f.NewTicker(time.Minute)
+
+	assertNoTick(t, ticker.C())
+
+	f.Advance(30 * time.Second)
+	assertNoTick(t, ticker.C())
+
+	f.Advance(30 * time.Second)
+	assertTick(t, ticker.C(), fakeStart.Add(time.Minute))
+
+	// Ticks which aren't received are dropped.
+	f.Advance(3 * time.Minute)
+	assertTick(t, ticker.C(), fakeStart.Add(2*time.Minute))
+	assertNoTick(t, ticker.C())
+	assert.Equal(t, fakeStart.Add(4*time.Minute), f.Now())
+
+	ticker.Reset(10 * time.Minute)
+	f.Advance(5 * time.Minute)
+	assertNoTick(t, ticker.C())
+	f.Advance(5 * time.Minute)
+	assertTick(t, ticker.C(), fakeStart.Add(14*time.Minute))
+
+	ticker.Stop()
+	assert.Equal(t, 0, f.Waiters())
+
+	f.Advance(time.Hour)
+	assertNoTick(t, ticker.C())
+}
+
+func TestFake_After(t *testing.T) {
+	f := NewFake(fakeStart)
+
+	var (
+		late  = f.After(2 * time.Hour)
+		early = f.After(time.Hour)
+	)
+
+	assertTick(t, f.After(0), fakeStart)
+	assert.Equal(t, 2, f.Waiters())
+
+	f.Set(fakeStart.Add(90 * time.Minute))
+	assertTick(t, early, fakeStart.Add(time.Hour))
+	assertNoTick(t, late)
+	assert.Equal(t, 1, f.Waiters())
+
+	f.Advance(time.Hour)
+	assertTick(t, late, fakeStart.Add(2*time.Hour))
+	assert.Equal(t, 0, f.Waiters())
+}
+
+func TestWithNow(t *testing.T) {
+	var (
+		f   = NewFake(fakeStart)
+		now = fakeStart.Add(-time.Hour)
+		c   = WithNow(f, func() time.Time { return now })
+	)
+
+	assert.Equal(t, now, c.Now())
+
+	ticker := c.NewTicker(time.Minute)
+	f.Advance(time.Minute)
+	assertTick(t, ticker.C(), fakeStart.Add(time.Minute))
+	assert.Equal(t, now, c.Now())
+}
+
+func assertTick(t *testing.T, ch <-chan time.Time, want time.Time) {
+	t.Helper()
+
+	select {
+	case got := <-ch:
+		assert.Equal(t, want, got)
+	default:
+		t.Errorf("expected a tick at %[1]s", want)
+	}
+}
+
+func assertNoTick(t *testing.T, ch <-chan time.Time) {
+	t.Helper()
+
+	select {
+	case got := <-ch:
+		t.Errorf("unexpected tick at %[1]s", got)
+	default:
+	}
+}
@@ -0,0 +1,100 @@
+package discord
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/authdata"
+)
+
+const (
+	DefaultApiBaseUrl string = "https://discord.com/api/v10"
+)
+
+const (
+	defaultHttpTimeout = 10 * time.Second
+)
+
+// Client is a minimal Discord REST API client,
+// supporting bot-token authenticated channel/direct messages
+// as well as incoming webhooks.
+type Client struct {
+	authData   authdata.AuthData
+	httpClient *http.Client
+	baseApiUrl string
+	webhookUrl string
+}
+
+// NewClient returns a Client configured using the passed Config.
+// If the Config uses a channel or user ID and no bot token is configured,
+// the bot token is read from the environment.
+func NewClient(conf *Config) (*Client, error) {
+	if conf == nil {
+		return nil, errors.New("discord config must not be nil")
+	}
+
+	c := &Client{
+		httpClient: utils.HttpClientWithTimeout(defaultHttpTimeout),
+		baseApiUrl: DefaultApiBaseUrl,
+	}
+
+	if baseUrl, ok := utils.FromPointer(conf.ApiBaseUrl); ok {
+		if _, err := c.SetBaseUrl(baseUrl); err != nil {
+			return nil, errors.WithMessage(err, "invalid discord config")
+		}
+	}
+
+	if webhookUrl, ok := utils.FromPointer(conf.WebhookUrl); ok {
+		c.webhookUrl = webhookUrl
+	}
+
+	if !conf.UsesBot() {
+		if !conf.UsesWebhook() {
+			return nil, errors.New("one of user_id, channel_id, or webhook_url must be configured")
+		}
+
+		return c, nil
+	}
+
+	if conf.Auth != nil && conf.Auth.Token != "" {
+		c.authData = conf.Auth
+	} else {
+		ad, err := authdata.DiscordAPIAuth()
+		if err != nil {
+			return nil, err
+		}
+
+		c.authData = ad
+	}
+
+	return c, nil
+}
+
+// SetHttpTimeout sets the timeout of the Client's http.Client instance.
+// By default, this is set to 10 seconds.
+func (c *Client) SetHttpTimeout(timeout time.Duration) *Client {
+	c.httpClient.Timeout = timeout
+	return c
+}
+
+// SetBaseUrl sets the base URL (scheme, host, and path prefix) of the Client's API requests,
+// ex. http://localhost:8080/api/v10. Webhook requests are sent to their configured URL.
+// An empty base URL resets it to DefaultApiBaseUrl.
+// Returns an error if the base URL is invalid.
+func (c *Client) SetBaseUrl(baseUrl string) (*Client, error) {
+	if baseUrl == "" {
+		baseUrl = DefaultApiBaseUrl
+	}
+
+	u, err := utils.ParseBaseUrl(baseUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	c.baseApiUrl = strings.TrimSuffix(u.String(), "/")
+	return c, nil
+}
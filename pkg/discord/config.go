@@ -11,15 +11,52 @@ import (
 )
 
 type Config struct {
-	UserId    *string     `json:"user_id,omitempty" yaml:"user_id,omitempty" toml:"UserId,omitempty"`
-	ChannelId *string     `json:"channel_id,omitempty" yaml:"channel_id,omitempty" toml:"ChannelId,omitempty"`
-	Auth      *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty" toml:"Auth,omitempty"`
+	// ID of a user to send notifications to (as direct messages).
+	// Requires a bot token.
+	UserId *string `json:"user_id,omitempty" yaml:"user_id,omitempty" toml:"UserId,omitempty"`
+	// ID of a channel to send notifications to.
+	// Requires a bot token.
+	ChannelId *string `json:"channel_id,omitempty" yaml:"channel_id,omitempty" toml:"ChannelId,omitempty"`
+	// Incoming webhook URL to send notifications to.
+	// Doesn't require a bot token.
+	WebhookUrl *string `json:"webhook_url,omitempty" yaml:"webhook_url,omitempty" toml:"WebhookUrl,omitempty"`
+	// Base URL of the Discord REST API.
+	// Default: https://discord.com/api/v10
+	ApiBaseUrl *string     `json:"api_base_url,omitempty" yaml:"api_base_url,omitempty" toml:"ApiBaseUrl,omitempty"`
+	Auth       *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty" toml:"Auth,omitempty"`
+}
+
+// UsesBot returns true if a user ID or channel ID is configured,
+// both of which require a bot token.
+func (c Config) UsesBot() bool {
+	userId, _ := utils.FromPointer(c.UserId)
+	channelId, _ := utils.FromPointer(c.ChannelId)
+
+	return userId != "" || channelId != ""
+}
+
+// UsesWebhook returns true if a webhook URL is configured.
+func (c Config) UsesWebhook() bool {
+	webhookUrl, _ := utils.FromPointer(c.WebhookUrl)
+	return webhookUrl != ""
 }
 
 type AuthConfig struct {
 	Token string `json:"token" yaml:"token" toml:"Token"`
 }
 
+func (c AuthConfig) Account() string {
+	return ""
+}
+
+func (c AuthConfig) Key() string {
+	return ""
+}
+
+func (c AuthConfig) Secret() string {
+	return c.Token
+}
+
 // LoadConfig reads configuration data from the file at the passed path
 // and returns it as a fully loaded Config.
 // `confPath` is expected to be an absolute file path.
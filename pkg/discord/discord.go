@@ -1 +1,168 @@
 package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+const (
+	authHeader      string = "Authorization"
+	botTokenPrefix  string = "Bot "
+	contentType     string = "application/json"
+	channelMsgsUri  string = "channels/%s/messages"
+	userChannelsUri string = "users/@me/channels"
+)
+
+// SendMessage sends the passed message to the Discord channel
+// with the passed ID.
+func (c *Client) SendMessage(ctx context.Context, msg messages.Message, channelId string) (*Message, error) {
+	var response *Message
+
+	if err := c.botRequest(ctx, channelMessagesEndpoint(channelId), BuildMessagePayload(msg), &response); err != nil {
+		return nil, sendMessageErr(err, "channel", channelId)
+	}
+
+	return response, nil
+}
+
+// SendDirectMessage sends the passed message as a direct message
+// to the Discord user with the passed ID.
+func (c *Client) SendDirectMessage(ctx context.Context, msg messages.Message, userId string) (*Message, error) {
+	dmChannel, err := c.CreateDMChannel(ctx, userId)
+	if err != nil {
+		return nil, sendMessageErr(err, "user", userId)
+	}
+
+	return c.SendMessage(ctx, msg, dmChannel.Id)
+}
+
+// CreateDMChannel returns the direct message channel between the
+// bot and the user with the passed ID, creating it if necessary.
+func (c *Client) CreateDMChannel(ctx context.Context, userId string) (*Channel, error) {
+	var response *Channel
+
+	payload := createDMPayload{RecipientId: userId}
+
+	if err := c.botRequest(ctx, userChannelsUri, payload, &response); err != nil {
+		return nil, errors.WithMessagef(err, "error creating dm channel for user %[1]s", userId)
+	}
+
+	return response, nil
+}
+
+// SendWebhookMessage sends the passed message to the Client's
+// configured incoming webhook URL.
+func (c *Client) SendWebhookMessage(ctx context.Context, msg messages.Message) error {
+	if c.webhookUrl == "" {
+		return errors.New("no discord webhook url configured")
+	}
+
+	if err := c.httpRequest(ctx, c.webhookUrl, "", BuildMessagePayload(msg), nil); err != nil {
+		return errors.WithMessage(err, "error sending message to discord webhook")
+	}
+
+	return nil
+}
+
+// BuildMessagePayload renders a messages.Message as a Discord message,
+// using the message's markdown formatting (falling back to plaintext)
+// as the description of a single embed.
+func BuildMessagePayload(msg messages.Message) MessagePayload {
+	body := strings.TrimSpace(msg.FormatMarkdown())
+	if body == "" {
+		body = strings.TrimSpace(msg.FormatPlaintext())
+	}
+
+	// Discord's limit is in characters, so the body is truncated on rune boundaries.
+	if runes := []rune(body); len(runes) > maxEmbedDescriptionLength {
+		body = string(runes[:maxEmbedDescriptionLength])
+	}
+
+	return MessagePayload{
+		Embeds: []Embed{{
+			Title:       embedTitle,
+			Description: body,
+			Color:       embedColor,
+			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		}},
+	}
+}
+
+func (c *Client) botRequest(ctx context.Context, endpoint string, payload, dest any) error {
+	if c.authData == nil {
+		return errors.New("a discord bot token is required to send channel or direct messages")
+	}
+
+	uri := c.baseApiUrl + "/" + strings.TrimPrefix(endpoint, "/")
+
+	return c.httpRequest(ctx, uri, botTokenPrefix+c.authData.Secret(), payload, dest)
+}
+
+func (c *Client) httpRequest(ctx context.Context, uri, authorization string, payload, dest any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling request body")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(body))
+	if err != nil {
+		return errs.HttpBuildRequestError(err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if authorization != "" {
+		req.Header.Set(authHeader, authorization)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errs.HttpResponseError(err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errs.HttpReadBodyError(err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &ApiError{StatusCode: resp.StatusCode}
+		if jsonErr := json.Unmarshal(respBody, apiErr); jsonErr != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+
+		return apiErr
+	}
+
+	if dest == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	if err = json.Unmarshal(respBody, dest); err != nil {
+		return errs.HttpUnmarshalResponseBodyError(err)
+	}
+
+	return nil
+}
+
+func channelMessagesEndpoint(channelId string) string {
+	return fmt.Sprintf(channelMsgsUri, channelId)
+}
+
+func sendMessageErr(err error, recipientType, recipientId string) error {
+	return errors.WithMessagef(err, "error sending message to discord %[1]s %[2]s", recipientType, recipientId)
+}
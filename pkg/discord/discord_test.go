@@ -0,0 +1,224 @@
+package discord_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/testservers"
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/discord"
+)
+
+const (
+	testToken     string = "test-token"
+	testChannelId string = "1234"
+	testUserId    string = "5678"
+	testDMId      string = "9999"
+)
+
+type capturedRequest struct {
+	path          string
+	authorization string
+	body          map[string]any
+}
+
+type fakeDiscord struct {
+	requests []capturedRequest
+	mu       sync.Mutex
+}
+
+func (f *fakeDiscord) handler(t *testing.T) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		f.mu.Lock()
+		f.requests = append(f.requests, capturedRequest{
+			path:          r.URL.Path,
+			authorization: r.Header.Get("Authorization"),
+			body:          body,
+		})
+		f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/users/@me/channels":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": testDMId, "type": 1})
+		case "/api/channels/" + testChannelId + "/messages", "/api/channels/" + testDMId + "/messages":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "1", "channel_id": testChannelId})
+		case "/webhook":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"message": "Unknown Channel", "code": 10003})
+		}
+	}
+}
+
+func (f *fakeDiscord) paths() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	paths := make([]string, len(f.requests))
+	for i := range f.requests {
+		paths[i] = f.requests[i].path
+	}
+
+	return paths
+}
+
+func TestClient_Send(t *testing.T) {
+	msg := testservers.Message{Plaintext: "plain", Markdown: "**markdown**"}
+
+	tests := []struct {
+		send      func(ctx context.Context, c *discord.Client) error
+		name      string
+		wantPaths []string
+		wantAuth  bool
+		wantErr   bool
+	}{
+		{
+			name: "channel message",
+			send: func(ctx context.Context, c *discord.Client) error {
+				_, err := c.SendMessage(ctx, msg, testChannelId)
+				return err
+			},
+			wantPaths: []string{"/api/channels/" + testChannelId + "/messages"},
+			wantAuth:  true,
+		},
+		{
+			name: "direct message",
+			send: func(ctx context.Context, c *discord.Client) error {
+				_, err := c.SendDirectMessage(ctx, msg, testUserId)
+				return err
+			},
+			wantPaths: []string{"/api/users/@me/channels", "/api/channels/" + testDMId + "/messages"},
+			wantAuth:  true,
+		},
+		{
+			name: "webhook",
+			send: func(ctx context.Context, c *discord.Client) error {
+				return c.SendWebhookMessage(ctx, msg)
+			},
+			wantPaths: []string{"/webhook"},
+		},
+		{
+			name: "unknown channel",
+			send: func(ctx context.Context, c *discord.Client) error {
+				_, err := c.SendMessage(ctx, msg, "0000")
+				return err
+			},
+			wantPaths: []string{"/api/channels/0000/messages"},
+			wantAuth:  true,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			fake := new(fakeDiscord)
+			srv := httptest.NewServer(fake.handler(t))
+			defer srv.Close()
+
+			client, err := discord.NewClient(&discord.Config{
+				ChannelId:  utils.ToPointer(testChannelId),
+				WebhookUrl: utils.ToPointer(srv.URL + "/webhook"),
+				ApiBaseUrl: utils.ToPointer(srv.URL + "/api"),
+				Auth:       &discord.AuthConfig{Token: testToken},
+			})
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err = tt.send(ctx, client)
+			if tt.wantErr {
+				var apiErr *discord.ApiError
+				assert.ErrorAs(t, err, &apiErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantPaths, fake.paths())
+
+			for _, req := range fake.requests {
+				if tt.wantAuth {
+					assert.Equal(t, "Bot "+testToken, req.authorization)
+				} else {
+					assert.Empty(t, req.authorization)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildMessagePayload(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  testservers.Message
+		want string
+	}{
+		{
+			name: "markdown",
+			msg:  testservers.Message{Plaintext: "plain", Markdown: "**markdown**"},
+			want: "**markdown**",
+		},
+		{
+			name: "plaintext fallback",
+			msg:  testservers.Message{Plaintext: "plain"},
+			want: "plain",
+		},
+		{
+			name: "truncated on rune boundaries",
+			msg:  testservers.Message{Markdown: strings.Repeat("✈", 4097)},
+			want: strings.Repeat("✈", 4096),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			payload := discord.BuildMessagePayload(tt.msg)
+
+			if assert.Len(t, payload.Embeds, 1) {
+				assert.Equal(t, tt.want, payload.Embeds[0].Description)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := discord.NewClient(&discord.Config{})
+	assert.Error(t, err)
+
+	_, err = discord.NewClient(&discord.Config{WebhookUrl: utils.ToPointer("http://localhost/webhook")})
+	assert.NoError(t, err)
+
+	_, err = discord.NewClient(&discord.Config{
+		WebhookUrl: utils.ToPointer("http://localhost/webhook"),
+		ApiBaseUrl: utils.ToPointer("ftp://localhost/api"),
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid discord config")
+	}
+
+	client, err := discord.NewClient(&discord.Config{WebhookUrl: utils.ToPointer("http://localhost/webhook")})
+	if assert.NoError(t, err) {
+		_, err = client.SetBaseUrl("http://")
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,57 @@
+package discord
+
+import (
+	"fmt"
+)
+
+const (
+	maxEmbedDescriptionLength = 4096
+)
+
+const (
+	embedTitle string = "StuffNotifier alert"
+	embedColor int    = 0x5865F2
+)
+
+// MessagePayload is the request body used when creating a message,
+// either via the channel messages endpoint or an incoming webhook.
+type MessagePayload struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+}
+
+type Embed struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Timestamp   string `json:"timestamp,omitempty"`
+	Color       int    `json:"color,omitempty"`
+}
+
+// Channel is a (partial) Discord channel object.
+type Channel struct {
+	Id   string `json:"id"`
+	Type int    `json:"type"`
+}
+
+// Message is a (partial) Discord message object.
+type Message struct {
+	Id        string `json:"id"`
+	ChannelId string `json:"channel_id"`
+	Content   string `json:"content"`
+}
+
+type createDMPayload struct {
+	RecipientId string `json:"recipient_id"`
+}
+
+// ApiError is the error object returned by the Discord API
+// for unsuccessful requests.
+type ApiError struct {
+	Message    string `json:"message"`
+	Code       int    `json:"code"`
+	StatusCode int    `json:"-"`
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("discord api error (status %[1]d, code %[2]d): %[3]s", e.StatusCode, e.Code, e.Message)
+}
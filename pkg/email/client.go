@@ -0,0 +1,148 @@
+package email
+
+import (
+	"crypto/tls"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/pkg/authdata"
+)
+
+const (
+	defaultDialTimeout = 10 * time.Second
+)
+
+// Client sends notifications as multipart (plaintext and HTML)
+// emails via an SMTP server.
+type Client struct {
+	authData    authdata.AuthData
+	tlsConfig   *tls.Config
+	subjectTmpl *template.Template
+	security    Security
+	host        string
+	from        string
+	to          []string
+	cc          []string
+	// Bare addresses (without display names) used in the SMTP envelope.
+	fromAddr    string
+	rcptAddrs   []string
+	port        int
+	dialTimeout time.Duration
+}
+
+// NewClient returns a Client configured using the passed Config.
+// If no auth is configured, SMTP credentials are read from the environment;
+// if none are set there either, the Client won't authenticate.
+func NewClient(conf *Config) (*Client, error) {
+	if conf == nil {
+		return nil, errors.New("email config must not be nil")
+	}
+
+	if conf.Host == "" {
+		return nil, errors.New("email host must be configured")
+	}
+
+	if conf.From == "" {
+		return nil, errors.New("email from address must be configured")
+	}
+
+	if len(conf.Recipients()) == 0 {
+		return nil, errors.New("at least one email to or cc address must be configured")
+	}
+
+	security, err := conf.security()
+	if err != nil {
+		return nil, err
+	}
+
+	fromAddr, err := parseAddress(conf.From)
+	if err != nil {
+		return nil, err
+	}
+
+	rcptAddrs := make([]string, len(conf.Recipients()))
+	for i, rcpt := range conf.Recipients() {
+		if rcptAddrs[i], err = parseAddress(rcpt); err != nil {
+			return nil, err
+		}
+	}
+
+	subjectTmpl, err := template.New("emailSubject").Parse(conf.subjectTemplate())
+	if err != nil {
+		return nil, errors.WithMessage(err, "error parsing email subject template")
+	}
+
+	c := &Client{
+		host:        conf.Host,
+		port:        conf.port(),
+		security:    security,
+		from:        conf.From,
+		to:          conf.To,
+		cc:          conf.Cc,
+		fromAddr:    fromAddr,
+		rcptAddrs:   rcptAddrs,
+		subjectTmpl: subjectTmpl,
+		dialTimeout: defaultDialTimeout,
+		tlsConfig:   &tls.Config{ServerName: conf.Host, MinVersion: tls.VersionTLS12},
+	}
+
+	if conf.Auth != nil {
+		c.authData = conf.Auth
+	} else if ad, authErr := authdata.SmtpAuth(); authErr == nil {
+		c.authData = ad
+	}
+
+	// net/smtp refuses to send credentials in plaintext to anything but localhost.
+	if c.authData != nil && security == SecurityNone && !isLocalhost(conf.Host) {
+		return nil, errors.Errorf("smtp credentials can't be sent to %[1]s without encryption; use 'starttls' or 'tls' security", conf.Host)
+	}
+
+	return c, nil
+}
+
+// SetTLSConfig sets the tls.Config used for STARTTLS and implicit TLS connections.
+func (c *Client) SetTLSConfig(tlsConfig *tls.Config) *Client {
+	c.tlsConfig = tlsConfig
+	return c
+}
+
+// SetDialTimeout sets the timeout used when connecting to the SMTP server.
+// By default, this is set to 10 seconds.
+func (c *Client) SetDialTimeout(timeout time.Duration) *Client {
+	c.dialTimeout = timeout
+	return c
+}
+
+func (c *Client) smtpAuth() smtp.Auth {
+	if c.authData == nil {
+		return nil
+	}
+
+	return smtp.PlainAuth("", c.authData.Key(), c.authData.Secret(), c.host)
+}
+
+// parseAddress returns the bare address of the passed RFC 5322 address,
+// which may include a display name (ex. "Name <addr@example.com>").
+func parseAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid email address %[1]q", address)
+	}
+
+	return parsed.Address, nil
+}
+
+func isLocalhost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
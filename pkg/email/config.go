@@ -0,0 +1,127 @@
+package email
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+const (
+	DefaultPort            = 587
+	DefaultSubjectTemplate = "StuffNotifier alert"
+)
+
+// Security is the method used to secure the connection to an SMTP server.
+type Security string
+
+const (
+	// SecurityStartTLS connects in plaintext and upgrades the connection using STARTTLS.
+	SecurityStartTLS Security = "starttls"
+	// SecurityTLS connects using implicit TLS (usually on port 465).
+	SecurityTLS Security = "tls"
+	// SecurityNone never encrypts the connection.
+	// This should only be used for local SMTP relays;
+	// credentials can't be used with it unless the SMTP host is localhost.
+	SecurityNone Security = "none"
+)
+
+// Config contains the configuration for sending notifications via email.
+type Config struct {
+	Auth *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty" toml:"Auth,omitempty"`
+	// Template (text/template) used to build email subjects.
+	// The template is executed with the notification being sent.
+	// Default: "StuffNotifier alert"
+	SubjectTemplate *string `json:"subject_template,omitempty" yaml:"subject_template,omitempty" toml:"SubjectTemplate,omitempty"`
+	// Hostname of the SMTP server.
+	Host string `json:"host" yaml:"host" toml:"Host"`
+	// Connection security method: one of "starttls", "tls", or "none".
+	// Default: starttls
+	Security Security `json:"security" yaml:"security" toml:"Security"`
+	// Address which emails will be sent from,
+	// optionally with a display name (ex. "StuffNotifier <notifier@example.com>").
+	From string `json:"from" yaml:"from" toml:"From"`
+	// Addresses which emails will be sent to.
+	To []string `json:"to" yaml:"to" toml:"To"`
+	// Addresses which emails will be CC'd to.
+	Cc []string `json:"cc,omitempty" yaml:"cc,omitempty" toml:"Cc,omitempty"`
+	// Port of the SMTP server.
+	// Default: 587
+	Port int `json:"port" yaml:"port" toml:"Port"`
+}
+
+func (c Config) port() int {
+	if c.Port == 0 {
+		return DefaultPort
+	}
+
+	return c.Port
+}
+
+func (c Config) security() (Security, error) {
+	switch Security(strings.ToLower(string(c.Security))) {
+	case "", SecurityStartTLS:
+		return SecurityStartTLS, nil
+	case SecurityTLS:
+		return SecurityTLS, nil
+	case SecurityNone:
+		return SecurityNone, nil
+	default:
+		return "", errors.Errorf("unknown security method %[1]s. Allowed values: 'starttls', 'tls', 'none'", c.Security)
+	}
+}
+
+func (c Config) subjectTemplate() string {
+	if subjectTmpl, ok := utils.FromPointer(c.SubjectTemplate); ok && subjectTmpl != "" {
+		return subjectTmpl
+	}
+
+	return DefaultSubjectTemplate
+}
+
+// Recipients returns all To and Cc addresses.
+func (c Config) Recipients() []string {
+	return utils.AppendSlices(c.To, c.Cc)
+}
+
+type AuthConfig struct {
+	Username string `json:"username" yaml:"username" toml:"Username"`
+	Password string `json:"password" yaml:"password" toml:"Password"`
+}
+
+func (c AuthConfig) Account() string {
+	return ""
+}
+
+func (c AuthConfig) Key() string {
+	return c.Username
+}
+
+func (c AuthConfig) Secret() string {
+	return c.Password
+}
+
+// LoadConfig reads configuration data from the file at the passed path
+// and returns it as a fully loaded Config.
+// `confPath` is expected to be an absolute file path.
+// Supported file types: json, yaml, toml
+func LoadConfig(confPath string) (conf Config, err error) {
+	confBytes, readErr := ioutil.ReadFile(confPath)
+	if readErr != nil {
+		err = errs.ReadFileError(readErr, confPath)
+		return
+	}
+
+	confType := utils.ConfigFileTypeFromExtension(filepath.Ext(confPath))
+
+	if unmarshalErr := utils.UnmarshalConfig(confBytes, confType, &conf); unmarshalErr != nil {
+		err = errors.WithMessagef(unmarshalErr, "error unmarshalling data from file %[1]s", confPath)
+		return
+	}
+
+	return
+}
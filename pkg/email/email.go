@@ -0,0 +1,263 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+)
+
+// SendMessage sends the passed message to all configured recipients
+// as a multipart/alternative email with plaintext and HTML bodies.
+func (c *Client) SendMessage(ctx context.Context, msg messages.Message) error {
+	subject, err := c.buildSubject(msg)
+	if err != nil {
+		return err
+	}
+
+	body, err := BuildMessage(Envelope{
+		From:      c.from,
+		To:        c.to,
+		Cc:        c.cc,
+		Subject:   subject,
+		Plaintext: msg.FormatPlaintext(),
+		HTML:      messages.FormatHTML(msg),
+		Date:      time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = c.send(ctx, body); err != nil {
+		return errors.WithMessagef(err, "error sending email via %[1]s", c.addr())
+	}
+
+	return nil
+}
+
+func (c *Client) buildSubject(msg messages.Message) (string, error) {
+	b := new(bytes.Buffer)
+
+	if err := c.subjectTmpl.Execute(b, msg); err != nil {
+		return "", errors.WithMessage(err, "error executing email subject template")
+	}
+
+	// Subjects must be a single line.
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+func (c *Client) addr() string {
+	return net.JoinHostPort(c.host, strconv.Itoa(c.port))
+}
+
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: c.dialTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr())
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if c.security != SecurityTLS {
+		return conn, nil
+	}
+
+	tlsConn := tls.Client(conn, c.tlsConfig)
+	if err = tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, errors.WithMessage(err, "error performing tls handshake")
+	}
+
+	return tlsConn, nil
+}
+
+func (c *Client) send(ctx context.Context, body []byte) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if c.security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+
+		if err = client.StartTLS(c.tlsConfig); err != nil {
+			return errors.WithMessage(err, "error starting tls")
+		}
+	}
+
+	if auth := c.smtpAuth(); auth != nil {
+		if err = client.Auth(auth); err != nil {
+			return errors.WithMessage(err, "error authenticating")
+		}
+	}
+
+	if err = client.Mail(c.fromAddr); err != nil {
+		return err
+	}
+
+	for _, rcpt := range c.rcptAddrs {
+		if err = client.Rcpt(rcpt); err != nil {
+			return errors.WithMessagef(err, "error adding recipient %[1]s", rcpt)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(body); err != nil {
+		return err
+	}
+
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// Envelope contains the headers and bodies used to build an email.
+type Envelope struct {
+	Date      time.Time
+	From      string
+	Subject   string
+	Plaintext string
+	HTML      string
+	To        []string
+	Cc        []string
+}
+
+// BuildMessage builds an RFC 5322 email with a multipart/alternative body
+// containing the Envelope's plaintext and HTML content.
+// An error is returned if any of the Envelope's header values contain line breaks.
+func BuildMessage(env Envelope) ([]byte, error) {
+	if err := env.validateHeaders(); err != nil {
+		return nil, err
+	}
+
+	var (
+		msg  bytes.Buffer
+		body bytes.Buffer
+	)
+
+	mw := multipart.NewWriter(&body)
+
+	if err := writePart(mw, "text/plain; charset=utf-8", env.Plaintext); err != nil {
+		return nil, err
+	}
+
+	if err := writePart(mw, "text/html; charset=utf-8", env.HTML); err != nil {
+		return nil, err
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := []struct{ key, value string }{
+		{"From", env.From},
+		{"To", strings.Join(env.To, ", ")},
+		{"Cc", strings.Join(env.Cc, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", env.Subject)},
+		{"Date", env.Date.Format(time.RFC1123Z)},
+		{"Message-ID", messageId(env.From)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+
+	for _, h := range headers {
+		if h.value == "" {
+			continue
+		}
+
+		fmt.Fprintf(&msg, "%[1]s: %[2]s\r\n", h.key, h.value)
+	}
+
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// validateHeaders returns an error if any of the Envelope's header values
+// contain a line break, which could otherwise be used to inject headers.
+func (env Envelope) validateHeaders() error {
+	headers := map[string][]string{
+		"From":    {env.From},
+		"To":      env.To,
+		"Cc":      env.Cc,
+		"Subject": {env.Subject},
+	}
+
+	for key, values := range headers {
+		for _, value := range values {
+			if strings.ContainsAny(value, "\r\n") {
+				return errors.Errorf("email %[1]s header must not contain line breaks", key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func writePart(mw *multipart.Writer, contentType, content string) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	pw, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	qw := quotedprintable.NewWriter(pw)
+	if _, err = qw.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	return qw.Close()
+}
+
+func messageId(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at != -1 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("<%[1]s@%[2]s>", hex.EncodeToString(b), domain)
+}
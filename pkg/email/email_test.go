@@ -0,0 +1,333 @@
+package email_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/email"
+)
+
+const (
+	testUsername string = "user"
+	testPassword string = "pass"
+)
+
+type receivedMail struct {
+	from string
+	auth string
+	rcpt []string
+	data string
+}
+
+// smtpServer is a tiny, in-process SMTP server which
+// records all mail sent to it.
+type smtpServer struct {
+	listener net.Listener
+	received []receivedMail
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+}
+
+func newSmtpServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	s := &smtpServer{listener: l}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	t.Cleanup(func() {
+		_ = l.Close()
+		s.wg.Wait()
+	})
+
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) mails() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]receivedMail{}, s.received...)
+}
+
+func (s *smtpServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.handle(conn)
+	}
+}
+
+//nolint:gocognit
+func (s *smtpServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	var m receivedMail
+
+	reply("220 localhost ESMTP test")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch cmd {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			parts := strings.Fields(line)
+			decoded, _ := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+			m.auth = string(decoded)
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			m.from = strings.TrimPrefix(line, "MAIL FROM:")
+			reply("250 OK")
+		case "RCPT":
+			m.rcpt = append(m.rcpt, strings.TrimPrefix(line, "RCPT TO:"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				dataLine, dataErr := r.ReadString('\n')
+				if dataErr != nil {
+					return
+				}
+
+				if dataLine == ".\r\n" {
+					break
+				}
+
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+
+			m.data = data.String()
+
+			s.mu.Lock()
+			s.received = append(s.received, m)
+			s.mu.Unlock()
+
+			m = receivedMail{}
+
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	srv := newSmtpServer(t)
+
+	conf := &email.Config{
+		Host:            "127.0.0.1",
+		Port:            srv.port(),
+		Security:        email.SecurityNone,
+		From:            "StuffNotifier <notifier@example.com>",
+		To:              []string{"a@example.com", "b@example.com"},
+		Cc:              []string{"c@example.com"},
+		SubjectTemplate: utils.ToPointer("Spot price: {{ .BaseCurrency }}/{{ .QuoteCurrency }}"),
+		Auth:            &email.AuthConfig{Username: testUsername, Password: testPassword},
+	}
+
+	client, err := email.NewClient(conf)
+	assert.NoError(t, err)
+
+	msg := messages.SpotPriceAlert{
+		EventTime:     time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+		SpotPrice:     decimal.NewFromInt(1800),
+		BaseAmount:    decimal.NewFromInt(1),
+		BaseCurrency:  "ETH",
+		QuoteCurrency: "USD",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, client.SendMessage(ctx, msg))
+
+	received := srv.mails()
+	if !assert.Len(t, received, 1) {
+		return
+	}
+
+	got := received[0]
+
+	assert.Equal(t, "\x00"+testUsername+"\x00"+testPassword, got.auth)
+	assert.Equal(t, "<notifier@example.com>", got.from)
+	assert.Equal(t, []string{"<a@example.com>", "<b@example.com>", "<c@example.com>"}, got.rcpt)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(got.data))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "StuffNotifier <notifier@example.com>", parsed.Header.Get("From"))
+	assert.Equal(t, "Spot price: ETH/USD", parsed.Header.Get("Subject"))
+	assert.Equal(t, "a@example.com, b@example.com", parsed.Header.Get("To"))
+	assert.Equal(t, "c@example.com", parsed.Header.Get("Cc"))
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := make(map[string]string)
+
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, partErr := mr.NextPart()
+		if partErr != nil {
+			break
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		content, _ := ioutil.ReadAll(quotedprintable.NewReader(part))
+		parts[partType] = strings.ReplaceAll(string(content), "\r\n", "\n")
+	}
+
+	assert.Equal(t, msg.FormatPlaintext(), parts["text/plain"])
+	assert.Equal(t, msg.FormatHTML(), parts["text/html"])
+	assert.Contains(t, parts["text/html"], "<strong>ETH - USD</strong>")
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		conf    *email.Config
+		name    string
+		wantErr bool
+	}{
+		{
+			name:    "nil config",
+			wantErr: true,
+		},
+		{
+			name:    "no recipients",
+			conf:    &email.Config{Host: "localhost", From: "a@example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "bad security",
+			conf:    &email.Config{Host: "localhost", From: "a@example.com", To: []string{"b@example.com"}, Security: "ssl"},
+			wantErr: true,
+		},
+		{
+			name:    "bad subject template",
+			conf:    &email.Config{Host: "localhost", From: "a@example.com", To: []string{"b@example.com"}, SubjectTemplate: utils.ToPointer("{{ .Foo")},
+			wantErr: true,
+		},
+		{
+			name:    "bad from address",
+			conf:    &email.Config{Host: "localhost", From: "a@", To: []string{"b@example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "bad recipient address",
+			conf:    &email.Config{Host: "localhost", From: "a@example.com", To: []string{"b@example.com"}, Cc: []string{"c"}},
+			wantErr: true,
+		},
+		{
+			name: "auth without encryption",
+			conf: &email.Config{
+				Host:     "smtp.example.com",
+				From:     "a@example.com",
+				To:       []string{"b@example.com"},
+				Security: email.SecurityNone,
+				Auth:     &email.AuthConfig{Username: testUsername, Password: testPassword},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid",
+			conf: &email.Config{Host: "localhost", Port: 465, From: "a@example.com", To: []string{"b@example.com"}, Security: email.SecurityTLS},
+		},
+		{
+			name: "display names",
+			conf: &email.Config{Host: "localhost", From: "A <a@example.com>", To: []string{"B <b@example.com>"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := email.NewClient(tt.conf)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBuildMessage_HeaderInjection(t *testing.T) {
+	tests := []struct {
+		name string
+		env  email.Envelope
+	}{
+		{
+			name: "subject",
+			env:  email.Envelope{From: "a@example.com", To: []string{"b@example.com"}, Subject: "alert\r\nBcc: c@example.com"},
+		},
+		{
+			name: "from",
+			env:  email.Envelope{From: "a@example.com\nBcc: c@example.com", To: []string{"b@example.com"}},
+		},
+		{
+			name: "cc",
+			env:  email.Envelope{From: "a@example.com", To: []string{"b@example.com"}, Cc: []string{"c@example.com\r"}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := email.BuildMessage(tt.env)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package errs
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors matched (using errors.Is) by an ApiError,
+// based on its status code.
+var (
+	ErrApiUnauthorized = errors.New("api request unauthorized")
+	ErrApiNotFound     = errors.New("api resource not found")
+	ErrApiRateLimited  = errors.New("api rate limit exceeded")
+	ErrApiServerError  = errors.New("api server error")
+)
+
+// ApiError is returned when an API responds with a non-2xx status code.
+type ApiError struct {
+	// Service which returned the error, ex. "flightaware".
+	Service string
+	Title   string
+	Reason  string
+	Detail  string
+	// Time to wait before retrying, from the response's Retry-After header.
+	// Only set for rate limited responses.
+	RetryAfter time.Duration
+	StatusCode int
+}
+
+// NewApiError returns an ApiError for a response with the passed status code,
+// parsing the passed Retry-After header value if the response was rate limited.
+func NewApiError(service string, statusCode int, retryAfter string) *ApiError {
+	e := &ApiError{
+		Service:    service,
+		StatusCode: statusCode,
+	}
+
+	if statusCode == http.StatusTooManyRequests {
+		e.RetryAfter = ParseRetryAfter(retryAfter, time.Now())
+	}
+
+	return e
+}
+
+func (e *ApiError) Error() string {
+	msg := e.Detail
+	if msg == "" {
+		msg = e.Reason
+	}
+	if msg == "" {
+		msg = e.Title
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	return fmt.Sprintf("%[1]s api error (status %[2]d): %[3]s", e.Service, e.StatusCode, msg)
+}
+
+// Is allows matching an ApiError against the sentinel errors
+// ErrApiUnauthorized, ErrApiNotFound, ErrApiRateLimited, and ErrApiServerError.
+func (e *ApiError) Is(target error) bool {
+	switch target {
+	case ErrApiUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrApiNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrApiRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrApiServerError:
+		return e.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// Temporary returns true if the request which caused the error
+// may succeed if retried later.
+func (e *ApiError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsFatalApiError returns true if err is (or wraps) an ApiError
+// which won't be resolved by retrying the request.
+func IsFatalApiError(err error) bool {
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && !apiErr.Temporary()
+}
+
+// RetryAfter returns the Retry-After duration of a rate limited ApiError,
+// if err is (or wraps) one which has it set.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+
+	return 0, false
+}
+
+// ParseRetryAfter parses the value of a Retry-After header,
+// which is either a number of seconds or an HTTP date.
+// 0 is returned if the value is empty, invalid, or in the past.
+func ParseRetryAfter(val string, now time.Time) time.Duration {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(val); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(val); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,97 @@
+package errs_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+func TestApiError(t *testing.T) {
+	tests := []struct {
+		wantIs     error
+		name       string
+		retryAfter string
+		statusCode int
+		wantRetry  time.Duration
+		wantFatal  bool
+	}{
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			wantIs:     errs.ErrApiUnauthorized,
+			wantFatal:  true,
+		},
+		{
+			name:       "forbidden",
+			statusCode: http.StatusForbidden,
+			wantIs:     errs.ErrApiUnauthorized,
+			wantFatal:  true,
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			wantIs:     errs.ErrApiNotFound,
+			wantFatal:  true,
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: "120",
+			wantIs:     errs.ErrApiRateLimited,
+			wantRetry:  2 * time.Minute,
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusBadGateway,
+			retryAfter: "120",
+			wantIs:     errs.ErrApiServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := errors.WithMessage(errs.NewApiError("flightaware", tt.statusCode, tt.retryAfter), "error fetching flight")
+
+			assert.ErrorIs(t, err, tt.wantIs)
+			assert.Equal(t, tt.wantFatal, errs.IsFatalApiError(err))
+
+			retryAfter, ok := errs.RetryAfter(err)
+			assert.Equal(t, tt.wantRetry > 0, ok)
+			assert.Equal(t, tt.wantRetry, retryAfter)
+		})
+	}
+
+	assert.False(t, errs.IsFatalApiError(errors.New("connection reset")))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 5, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		val  string
+		want time.Duration
+	}{
+		{name: "empty"},
+		{name: "seconds", val: "30", want: 30 * time.Second},
+		{name: "negative seconds", val: "-30"},
+		{name: "http date", val: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{name: "past http date", val: now.Add(-time.Minute).Format(http.TimeFormat)},
+		{name: "invalid", val: "soon"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errs.ParseRetryAfter(tt.val, now))
+		})
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/jalavosus/stuffnotifier/internal/env"
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/authdata"
+	"github.com/jalavosus/stuffnotifier/pkg/clock"
 	"github.com/jalavosus/stuffnotifier/pkg/errs"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 )
 
 // Client is a superficial wrapper around
@@ -20,30 +23,145 @@ import (
 type Client struct {
 	authData   authdata.AuthData
 	httpClient *http.Client
+	transport  *transport.Transport
+	// http.RoundTripper which the transport sends requests through,
+	// or nil for http.DefaultTransport.
+	baseTransport http.RoundTripper
+	clock         clock.Clock
+	baseUrl       string
+	maxPages      int
 }
 
 func NewClient(authData authdata.AuthData) *Client {
+	httpClient, t := transport.NewClient(nil, defaultHttpTimeout, DefaultTransportConfig())
+
 	return &Client{
 		authData:   authData,
-		httpClient: utils.HttpClientWithTimeout(defaultHttpTimeout),
+		httpClient: httpClient,
+		transport:  t,
+		clock:      clock.Real(),
+		baseUrl:    defaultBaseUrl(),
+		maxPages:   DefaultMaxPages,
 	}
 }
 
-// SetHttpTimeout sets the timeout of the Client's http.Client instance.
+// defaultBaseUrl returns the base URL set in the environment, or DefaultBaseUrl.
+func defaultBaseUrl() string {
+	return env.BaseUrl(env.FlightAwareBaseUrl, DefaultBaseUrl)
+}
+
+// SetHttpTimeout sets the timeout of each attempt of the Client's requests.
+// Retried requests get the full timeout for every attempt.
 // By default, this is set to 10 seconds.
 func (c *Client) SetHttpTimeout(timeout time.Duration) *Client {
-	c.httpClient.Timeout = timeout
+	c.transport.SetAttemptTimeout(timeout)
 	return c
 }
 
-func (c *Client) FlightInformation(ctx context.Context, params FlightInformationParams) (*FlightDataResponse, error) {
-	var response *FlightDataResponse
+// SetBaseUrl sets the base URL (scheme, host, and path prefix) of the Client's requests,
+// ex. http://localhost:8080/aeroapi.
+// An empty base URL resets it to the one set in the environment, or DefaultBaseUrl.
+// Returns an error if the base URL is invalid.
+func (c *Client) SetBaseUrl(baseUrl string) (*Client, error) {
+	if baseUrl == "" {
+		baseUrl = defaultBaseUrl()
+	}
 
-	endpoint, _, err := params.UrlParams()
+	u, err := utils.ParseBaseUrl(baseUrl)
 	if err != nil {
 		return nil, err
 	}
 
+	c.baseUrl = u.String()
+	return c, nil
+}
+
+// SetClock sets the clock used to find the closest flight when no flight date is passed.
+// A nil clock resets it to the system clock.
+func (c *Client) SetClock(clk clock.Clock) *Client {
+	if clk == nil {
+		clk = clock.Real()
+	}
+
+	c.clock = clk
+	return c
+}
+
+// SetTransportConfig replaces the Client's transport with one using the passed config.
+// Unset fields are set to their DefaultTransportConfig values.
+func (c *Client) SetTransportConfig(conf transport.Config) *Client {
+	c.httpClient, c.transport = transport.NewClient(c.baseTransport, c.transport.AttemptTimeout(), conf.WithDefaults(DefaultTransportConfig()))
+	return c
+}
+
+// SetBaseTransport sets the http.RoundTripper which the Client's transport sends requests through,
+// ex. a transport.Recorder or transport.Replayer, keeping the transport's config.
+// A nil base resets it to http.DefaultTransport.
+func (c *Client) SetBaseTransport(base http.RoundTripper) *Client {
+	c.baseTransport = base
+	return c.SetTransportConfig(c.transport.Config())
+}
+
+// Usage returns the number and cost of every AeroAPI request sent by the Client.
+// The usage of a single call can be tracked by passing it a context
+// returned by transport.WithUsage.
+func (c *Client) Usage() transport.UsageStats {
+	if c.transport == nil {
+		return transport.UsageStats{}
+	}
+
+	return c.transport.Usage()
+}
+
+// SetMaxPages sets the maximum number of result pages fetched by FlightInformation
+// when searching by designator or registration. Values less than 1 reset it to DefaultMaxPages.
+func (c *Client) SetMaxPages(maxPages int) *Client {
+	if maxPages < 1 {
+		maxPages = DefaultMaxPages
+	}
+
+	c.maxPages = maxPages
+	return c
+}
+
+// FlightInformation returns the flights matching the passed params,
+// following pagination cursors for up to the Client's max pages
+// (or a single page, if fetching by fa_flight_id).
+// The returned response's Flights contains the flights from every fetched page,
+// and its Links.Next is set if more pages are available.
+func (c *Client) FlightInformation(ctx context.Context, params FlightInformationParams) (*FlightDataResponse, error) {
+	var (
+		response = new(FlightDataResponse)
+		pages    = c.FlightInformationPages(params)
+	)
+
+	for pages.Next(ctx) {
+		page := pages.Page()
+
+		response.Flights = append(response.Flights, page.Flights...)
+		response.Links = page.Links
+		response.NumPages++
+	}
+
+	if err := pages.Err(); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// FlightInformationPages returns a FlightPages iterating over
+// the result pages of the flights matching the passed params.
+func (c *Client) FlightInformationPages(params FlightInformationParams) *FlightPages {
+	return &FlightPages{
+		client: c,
+		params: params,
+	}
+}
+
+func (c *Client) flightInformationPage(ctx context.Context, endpoint string) (*FlightDataResponse, error) {
+	var response *FlightDataResponse
+
 	resp, err := c.httpRequest(ctx, endpoint)
 	if err != nil {
 		return nil, err
@@ -53,6 +171,10 @@ func (c *Client) FlightInformation(ctx context.Context, params FlightInformation
 		return nil, errs.HttpUnmarshalResponseBodyError(err)
 	}
 
+	if response == nil {
+		response = new(FlightDataResponse)
+	}
+
 	return response, nil
 }
 
@@ -91,7 +213,7 @@ func (c *Client) FlightIdentifiers(ctx context.Context, params FlightInformation
 		return nil, "", ErrNoFlightsFound
 	}
 
-	flight, ok := FindFlightFromParams(flights, params)
+	flight, ok := FindFlightFromParamsAt(flights, params, c.clock.Now())
 	if ok {
 		response = &flight.Identifiers
 		faFlightId = flight.FlightId
@@ -101,7 +223,7 @@ func (c *Client) FlightIdentifiers(ctx context.Context, params FlightInformation
 }
 
 func (c *Client) httpRequest(ctx context.Context, endpoint string) ([]byte, error) {
-	uri := utils.BuildRequestEndpoint(baseApiEndpoint, endpoint)
+	uri := utils.BuildRequestEndpoint(c.baseUrl, endpoint)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
@@ -124,5 +246,24 @@ func (c *Client) httpRequest(ctx context.Context, endpoint string) ([]byte, erro
 		return nil, errs.HttpReadBodyError(err)
 	}
 
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, newApiError(resp, respBody)
+	}
+
 	return respBody, nil
 }
+
+// newApiError builds an errs.ApiError from an unsuccessful response,
+// using its ApiResponseError body if it has one.
+func newApiError(resp *http.Response, respBody []byte) *errs.ApiError {
+	apiErr := errs.NewApiError(apiServiceName, resp.StatusCode, resp.Header.Get("Retry-After"))
+
+	var body ApiResponseError
+	if jsonErr := json.Unmarshal(respBody, &body); jsonErr == nil {
+		apiErr.Title = body.Title
+		apiErr.Reason = body.Reason
+		apiErr.Detail = body.Detail
+	}
+
+	return apiErr
+}
@@ -0,0 +1,135 @@
+package flightaware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+func TestClient_httpRequest_ApiError(t *testing.T) {
+	tests := []struct {
+		wantIs     error
+		name       string
+		body       string
+		retryAfter string
+		wantDetail string
+		statusCode int
+		wantRetry  time.Duration
+		wantFatal  bool
+	}{
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"title": "Unauthorized", "reason": "UNAUTHORIZED", "detail": "invalid API key", "status": 401}`,
+			wantIs:     errs.ErrApiUnauthorized,
+			wantDetail: "invalid API key",
+			wantFatal:  true,
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			body:       `{"title": "Not Found", "reason": "NOT_FOUND", "detail": "unknown flight", "status": 404}`,
+			wantIs:     errs.ErrApiNotFound,
+			wantDetail: "unknown flight",
+			wantFatal:  true,
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: "60",
+			wantIs:     errs.ErrApiRateLimited,
+			wantRetry:  time.Minute,
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusServiceUnavailable,
+			body:       "<html>Service Unavailable</html>",
+			wantIs:     errs.ErrApiServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			serverUrl, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := NewClient(&AuthConfig{ApiKey: "test-key"})
+			client.httpClient = server.Client()
+			client.httpClient.Transport = rewriteTransport{base: client.httpClient.Transport, serverUrl: serverUrl}
+
+			_, err = client.FlightInformation(context.Background(), FlightInformationParams{FlightDesignator: utils.ToPointer("UAL2614")})
+			if !assert.Error(t, err) {
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantIs)
+			assert.Equal(t, tt.wantFatal, errs.IsFatalApiError(err))
+
+			var apiErr *errs.ApiError
+			if assert.True(t, errors.As(err, &apiErr)) {
+				assert.Equal(t, tt.statusCode, apiErr.StatusCode)
+				assert.Equal(t, tt.wantDetail, apiErr.Detail)
+				assert.Equal(t, tt.wantRetry, apiErr.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestClient_SetBaseUrl(t *testing.T) {
+	var gotPath, gotApiKey string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotApiKey = r.Header.Get(apiKeyHeader)
+
+		_, _ = w.Write([]byte(`{"airport_code": "KSFO", "name": "San Francisco Int'l"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&AuthConfig{ApiKey: "test-key"}).SetBaseUrl(server.URL + "/aeroapi")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	airport, err := client.AirportInformation(context.Background(), "KSFO")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "/aeroapi/airports/KSFO", gotPath)
+	assert.Equal(t, "test-key", gotApiKey)
+	assert.Equal(t, "San Francisco Int'l", airport.Name)
+
+	t.Setenv("FLIGHTAWARE_BASE_URL", "http://localhost:8080/aeroapi")
+
+	client, err = client.SetBaseUrl("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "http://localhost:8080/aeroapi", client.baseUrl)
+	}
+
+	_, err = client.SetBaseUrl("ftp://localhost:8080/aeroapi")
+	assert.Error(t, err)
+}
@@ -1,77 +1,454 @@
 package flightaware
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/pelletier/go-toml"
 	"github.com/pkg/errors"
 
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/errs"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 )
 
 const (
 	DefaultPollInterval     = time.Minute
 	DefaultPreArrivalOffset = time.Hour
+	DefaultDelayThreshold   = 15 * time.Minute
+	DefaultDelayIncrement   = 15 * time.Minute
+	// DefaultMinConnectionTime is the default minimum connection time for itineraries.
+	DefaultMinConnectionTime = 45 * time.Minute
+	// DefaultMinTurnaround is the default minimum turnaround time for a flight's inbound aircraft.
+	DefaultMinTurnaround    = 45 * time.Minute
+	DefaultInboundIncrement = 15 * time.Minute
+	// DefaultMaxPages is the default maximum number of result pages
+	// fetched for a single flight search by designator or registration.
+	DefaultMaxPages = 5
 )
 
 const (
 	defaultHttpTimeout = 10 * time.Second
 )
 
+// DefaultTransportConfig returns the default retry and rate limiting
+// configuration of AeroAPI requests.
+func DefaultTransportConfig() transport.Config {
+	conf := transport.DefaultConfig()
+	conf.RateLimit = 1
+	conf.Burst = 5
+
+	return conf
+}
+
 // Config contains the configuration for a FlightAware poller.
 type Config struct {
 	Auth *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty" toml:"Auth,omitempty"`
 	// Interval between flight data checks.
 	// Default: 1 minute
 	PollInterval time.Duration `json:"poll_interval" yaml:"poll_interval" toml:"PollInterval"`
+	// Adapts how often flights are polled to their phase. When enabled,
+	// flights are only polled every PollInterval around departure and arrival.
+	PollSchedule PollScheduleConfig `json:"poll_schedule" yaml:"poll_schedule" toml:"PollSchedule"`
+	// Maximum number of result pages to fetch when searching for a flight
+	// by designator or registration. Each page is a separate (billed) AeroAPI request.
+	// Default: 5
+	MaxPages int `json:"max_pages,omitempty" yaml:"max_pages,omitempty" toml:"MaxPages,omitempty"`
+	// Base URL (scheme, host, and path prefix) of AeroAPI requests,
+	// ex. to send them to a local stand-in or through a proxy.
+	// Default: FLIGHTAWARE_BASE_URL if set, otherwise https://aeroapi.flightaware.com/aeroapi
+	BaseUrl string `json:"base_url,omitempty" yaml:"base_url,omitempty" toml:"BaseUrl,omitempty"`
+	// Retry, rate limiting, and cost accounting configuration of AeroAPI requests.
+	// Set CostPerRequest to the per-query price of your AeroAPI plan
+	// to have the cost of polling logged.
+	// Default: up to 3 retries, and 1 request per second with bursts of up to 5 requests.
+	Transport transport.Config `json:"transport,omitempty" yaml:"transport,omitempty" toml:"Transport,omitempty"`
+	// Records AeroAPI traffic to a cassette file, or replays it from one.
+	// When replaying, flights are polled as fast as the recorded responses allow,
+	// and notifications are timed using the recorded timestamps.
+	Cassette transport.CassetteConfig `json:"cassette,omitempty" yaml:"cassette,omitempty" toml:"Cassette,omitempty"`
 	// Configuration for various notifications.
 	Notifications NotificationsConfig `json:"notifications" yaml:"notifications" toml:"Notifications"`
+	// Flights to track. Flights passed on the command line
+	// are tracked in addition to these.
+	Flights []FlightConfig `json:"flights,omitempty" yaml:"flights,omitempty" toml:"Flights,omitempty"`
+	// Itineraries to track. Each leg of an itinerary is tracked as a flight,
+	// and alerts are sent when a connection between legs is at risk.
+	Itineraries []ItineraryConfig `json:"itineraries,omitempty" yaml:"itineraries,omitempty" toml:"Itineraries,omitempty"`
+}
+
+// FlightConfig contains configuration data for a single tracked flight.
+type FlightConfig struct {
+	// Flight number (ex. UA1614) or FlightAware flight ID of the flight,
+	// or registration (ex. N12345) of an aircraft to watch.
+	Identifier string `json:"identifier" yaml:"identifier" toml:"Identifier"`
+	// Type of Identifier: "flight_number", "flightaware_id", or "registration".
+	// Every flight filed for a registration is tracked, as it is filed.
+	// Default: flight_number
+	IdentifierType string `json:"identifier_type,omitempty" yaml:"identifier_type,omitempty" toml:"IdentifierType,omitempty"`
+	// Date (YYYY-MM-DD, UTC) of the flight's scheduled departure.
+	// Only used when Identifier is a flight number.
+	// If unset, the closest flight is tracked.
+	Date string `json:"date,omitempty" yaml:"date,omitempty" toml:"Date,omitempty"`
+	// Names of recipient groups (configured in the poller config)
+	// to notify about this flight.
+	// If unset, all of the poller's default notifiers are used.
+	Recipients []string `json:"recipients,omitempty" yaml:"recipients,omitempty" toml:"Recipients,omitempty"`
+}
+
+// ItineraryConfig contains configuration data for a trip made up of connecting flights.
+type ItineraryConfig struct {
+	// Name of the itinerary, included in connection alerts.
+	Name string `json:"name,omitempty" yaml:"name,omitempty" toml:"Name,omitempty"`
+	// Flight legs of the itinerary, in order of travel.
+	// Each leg must depart from the airport the leg before it arrives at.
+	// Legs identified by flight number must have a Date set,
+	// so that each leg is pinned to the correct flight.
+	Legs []FlightConfig `json:"legs" yaml:"legs" toml:"Legs"`
+	// A connection alert is sent when the time between the estimated arrival
+	// of an inbound leg and the estimated departure of the following leg
+	// drops below MinConnectionTime.
+	// Default: 45 minutes
+	MinConnectionTime time.Duration `json:"min_connection_time,omitempty" yaml:"min_connection_time,omitempty" toml:"MinConnectionTime,omitempty"`
+	// Names of recipient groups (configured in the poller config)
+	// to notify about this itinerary. Legs without their own recipients
+	// are also notified to these groups.
+	// If unset, all of the poller's default notifiers are used.
+	Recipients []string `json:"recipients,omitempty" yaml:"recipients,omitempty" toml:"Recipients,omitempty"`
+}
+
+// Validate returns an error if the itinerary has fewer than two legs,
+// or if any leg identified by flight number has no date set.
+func (c ItineraryConfig) Validate() error {
+	if len(c.Legs) < 2 {
+		return errors.Errorf("itinerary %[1]s must have at least two legs", c.Name)
+	}
+
+	for _, leg := range c.Legs {
+		idType, err := ParseIdentifierType(leg.IdentifierType)
+		if err != nil {
+			return errors.WithMessagef(err, "itinerary %[1]s", c.Name)
+		}
+
+		if idType == RegistrationIdent {
+			return errors.Errorf("itinerary %[1]s: leg %[2]s must be a single flight, not a registration", c.Name, leg.Identifier)
+		}
+
+		if idType != FaFlightIdIdent && leg.Date == "" {
+			return errors.Errorf("itinerary %[1]s: leg %[2]s must have a date set", c.Name, leg.Identifier)
+		}
+	}
+
+	return nil
+}
+
+// ConnectionTime returns the itinerary's minimum connection time,
+// or DefaultMinConnectionTime if it isn't set.
+func (c ItineraryConfig) ConnectionTime() time.Duration {
+	if c.MinConnectionTime <= 0 {
+		return DefaultMinConnectionTime
+	}
+
+	return c.MinConnectionTime
+}
+
+// LegFlights returns the itinerary's legs, with the itinerary's
+// recipients set on any legs which don't have their own.
+func (c ItineraryConfig) LegFlights() []FlightConfig {
+	legs := make([]FlightConfig, len(c.Legs))
+
+	for i, leg := range c.Legs {
+		if len(leg.Recipients) == 0 {
+			leg.Recipients = c.Recipients
+		}
+
+		legs[i] = leg
+	}
+
+	return legs
+}
+
+// IsRegistration returns true if the config is for an aircraft registration,
+// rather than a single flight.
+func (c FlightConfig) IsRegistration() bool {
+	idType, err := ParseIdentifierType(c.IdentifierType)
+	return err == nil && idType == RegistrationIdent
+}
+
+// InformationParams returns the FlightInformationParams used to fetch the configured flight.
+func (c FlightConfig) InformationParams() (params FlightInformationParams, err error) {
+	if c.Identifier == "" {
+		err = errors.New("flight identifier must not be empty")
+		return
+	}
+
+	idType, err := ParseIdentifierType(c.IdentifierType)
+	if err != nil {
+		return
+	}
+
+	switch idType {
+	case FaFlightIdIdent:
+		params.FlightId = utils.ToPointer(c.Identifier)
+	case RegistrationIdent:
+		params.Registration = utils.ToPointer(strings.ToUpper(c.Identifier))
+	default:
+		params.FlightDesignator = utils.ToPointer(c.Identifier)
+
+		if c.Date != "" {
+			flightDate, parseErr := time.Parse(FlightDateFormatLayout, c.Date)
+			if parseErr != nil {
+				err = errors.WithMessagef(parseErr, "invalid date %[1]s for flight %[2]s", c.Date, c.Identifier)
+				return
+			}
+
+			params.FlightDate = utils.ToPointer(flightDate.UTC())
+		}
+	}
+
+	return
 }
 
 func DefaultConfig() Config {
 	return Config{
 		Auth:          nil,
 		PollInterval:  DefaultPollInterval,
+		PollSchedule:  DefaultPollScheduleConfig(),
+		MaxPages:      DefaultMaxPages,
 		Notifications: DefaultNotificationsConfig(),
 	}
 }
 
+// loadedConfigDefaults returns the Config which loaded configs are merged over:
+// notifications which are on by default, but which configs written before they were added
+// don't mention, are set to their defaults so that loading such a config doesn't turn them off.
+func loadedConfigDefaults() Config {
+	defaults := DefaultNotificationsConfig()
+
+	return Config{
+		Notifications: NotificationsConfig{
+			Delay:           defaults.Delay,
+			InboundAircraft: defaults.InboundAircraft,
+			GateChange:      defaults.GateChange,
+			Cancellation:    defaults.Cancellation,
+			Diversion:       defaults.Diversion,
+		},
+	}
+}
+
+// UnmarshalJSON unmarshals the passed JSON data over loadedConfigDefaults.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type config Config
+
+	conf := config(loadedConfigDefaults())
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return err
+	}
+
+	*c = Config(conf)
+
+	return nil
+}
+
+// UnmarshalYAML unmarshals YAML data over loadedConfigDefaults.
+func (c *Config) UnmarshalYAML(unmarshal func(any) error) error {
+	type config Config
+
+	conf := config(loadedConfigDefaults())
+	if err := unmarshal(&conf); err != nil {
+		return err
+	}
+
+	*c = Config(conf)
+
+	return nil
+}
+
+// UnmarshalTOML unmarshals the passed TOML table over loadedConfigDefaults.
+func (c *Config) UnmarshalTOML(data any) error {
+	type config Config
+
+	table, ok := data.(map[string]any)
+	if !ok {
+		return errors.Errorf("expected a table, got %[1]T", data)
+	}
+
+	tree, err := toml.TreeFromMap(table)
+	if err != nil {
+		return err
+	}
+
+	conf := config(loadedConfigDefaults())
+	if err := tree.Unmarshal(&conf); err != nil {
+		return err
+	}
+
+	*c = Config(conf)
+
+	return nil
+}
+
 // NotificationsConfig contains configuration data respective to
 // which notifications will be sent for certain flight events.
 type NotificationsConfig struct {
 	PreDeparture  PreEventConfig `json:"pre_departure" yaml:"pre_departure" toml:"PreDeparture"`
 	PreArrival    PreEventConfig `json:"pre_arrival" yaml:"pre_arrival" toml:"PreArrival"`
+	Delay         DelayConfig    `json:"delay" yaml:"delay" toml:"Delay"`
 	Takeoff       bool           `json:"takeoff" yaml:"takeoff" toml:"Takeoff"`
 	Landing       bool           `json:"landing" yaml:"landing" toml:"Landing"`
 	GateArrival   bool           `json:"gate_arrival" yaml:"gate_arrival" toml:"GateArrival"`
 	BaggageClaim  bool           `json:"baggage_claim" yaml:"baggage_claim" toml:"BaggageClaim"`
 	GateDeparture bool           `json:"gate_departure" yaml:"gate_departure" toml:"GateDeparture"`
 	UseLocalTime  bool           `json:"use_local_time" yaml:"use_local_time" toml:"UseLocalTime"`
+	// If true, a notification is sent when a flight's
+	// departure or arrival gate/terminal changes.
+	GateChange bool `json:"gate_change" yaml:"gate_change" toml:"GateChange"`
+	// If true, a notification is sent when a flight is cancelled.
+	Cancellation bool `json:"cancellation" yaml:"cancellation" toml:"Cancellation"`
+	// If true, a notification is sent when a flight is diverted.
+	Diversion bool `json:"diversion" yaml:"diversion" toml:"Diversion"`
+	// Configuration for notifications about a flight's inbound aircraft running late.
+	InboundAircraft InboundAircraftConfig `json:"inbound_aircraft" yaml:"inbound_aircraft" toml:"InboundAircraft"`
+}
+
+// InboundAircraftConfig contains configuration details
+// for notifications about a flight's inbound aircraft.
+type InboundAircraftConfig struct {
+	// If true, a flight's inbound flight is followed, and a notification is sent
+	// when the aircraft is predicted to arrive too late for the flight to depart on time.
+	// Default: true
+	Enabled bool `json:"enabled" yaml:"enabled" toml:"Enabled"`
+	// Minimum time needed between the inbound flight's arrival at the gate
+	// and the flight's departure.
+	// Default: 45 minutes
+	MinTurnaround time.Duration `json:"min_turnaround" yaml:"min_turnaround" toml:"MinTurnaround"`
+	// If greater than 0, a further notification is sent every time the predicted
+	// departure delay grows by at least Increment since the last notification.
+	// Default: 15 minutes
+	Increment time.Duration `json:"increment" yaml:"increment" toml:"Increment"`
+}
+
+// ShouldNotify returns true if an inbound aircraft notification should be sent for
+// the passed predicted departure delay, given the predicted delay included in the last
+// inbound aircraft notification (or 0 if none has been sent).
+func (c InboundAircraftConfig) ShouldNotify(predictedDelay, lastNotified time.Duration) bool {
+	switch {
+	case !c.Enabled, predictedDelay <= 0:
+		return false
+	case lastNotified <= 0:
+		return true
+	case c.Increment <= 0:
+		return false
+	default:
+		return predictedDelay-lastNotified >= c.Increment
+	}
+}
+
+// DelayConfig contains configuration details
+// for flight delay notifications.
+type DelayConfig struct {
+	// If true, enables delay notifications.
+	// Default: true
+	Enabled bool `json:"enabled" yaml:"enabled" toml:"Enabled"`
+	// A notification is sent once a flight's departure or arrival
+	// delay is at least Threshold.
+	// Default: 15 minutes
+	Threshold time.Duration `json:"threshold" yaml:"threshold" toml:"Threshold"`
+	// If greater than 0, a further notification is sent every time
+	// a delay grows by at least Increment since the last delay notification.
+	// Default: 15 minutes
+	Increment time.Duration `json:"increment" yaml:"increment" toml:"Increment"`
+}
+
+// ShouldNotify returns true if a delay notification should be sent for
+// the passed delay, given the delay included in the last delay notification
+// (or 0 if none has been sent).
+func (c DelayConfig) ShouldNotify(delay, lastNotified time.Duration) bool {
+	switch {
+	case !c.Enabled, delay <= 0, delay < c.Threshold:
+		return false
+	case lastNotified <= 0:
+		return true
+	case c.Increment <= 0:
+		return false
+	default:
+		return delay-lastNotified >= c.Increment
+	}
 }
 
 // PreEventConfig contains configuration details
-// for pre-arrival notifications.
+// for pre-departure and pre-arrival notifications.
+// Pre-departure notifications are timed against a flight's gate departure time,
+// and pre-arrival notifications against its runway arrival (landing) time.
 type PreEventConfig struct {
-	// If true, enables notifications pre-arrival.
-	// Amount of time pre-arrival to send notifications can be set with
-	// Offset.
+	// If true, enables notifications before the event.
+	// Amount of time before the event to send notifications can be set with
+	// Offset and Offsets.
 	// Default: true
 	Enabled bool `json:"enabled" yaml:"enabled" toml:"Enabled"`
-	// If true, a notification will be sent at Offset time
-	// before the estimated arrival time of a flight.
+	// If true, a notification will be sent at each offset
+	// before the estimated time of the event.
 	// Default: true
 	Estimated bool `json:"estimated" yaml:"estimated" toml:"Estimated"`
-	// If true, a notification will be sent at Offset time
-	// before the scheduled arrival time of a flight.
+	// If true, a notification will be sent at each offset
+	// before the scheduled time of the event.
+	// If neither Estimated nor Scheduled is set, pre-departure notifications are sent
+	// before the scheduled departure time, and pre-arrival notifications before the estimated arrival time.
 	// Default: false
 	Scheduled bool `json:"scheduled" yaml:"scheduled" toml:"Scheduled"`
 	// If Enabled is true, Offset is the amount of time
-	// before both/either of estimated and scheduled arrival times
+	// before both/either of estimated and scheduled event times
 	// at which to send notifications.
+	// Ignored if Offsets is set.
 	// Default: 1 hour
 	Offset time.Duration `json:"offset" yaml:"offset" toml:"Offset"`
+	// Multiple offsets at which to send notifications (ex. 24h, 3h, and 30m before departure).
+	// If set, Offset is ignored.
+	Offsets []time.Duration `json:"offsets,omitempty" yaml:"offsets,omitempty" toml:"Offsets,omitempty"`
+}
+
+// AllOffsets returns the offsets at which notifications are sent:
+// Offsets if set, otherwise Offset.
+// Non-positive offsets are ignored.
+func (c PreEventConfig) AllOffsets() []time.Duration {
+	offsets := c.Offsets
+	if len(offsets) == 0 {
+		offsets = []time.Duration{c.Offset}
+	}
+
+	allOffsets := make([]time.Duration, 0, len(offsets))
+
+	for _, offset := range offsets {
+		if offset > 0 {
+			allOffsets = append(allOffsets, offset)
+		}
+	}
+
+	return allOffsets
+}
+
+// Active returns true if notifications are enabled
+// and at least one trigger and offset is set.
+func (c PreEventConfig) Active() bool {
+	return c.Enabled && (c.Estimated || c.Scheduled) && len(c.AllOffsets()) > 0
+}
+
+// WithDefaultTriggers returns a copy of the config in which enabled pre-event notifications
+// with neither trigger set are sent as they were before triggers could be configured:
+// before the scheduled departure time, and before the estimated arrival time.
+func (c NotificationsConfig) WithDefaultTriggers() NotificationsConfig {
+	if !c.PreDeparture.Estimated && !c.PreDeparture.Scheduled {
+		c.PreDeparture.Scheduled = true
+	}
+
+	if !c.PreArrival.Estimated && !c.PreArrival.Scheduled {
+		c.PreArrival.Estimated = true
+	}
+
+	return c
 }
 
 // DefaultNotificationsConfig returns a NotificationsConfig
@@ -95,6 +472,19 @@ func DefaultNotificationsConfig() NotificationsConfig {
 			Scheduled: true,
 			Offset:    DefaultPreArrivalOffset,
 		},
+		Delay: DelayConfig{
+			Enabled:   true,
+			Threshold: DefaultDelayThreshold,
+			Increment: DefaultDelayIncrement,
+		},
+		InboundAircraft: InboundAircraftConfig{
+			Enabled:       true,
+			MinTurnaround: DefaultMinTurnaround,
+			Increment:     DefaultInboundIncrement,
+		},
+		GateChange:   true,
+		Cancellation: true,
+		Diversion:    true,
 		UseLocalTime: true,
 	}
 }
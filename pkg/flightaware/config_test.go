@@ -1,11 +1,14 @@
 package flightaware_test
 
 import (
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
 )
@@ -117,6 +120,12 @@ func TestLoadConfig(t *testing.T) {
 				wantNotifsConf.PreArrival.Enabled, gotNotifsConf.PreArrival.Enabled,
 			)
 
+			assert.Equalf(
+				t, wantNotifsConf.PreArrival.Estimated, gotNotifsConf.PreArrival.Estimated,
+				"expected Notifications.PreArrival.Estimated to be %[1]t, got %[2]t",
+				wantNotifsConf.PreArrival.Estimated, gotNotifsConf.PreArrival.Estimated,
+			)
+
 			assert.Equalf(
 				t, wantNotifsConf.BaggageClaim, gotNotifsConf.BaggageClaim,
 				"expected Notifications.BaggageClaim to be %[1]t, got %[2]t",
@@ -132,7 +141,234 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_NotificationDefaults(t *testing.T) {
+	// Written before delay, gate change, cancellation, diversion,
+	// and inbound aircraft notifications were added, other than the delay threshold.
+	tests := []struct {
+		name string
+		ext  string
+		data string
+	}{
+		{
+			name: "json",
+			ext:  ".json",
+			data: `{"poll_interval": 120000000000, "notifications": {"takeoff": true, "delay": {"threshold": 1800000000000}}}`,
+		},
+		{
+			name: "yaml",
+			ext:  ".yaml",
+			data: "poll_interval: 2m\nnotifications:\n  takeoff: true\n  delay:\n    threshold: 30m\n",
+		},
+		{
+			name: "toml",
+			ext:  ".toml",
+			data: "PollInterval = \"2m\"\n[Notifications]\nTakeoff = true\n[Notifications.Delay]\nThreshold = \"30m\"\n",
+		},
+	}
+
+	defaults := flightaware.DefaultNotificationsConfig()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			confPath := filepath.Join(t.TempDir(), "flightaware"+tt.ext)
+			require.NoError(t, os.WriteFile(confPath, []byte(tt.data), 0o600))
+
+			got, err := flightaware.LoadConfig(confPath)
+			require.NoError(t, err)
+
+			assert.Equal(t, 2*time.Minute, got.PollInterval)
+			assert.True(t, got.Notifications.Takeoff)
+			assert.False(t, got.Notifications.Landing)
+			assert.True(t, got.Notifications.GateChange)
+			assert.True(t, got.Notifications.Cancellation)
+			assert.True(t, got.Notifications.Diversion)
+			assert.Equal(t, defaults.InboundAircraft, got.Notifications.InboundAircraft)
+			assert.Equal(t, flightaware.DelayConfig{
+				Enabled:   true,
+				Threshold: 30 * time.Minute,
+				Increment: flightaware.DefaultDelayIncrement,
+			}, got.Notifications.Delay)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		confPath := filepath.Join(t.TempDir(), "flightaware.yaml")
+		require.NoError(t, os.WriteFile(confPath, []byte("notifications:\n  gate_change: false\n  delay:\n    enabled: false\n"), 0o600))
+
+		got, err := flightaware.LoadConfig(confPath)
+		require.NoError(t, err)
+
+		assert.False(t, got.Notifications.GateChange)
+		assert.False(t, got.Notifications.Delay.Enabled)
+		assert.True(t, got.Notifications.Cancellation)
+	})
+}
+
+func TestNotificationsConfig_WithDefaultTriggers(t *testing.T) {
+	tests := []struct {
+		name             string
+		conf             flightaware.PreEventConfig
+		wantPreDeparture flightaware.PreEventConfig
+		wantPreArrival   flightaware.PreEventConfig
+	}{
+		{
+			name:             "no triggers",
+			conf:             flightaware.PreEventConfig{Enabled: true, Offset: time.Hour},
+			wantPreDeparture: flightaware.PreEventConfig{Enabled: true, Scheduled: true, Offset: time.Hour},
+			wantPreArrival:   flightaware.PreEventConfig{Enabled: true, Estimated: true, Offset: time.Hour},
+		},
+		{
+			name:             "scheduled",
+			conf:             flightaware.PreEventConfig{Enabled: true, Scheduled: true, Offset: time.Hour},
+			wantPreDeparture: flightaware.PreEventConfig{Enabled: true, Scheduled: true, Offset: time.Hour},
+			wantPreArrival:   flightaware.PreEventConfig{Enabled: true, Scheduled: true, Offset: time.Hour},
+		},
+		{
+			name:             "estimated",
+			conf:             flightaware.PreEventConfig{Enabled: true, Estimated: true, Offset: time.Hour},
+			wantPreDeparture: flightaware.PreEventConfig{Enabled: true, Estimated: true, Offset: time.Hour},
+			wantPreArrival:   flightaware.PreEventConfig{Enabled: true, Estimated: true, Offset: time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got := flightaware.NotificationsConfig{PreDeparture: tt.conf, PreArrival: tt.conf}.WithDefaultTriggers()
+			assert.Equal(t, tt.wantPreDeparture, got.PreDeparture)
+			assert.Equal(t, tt.wantPreArrival, got.PreArrival)
+			assert.True(t, got.PreDeparture.Active())
+			assert.True(t, got.PreArrival.Active())
+		})
+	}
+}
+
 func formatDurationSeconds(t *testing.T, d time.Duration) string {
 	t.Helper()
 	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
 }
+
+func TestFlightConfig_InformationParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		conf      flightaware.FlightConfig
+		wantId    string
+		wantDate  time.Time
+		wantFaId  bool
+		wantErr   bool
+		wantDated bool
+	}{
+		{
+			name:   "flight number",
+			conf:   flightaware.FlightConfig{Identifier: "UA2614"},
+			wantId: "UA2614",
+		},
+		{
+			name:      "flight number with date",
+			conf:      flightaware.FlightConfig{Identifier: "UA2614", IdentifierType: "flight_number", Date: "2022-05-31"},
+			wantId:    "UA2614",
+			wantDate:  flightaware.MakeFlightDateParam(2022, 5, 31),
+			wantDated: true,
+		},
+		{
+			name:     "flightaware id",
+			conf:     flightaware.FlightConfig{Identifier: "UAL2614-1653800000-airline-0001", IdentifierType: "flightaware_id", Date: "2022-05-31"},
+			wantId:   "UAL2614-1653800000-airline-0001",
+			wantFaId: true,
+		},
+		{
+			name:   "registration",
+			conf:   flightaware.FlightConfig{Identifier: "n12345", IdentifierType: "registration", Date: "2022-05-31"},
+			wantId: "N12345",
+		},
+		{
+			name:    "bad identifier type",
+			conf:    flightaware.FlightConfig{Identifier: "UA2614", IdentifierType: "tail_number"},
+			wantErr: true,
+		},
+		{
+			name:    "bad date",
+			conf:    flightaware.FlightConfig{Identifier: "UA2614", Date: "05/31/2022"},
+			wantErr: true,
+		},
+		{
+			name:    "no identifier",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := tt.conf.InformationParams()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantId, params.FlightIdentifier())
+			assert.Equal(t, tt.wantFaId, params.FetchByApiId())
+			assert.Equal(t, tt.wantDated, params.FetchByDate())
+
+			if date, ok := params.FlightDateParam(); ok {
+				assert.Equal(t, tt.wantDate, date)
+			}
+		})
+	}
+}
+
+func TestItineraryConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    flightaware.ItineraryConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			conf: flightaware.ItineraryConfig{
+				Name: "home",
+				Legs: []flightaware.FlightConfig{
+					{Identifier: "UA2614", Date: "2022-05-31"},
+					{Identifier: "UAL1234-1653800000-airline-0001", IdentifierType: "flightaware_id"},
+				},
+			},
+		},
+		{
+			name: "single leg",
+			conf: flightaware.ItineraryConfig{
+				Name: "home",
+				Legs: []flightaware.FlightConfig{{Identifier: "UA2614", Date: "2022-05-31"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "leg without date",
+			conf: flightaware.ItineraryConfig{
+				Name: "home",
+				Legs: []flightaware.FlightConfig{
+					{Identifier: "UA2614", Date: "2022-05-31"},
+					{Identifier: "UA1234"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.conf.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
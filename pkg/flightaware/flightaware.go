@@ -10,8 +10,11 @@ import (
 var logger = logging.NewLogger()
 
 const (
-	apiKeyHeader    string = "x-apikey"
-	baseApiEndpoint string = "aeroapi.flightaware.com/aeroapi"
+	apiServiceName string = "flightaware"
+	apiKeyHeader   string = "x-apikey"
+	// DefaultBaseUrl is the base URL of AeroAPI requests,
+	// unless overridden by config or FLIGHTAWARE_BASE_URL.
+	DefaultBaseUrl string = "https://aeroapi.flightaware.com/aeroapi"
 )
 
 const (
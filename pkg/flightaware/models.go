@@ -19,11 +19,12 @@ type ApiResponse struct {
 	NumPages int `json:"num_pages"`
 }
 
+// ApiResponseError is the body of an unsuccessful AeroAPI response.
 type ApiResponseError struct {
-	Title  string
-	Reason string
-	Detail string
-	Status string
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
 }
 
 type FlightDataResponse struct {
@@ -41,6 +42,7 @@ type FlightData struct {
 	Operator            FlightOperator
 	Identifiers         FlightIdentifiers
 	InboundFlightId     string
+	BaggageClaim        string
 	FlightNumber        string
 	Route               string
 	FlightId            string
@@ -103,6 +105,9 @@ func (d *FlightData) UnmarshalJSON(data []byte) error {
 	if raw.AtcIdent != nil {
 		d.AtcIdent = *raw.AtcIdent
 	}
+	if raw.BaggageClaim != nil {
+		d.BaggageClaim = *raw.BaggageClaim
+	}
 	if raw.RouteDistance != nil {
 		d.RouteDistance = *raw.RouteDistance
 	}
@@ -239,6 +244,7 @@ type rawFlightData struct {
 	EstimatedOff        *string                        `json:"estimated_off,omitempty"`
 	ScheduledOff        *string                        `json:"scheduled_off,omitempty"`
 	AtcIdent            *string                        `json:"atc_ident,omitempty"`
+	BaggageClaim        *string                        `json:"baggage_claim,omitempty"`
 	ActualOut           *string                        `json:"actual_out,omitempty"`
 	ScheduledOn         *string                        `json:"scheduled_on,omitempty"`
 	FiledAltitude       *int64                         `json:"filed_altitude,omitempty"`
@@ -0,0 +1,47 @@
+package flightaware_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+func TestFlightData_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name             string
+		raw              string
+		wantBaggageClaim string
+		wantTerminal     string
+	}{
+		{
+			name:             "baggage claim posted",
+			raw:              `{"ident_iata": "UA2614", "baggage_claim": "4", "terminal_destination": "C"}`,
+			wantBaggageClaim: "4",
+			wantTerminal:     "C",
+		},
+		{
+			name: "baggage claim null",
+			raw:  `{"ident_iata": "UA2614", "baggage_claim": null}`,
+		},
+		{
+			name: "baggage claim missing",
+			raw:  `{"ident_iata": "UA2614"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var flightData flightaware.FlightData
+
+			assert.NoError(t, json.Unmarshal([]byte(tt.raw), &flightData))
+			assert.Equal(t, "UA2614", flightData.Identifiers.IATA)
+			assert.Equal(t, tt.wantBaggageClaim, flightData.BaggageClaim)
+			assert.Equal(t, tt.wantTerminal, flightData.Destination.Terminal)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package flightaware
+
+import (
+	"context"
+)
+
+// FlightPages iterates over the result pages of a flight information request,
+// following the `links.next` cursor of each page until no pages are left,
+// or until the Client's max pages have been fetched.
+// Requests by fa_flight_id match a single flight, so only their first page is fetched.
+//
+//	pages := client.FlightInformationPages(params)
+//	for pages.Next(ctx) {
+//		flights := pages.Page().Flights
+//	}
+//	if err := pages.Err(); err != nil {
+//		...
+//	}
+type FlightPages struct {
+	client  *Client
+	page    *FlightDataResponse
+	err     error
+	params  FlightInformationParams
+	next    string
+	fetched int
+	done    bool
+}
+
+// Next fetches the next page, returning false once no pages are left
+// or an error has occurred.
+func (p *FlightPages) Next(ctx context.Context) bool {
+	if p.done || p.err != nil {
+		return false
+	}
+
+	if p.fetched >= p.maxPages() {
+		p.done = true
+		return false
+	}
+
+	endpoint := p.next
+
+	if p.fetched == 0 {
+		endpoint, _, p.err = p.params.UrlParams()
+		if p.err != nil {
+			return false
+		}
+	}
+
+	page, err := p.client.flightInformationPage(ctx, endpoint)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.page = page
+	p.fetched++
+	p.next = page.Links.Next
+
+	if p.next == "" {
+		p.done = true
+	}
+
+	return true
+}
+
+// maxPages returns the maximum number of pages to fetch: the Client's max pages
+// for searches by designator or registration, which can match many flights,
+// and one page for requests by fa_flight_id.
+func (p *FlightPages) maxPages() int {
+	if p.params.FetchByApiId() {
+		return 1
+	}
+
+	return p.client.maxPages
+}
+
+// Page returns the page fetched by the last call to Next.
+func (p *FlightPages) Page() *FlightDataResponse {
+	return p.page
+}
+
+// Fetched returns the number of pages fetched so far.
+func (p *FlightPages) Fetched() int {
+	return p.fetched
+}
+
+// Err returns the error which stopped iteration, if any.
+func (p *FlightPages) Err() error {
+	return p.err
+}
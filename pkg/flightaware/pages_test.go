@@ -0,0 +1,134 @@
+package flightaware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+)
+
+// rewriteTransport sends every request to the test server.
+type rewriteTransport struct {
+	base      http.RoundTripper
+	serverUrl *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.serverUrl.Scheme
+	req.URL.Host = t.serverUrl.Host
+
+	return t.base.RoundTrip(req)
+}
+
+func newPagedTestClient(t *testing.T, numPages int) (*Client, *int) {
+	t.Helper()
+
+	requests := new(int)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		page := 1
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			_, _ = fmt.Sscanf(cursor, "page%d", &page)
+		}
+
+		next := "null"
+		if page < numPages {
+			next = fmt.Sprintf(`"/flights/UAL2614?ident_type=designator&cursor=page%[1]d"`, page+1)
+		}
+
+		_, _ = fmt.Fprintf(
+			w,
+			`{"links": {"next": %[1]s}, "num_pages": 1, "flights": [{"fa_flight_id": "UAL2614-%[2]d", "ident_iata": "UA2614"}]}`,
+			next, page,
+		)
+	}))
+	t.Cleanup(server.Close)
+
+	serverUrl, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(&AuthConfig{ApiKey: "test-key"})
+	client.httpClient = server.Client()
+	client.httpClient.Transport = rewriteTransport{base: client.httpClient.Transport, serverUrl: serverUrl}
+
+	return client, requests
+}
+
+func TestClient_FlightInformation_Pagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		numPages  int
+		maxPages  int
+		wantPages int
+		wantNext  bool
+		byApiId   bool
+	}{
+		{
+			name:      "single page",
+			numPages:  1,
+			maxPages:  5,
+			wantPages: 1,
+		},
+		{
+			name:      "all pages",
+			numPages:  3,
+			maxPages:  5,
+			wantPages: 3,
+		},
+		{
+			name:      "max pages",
+			numPages:  4,
+			maxPages:  2,
+			wantPages: 2,
+			wantNext:  true,
+		},
+		{
+			name:      "fa_flight_id",
+			numPages:  3,
+			maxPages:  5,
+			wantPages: 1,
+			wantNext:  true,
+			byApiId:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newPagedTestClient(t, tt.numPages)
+			client.SetMaxPages(tt.maxPages)
+
+			params := FlightInformationParams{FlightDesignator: utils.ToPointer("UAL2614")}
+			if tt.byApiId {
+				params = FlightInformationParams{FlightId: utils.ToPointer("UAL2614-1")}
+			}
+
+			resp, err := client.FlightInformation(context.Background(), params)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantPages, *requests)
+			assert.Equal(t, tt.wantPages, resp.NumPages)
+			assert.Len(t, resp.Flights, tt.wantPages)
+			assert.Equal(t, tt.wantNext, resp.Links.Next != "")
+
+			lastId := fmt.Sprintf("UAL2614-%[1]d", tt.wantPages)
+
+			found, ok := FindFlightFromParams(resp.Flights, FlightInformationParams{FlightId: &lastId})
+			if assert.True(t, ok) {
+				assert.Equal(t, lastId, found.FlightId)
+			}
+		})
+	}
+}
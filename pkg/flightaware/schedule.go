@@ -0,0 +1,287 @@
+package flightaware
+
+import (
+	"time"
+)
+
+const (
+	// DefaultFarPollInterval is the default interval between polls
+	// of a flight whose departure is outside of the departure window.
+	DefaultFarPollInterval = 30 * time.Minute
+	// DefaultCruisePollInterval is the default interval between polls
+	// of an en route flight outside of the arrival window.
+	DefaultCruisePollInterval = 15 * time.Minute
+	DefaultDepartureWindow    = time.Hour
+	DefaultArrivalWindow      = 45 * time.Minute
+)
+
+// PollPhase is the phase of a flight which sets how often it's polled.
+type PollPhase string
+
+const (
+	// PollPhaseScheduled is a flight which hasn't departed,
+	// and whose departure is outside of the departure window.
+	PollPhaseScheduled PollPhase = "scheduled"
+	// PollPhaseDeparture is a flight within the departure window
+	// which hasn't taken off.
+	PollPhaseDeparture PollPhase = "departure"
+	// PollPhaseCruise is a flight which has taken off,
+	// and whose arrival is outside of the arrival window.
+	PollPhaseCruise PollPhase = "cruise"
+	// PollPhaseArrival is a flight within the arrival window, or which has landed.
+	PollPhaseArrival PollPhase = "arrival"
+)
+
+// PollScheduleConfig contains the configuration for adapting
+// how often a flight is polled to its phase.
+// While a flight is within the departure or arrival window, it's polled every PollInterval.
+type PollScheduleConfig struct {
+	// Flights are only polled every PollInterval around their departure and arrival,
+	// and are polled less often otherwise. If Disabled is true, flights are always
+	// polled every PollInterval.
+	// Default: false
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty" toml:"Disabled,omitempty"`
+	// Interval between polls of a flight whose departure is outside of the departure window.
+	// Default: 30 minutes
+	FarInterval time.Duration `json:"far_interval,omitempty" yaml:"far_interval,omitempty" toml:"FarInterval,omitempty"`
+	// Interval between polls of a flight which has taken off,
+	// while its arrival is outside of the arrival window.
+	// Default: 15 minutes
+	CruiseInterval time.Duration `json:"cruise_interval,omitempty" yaml:"cruise_interval,omitempty" toml:"CruiseInterval,omitempty"`
+	// Amount of time before a flight's scheduled or estimated gate departure (whichever is earlier)
+	// from which it's polled every PollInterval, until it takes off.
+	// Default: 1 hour
+	DepartureWindow time.Duration `json:"departure_window,omitempty" yaml:"departure_window,omitempty" toml:"DepartureWindow,omitempty"`
+	// Amount of time before a flight's estimated landing or gate arrival (whichever is earlier)
+	// from which it's polled every PollInterval.
+	// Default: 45 minutes
+	ArrivalWindow time.Duration `json:"arrival_window,omitempty" yaml:"arrival_window,omitempty" toml:"ArrivalWindow,omitempty"`
+}
+
+// DefaultPollScheduleConfig returns a PollScheduleConfig
+// set with default values.
+func DefaultPollScheduleConfig() PollScheduleConfig {
+	return PollScheduleConfig{
+		FarInterval:     DefaultFarPollInterval,
+		CruiseInterval:  DefaultCruisePollInterval,
+		DepartureWindow: DefaultDepartureWindow,
+		ArrivalWindow:   DefaultArrivalWindow,
+	}
+}
+
+// Phase returns the phase of the passed flight at `now`.
+func (c PollScheduleConfig) Phase(flight *FlightData, now time.Time) PollPhase {
+	switch {
+	case !flight.RunwayArrivalTime.Actual.IsZero(), !flight.GateArrivalTime.Actual.IsZero():
+		return PollPhaseArrival
+	case !flight.RunwayDepartureTime.Actual.IsZero():
+		arrival := earliestTime(expectedTime(flight.RunwayArrivalTime), expectedTime(flight.GateArrivalTime))
+		if arrival.IsZero() || !now.Before(arrival.Add(-c.arrivalWindow())) {
+			return PollPhaseArrival
+		}
+
+		return PollPhaseCruise
+	case !flight.GateDepartureTime.Actual.IsZero():
+		return PollPhaseDeparture
+	default:
+		windowStart, ok := c.DepartureWindowStart(flight)
+		if !ok || !now.Before(windowStart) {
+			return PollPhaseDeparture
+		}
+
+		return PollPhaseScheduled
+	}
+}
+
+// NextInterval returns the interval until the next poll of the passed flight at `now`,
+// where pollInterval is the interval between polls within the departure and arrival windows.
+// The interval is shortened so that the flight is polled once a window opens.
+// If the schedule is disabled, pollInterval is always returned.
+func (c PollScheduleConfig) NextInterval(flight *FlightData, now time.Time, pollInterval time.Duration) time.Duration {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	if c.Disabled {
+		return pollInterval
+	}
+
+	var (
+		interval    time.Duration
+		windowStart time.Time
+	)
+
+	switch c.Phase(flight, now) {
+	case PollPhaseScheduled:
+		interval = c.farInterval()
+		windowStart, _ = c.DepartureWindowStart(flight)
+	case PollPhaseCruise:
+		interval = c.cruiseInterval()
+		windowStart = earliestTime(expectedTime(flight.RunwayArrivalTime), expectedTime(flight.GateArrivalTime)).
+			Add(-c.arrivalWindow())
+	default:
+		return pollInterval
+	}
+
+	if untilWindow := windowStart.Sub(now); untilWindow < interval {
+		interval = untilWindow
+	}
+
+	if interval < pollInterval {
+		interval = pollInterval
+	}
+
+	return interval
+}
+
+// ProjectedQueries returns the number of times the passed flight will be polled
+// from `now` until its estimated gate arrival, assuming that the flight keeps to its
+// estimated times. Each poll of a flight is a single AeroAPI query.
+func (c PollScheduleConfig) ProjectedQueries(flight *FlightData, now time.Time, pollInterval time.Duration) int {
+	end := expectedTime(flight.GateArrivalTime)
+	if end.IsZero() {
+		end = expectedTime(flight.RunwayArrivalTime)
+	}
+
+	return c.projectedQueries(flight, now, end, pollInterval)
+}
+
+// ProjectedDepartureQueries returns the number of times the passed flight will be polled
+// from `now` until its estimated gate departure, assuming that the flight keeps to its
+// estimated times (ex. to count queries which are only sent until the flight departs).
+func (c PollScheduleConfig) ProjectedDepartureQueries(flight *FlightData, now time.Time, pollInterval time.Duration) int {
+	if !flight.GateDepartureTime.Actual.IsZero() {
+		return 0
+	}
+
+	return c.projectedQueries(flight, now, expectedTime(flight.GateDepartureTime), pollInterval)
+}
+
+// projectedQueries returns the number of times the passed flight will be polled from `now` until `end`.
+func (c PollScheduleConfig) projectedQueries(flight *FlightData, now, end time.Time, pollInterval time.Duration) int {
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	var queries int
+
+	for t := now; t.Before(end); {
+		projected := projectFlight(*flight, t)
+
+		interval := c.NextInterval(&projected, t, pollInterval)
+		if interval > pollInterval {
+			queries++
+			t = t.Add(interval)
+
+			continue
+		}
+
+		// Once a flight is polled every pollInterval, it keeps being polled
+		// every pollInterval until at least its next event,
+		// so every poll until then is counted at once.
+		until := nextExpectedEvent(projected, t, end).Sub(t)
+
+		polls := int((until + pollInterval - 1) / pollInterval)
+		if polls < 1 {
+			polls = 1
+		}
+
+		queries += polls
+		t = t.Add(time.Duration(polls) * pollInterval)
+	}
+
+	return queries
+}
+
+func (c PollScheduleConfig) farInterval() time.Duration {
+	return durationOrDefault(c.FarInterval, DefaultFarPollInterval)
+}
+
+func (c PollScheduleConfig) cruiseInterval() time.Duration {
+	return durationOrDefault(c.CruiseInterval, DefaultCruisePollInterval)
+}
+
+// DepartureWindowStart returns the time at which the passed flight enters the departure window:
+// DepartureWindow before its scheduled or estimated gate departure, whichever is earlier.
+// False is returned if the flight has neither.
+func (c PollScheduleConfig) DepartureWindowStart(flight *FlightData) (time.Time, bool) {
+	departure := earliestTime(flight.GateDepartureTime.Scheduled, flight.GateDepartureTime.Estimated)
+	if departure.IsZero() {
+		return time.Time{}, false
+	}
+
+	return departure.Add(-c.departureWindow()), true
+}
+
+func (c PollScheduleConfig) departureWindow() time.Duration {
+	return durationOrDefault(c.DepartureWindow, DefaultDepartureWindow)
+}
+
+func (c PollScheduleConfig) arrivalWindow() time.Duration {
+	return durationOrDefault(c.ArrivalWindow, DefaultArrivalWindow)
+}
+
+// projectFlight returns the passed flight data as it's expected to be at `at`,
+// with the actual time of every event expected to have happened by then set.
+func projectFlight(flight FlightData, at time.Time) FlightData {
+	for _, ts := range []*FlightTimestamp{
+		&flight.GateDepartureTime,
+		&flight.RunwayDepartureTime,
+		&flight.RunwayArrivalTime,
+		&flight.GateArrivalTime,
+	} {
+		if expected := expectedTime(*ts); ts.Actual.IsZero() && !expected.IsZero() && !expected.After(at) {
+			ts.Actual = expected
+		}
+	}
+
+	return flight
+}
+
+// nextExpectedEvent returns the expected time of the passed flight's next event after `now`
+// which hasn't happened, or `end` if it's earlier.
+func nextExpectedEvent(flight FlightData, now, end time.Time) time.Time {
+	next := end
+
+	for _, ts := range []FlightTimestamp{
+		flight.GateDepartureTime,
+		flight.RunwayDepartureTime,
+		flight.RunwayArrivalTime,
+		flight.GateArrivalTime,
+	} {
+		if expected := expectedTime(ts); ts.Actual.IsZero() && expected.After(now) && expected.Before(next) {
+			next = expected
+		}
+	}
+
+	return next
+}
+
+// expectedTime returns the estimated time of an event if set,
+// otherwise its scheduled time.
+func expectedTime(ts FlightTimestamp) time.Time {
+	if !ts.Estimated.IsZero() {
+		return ts.Estimated
+	}
+
+	return ts.Scheduled
+}
+
+// earliestTime returns the earliest of the passed times which is set.
+func earliestTime(times ...time.Time) (earliest time.Time) {
+	for _, t := range times {
+		if !t.IsZero() && (earliest.IsZero() || t.Before(earliest)) {
+			earliest = t
+		}
+	}
+
+	return
+}
+
+func durationOrDefault(d, defaultDuration time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultDuration
+	}
+
+	return d
+}
@@ -0,0 +1,199 @@
+package flightaware_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/pkg/flightaware"
+)
+
+var scheduleDeparture = time.Date(2022, 6, 1, 14, 0, 0, 0, time.UTC)
+
+// scheduleFlight returns a flight scheduled to depart its gate at 14:00 (estimated 14:10),
+// take off at 14:15, land at 19:50, and arrive at its gate at 20:00,
+// with the passed events having happened.
+func scheduleFlight(gateOut, takeoff, landing bool) *flightaware.FlightData {
+	flight := &flightaware.FlightData{
+		GateDepartureTime: flightaware.FlightTimestamp{
+			Scheduled: scheduleDeparture,
+			Estimated: scheduleDeparture.Add(10 * time.Minute),
+		},
+		RunwayDepartureTime: flightaware.FlightTimestamp{
+			Scheduled: scheduleDeparture.Add(15 * time.Minute),
+		},
+		RunwayArrivalTime: flightaware.FlightTimestamp{
+			Scheduled: scheduleDeparture.Add(5*time.Hour + 50*time.Minute),
+		},
+		GateArrivalTime: flightaware.FlightTimestamp{
+			Scheduled: scheduleDeparture.Add(6 * time.Hour),
+		},
+	}
+
+	if gateOut {
+		flight.GateDepartureTime.Actual = scheduleDeparture
+	}
+
+	if takeoff {
+		flight.RunwayDepartureTime.Actual = scheduleDeparture.Add(15 * time.Minute)
+	}
+
+	if landing {
+		flight.RunwayArrivalTime.Actual = scheduleDeparture.Add(5*time.Hour + 50*time.Minute)
+	}
+
+	return flight
+}
+
+func TestPollScheduleConfig_NextInterval(t *testing.T) {
+	const pollInterval = time.Minute
+
+	schedule := flightaware.DefaultPollScheduleConfig()
+
+	tests := []struct {
+		now          time.Time
+		flight       *flightaware.FlightData
+		name         string
+		schedule     flightaware.PollScheduleConfig
+		wantPhase    flightaware.PollPhase
+		wantInterval time.Duration
+	}{
+		{
+			name:         "days out",
+			schedule:     schedule,
+			flight:       scheduleFlight(false, false, false),
+			now:          scheduleDeparture.Add(-48 * time.Hour),
+			wantPhase:    flightaware.PollPhaseScheduled,
+			wantInterval: flightaware.DefaultFarPollInterval,
+		},
+		{
+			name:         "departure window opening",
+			schedule:     schedule,
+			flight:       scheduleFlight(false, false, false),
+			now:          scheduleDeparture.Add(-70 * time.Minute),
+			wantPhase:    flightaware.PollPhaseScheduled,
+			wantInterval: 10 * time.Minute,
+		},
+		{
+			name:         "departure window",
+			schedule:     schedule,
+			flight:       scheduleFlight(false, false, false),
+			now:          scheduleDeparture.Add(-time.Hour),
+			wantPhase:    flightaware.PollPhaseDeparture,
+			wantInterval: pollInterval,
+		},
+		{
+			name:         "delayed departure",
+			schedule:     schedule,
+			flight:       scheduleFlight(false, false, false),
+			now:          scheduleDeparture.Add(2 * time.Hour),
+			wantPhase:    flightaware.PollPhaseDeparture,
+			wantInterval: pollInterval,
+		},
+		{
+			name:         "taxiing",
+			schedule:     schedule,
+			flight:       scheduleFlight(true, false, false),
+			now:          scheduleDeparture.Add(10 * time.Minute),
+			wantPhase:    flightaware.PollPhaseDeparture,
+			wantInterval: pollInterval,
+		},
+		{
+			name:         "cruise",
+			schedule:     schedule,
+			flight:       scheduleFlight(true, true, false),
+			now:          scheduleDeparture.Add(time.Hour),
+			wantPhase:    flightaware.PollPhaseCruise,
+			wantInterval: flightaware.DefaultCruisePollInterval,
+		},
+		{
+			name:         "arrival window opening",
+			schedule:     schedule,
+			flight:       scheduleFlight(true, true, false),
+			now:          scheduleDeparture.Add(5 * time.Hour),
+			wantPhase:    flightaware.PollPhaseCruise,
+			wantInterval: 5 * time.Minute,
+		},
+		{
+			name:         "arrival window",
+			schedule:     schedule,
+			flight:       scheduleFlight(true, true, false),
+			now:          scheduleDeparture.Add(5*time.Hour + 30*time.Minute),
+			wantPhase:    flightaware.PollPhaseArrival,
+			wantInterval: pollInterval,
+		},
+		{
+			name:         "landed",
+			schedule:     schedule,
+			flight:       scheduleFlight(true, true, true),
+			now:          scheduleDeparture.Add(5*time.Hour + 55*time.Minute),
+			wantPhase:    flightaware.PollPhaseArrival,
+			wantInterval: pollInterval,
+		},
+		{
+			name:         "custom windows",
+			schedule:     flightaware.PollScheduleConfig{FarInterval: 4 * time.Hour, DepartureWindow: 3 * time.Hour},
+			flight:       scheduleFlight(false, false, false),
+			now:          scheduleDeparture.Add(-24 * time.Hour),
+			wantPhase:    flightaware.PollPhaseScheduled,
+			wantInterval: 4 * time.Hour,
+		},
+		{
+			name:         "disabled",
+			schedule:     flightaware.PollScheduleConfig{Disabled: true},
+			flight:       scheduleFlight(false, false, false),
+			now:          scheduleDeparture.Add(-48 * time.Hour),
+			wantPhase:    flightaware.PollPhaseScheduled,
+			wantInterval: pollInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantPhase, tt.schedule.Phase(tt.flight, tt.now))
+			assert.Equal(t, tt.wantInterval, tt.schedule.NextInterval(tt.flight, tt.now, pollInterval))
+		})
+	}
+}
+
+func TestPollScheduleConfig_ProjectedQueries(t *testing.T) {
+	const pollInterval = time.Minute
+
+	var (
+		flight = scheduleFlight(false, false, false)
+		now    = scheduleDeparture.Add(-3 * time.Hour)
+	)
+
+	// Gate departure is scheduled at 14:00 and estimated at 14:10, and the flight
+	// is expected to take off at 14:15, land at 19:50, and arrive at its gate at 20:00.
+	// 11:00-13:00 every 30 minutes:       4 queries
+	// 13:00-14:15 every minute:          75 queries
+	// 14:15-19:05 every 15 minutes:      20 queries (the last after 5 minutes)
+	// 19:05-20:00 every minute:          55 queries
+	assert.Equal(t, 154, flightaware.DefaultPollScheduleConfig().ProjectedQueries(flight, now, pollInterval))
+
+	// Unset fields (ex. of a config without a poll schedule) use the defaults.
+	assert.Equal(t, 154, flightaware.PollScheduleConfig{}.ProjectedQueries(flight, now, pollInterval))
+
+	assert.Equal(t, 540, flightaware.PollScheduleConfig{Disabled: true}.ProjectedQueries(flight, now, pollInterval))
+
+	assert.Equal(t, 0, flightaware.DefaultPollScheduleConfig().ProjectedQueries(flight, scheduleDeparture.Add(7*time.Hour), pollInterval))
+}
+
+func TestPollScheduleConfig_ProjectedDepartureQueries(t *testing.T) {
+	const pollInterval = time.Minute
+
+	var (
+		flight = scheduleFlight(false, false, false)
+		now    = scheduleDeparture.Add(-3 * time.Hour)
+	)
+
+	// 11:00-13:00 every 30 minutes:       4 queries
+	// 13:00-14:10 every minute:          70 queries
+	assert.Equal(t, 74, flightaware.DefaultPollScheduleConfig().ProjectedDepartureQueries(flight, now, pollInterval))
+
+	departed := scheduleFlight(true, false, false)
+	assert.Equal(t, 0, flightaware.DefaultPollScheduleConfig().ProjectedDepartureQueries(departed, now, pollInterval))
+}
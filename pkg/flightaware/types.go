@@ -3,6 +3,7 @@ package flightaware
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,9 +15,16 @@ const (
 	FlightDateFormatLayout string = "2006-01-02"
 )
 
+// Human-readable identifier types, as accepted by ParseIdentifierType.
+const (
+	FlightNumberIdentifierType  string = "flight_number"
+	FlightAwareIdIdentifierType string = "flightaware_id"
+	RegistrationIdentifierType  string = "registration"
+)
+
 var (
 	ErrNoFlightsFound = errors.New("no flights found based on passed parameters")
-	ErrNoFlightId     = errors.New("one of FlightId or FlightDesignator must be passed in FlightInformationParams")
+	ErrNoFlightId     = errors.New("one of FlightId, FlightDesignator, or Registration must be passed in FlightInformationParams")
 )
 
 type FlightInformationParams struct {
@@ -29,6 +37,10 @@ type FlightInformationParams struct {
 	// If passed, a particular date should also be passed to ensure that
 	// an accurate/desired result is returned.
 	FlightDesignator *string
+	// Aircraft registration (tail number), for example: N12345.
+	// Flights for a registration include every flight the aircraft
+	// has recently flown or is scheduled to fly.
+	Registration *string
 	// If querying flight info using the FlightDesignator param,
 	// FlightDate should be passed as a time.Time in UTC.
 	// Building a new time.Time using MakeFlightDateParam
@@ -45,6 +57,10 @@ func (p FlightInformationParams) FlightIdentifier() string {
 		return val
 	}
 
+	if val, ok := utils.FromPointer(p.Registration); ok {
+		return val
+	}
+
 	return ""
 }
 
@@ -58,6 +74,7 @@ func (p FlightInformationParams) UrlParams() (endpoint string, params url.Values
 
 	apiIdParam, hasApiId := utils.FromPointer(p.FlightId)
 	designatorParam, hasDesignator := utils.FromPointer(p.FlightDesignator)
+	registrationParam, hasRegistration := utils.FromPointer(p.Registration)
 
 	switch {
 	case hasApiId:
@@ -66,6 +83,9 @@ func (p FlightInformationParams) UrlParams() (endpoint string, params url.Values
 	case hasDesignator:
 		flightId = designatorParam
 		idType = DesignatorIdent
+	case hasRegistration:
+		flightId = registrationParam
+		idType = RegistrationIdent
 	default:
 		err = ErrNoFlightId
 		return
@@ -110,6 +130,25 @@ func (p FlightInformationParams) FetchClosest() bool {
 	return true
 }
 
+// ParseIdentifierType parses a human-readable identifier type
+// ("flight_number", "flightaware_id", or "registration") into an IdentifierType.
+// An empty string is parsed as "flight_number".
+func ParseIdentifierType(s string) (IdentifierType, error) {
+	switch strings.ToLower(s) {
+	case "", FlightNumberIdentifierType:
+		return DesignatorIdent, nil
+	case FlightAwareIdIdentifierType:
+		return FaFlightIdIdent, nil
+	case RegistrationIdentifierType:
+		return RegistrationIdent, nil
+	default:
+		return DesignatorIdent, errors.Errorf(
+			"unknown identifier type %[1]s. Allowed values: '%[2]s', '%[3]s', '%[4]s'",
+			s, FlightNumberIdentifierType, FlightAwareIdIdentifierType, RegistrationIdentifierType,
+		)
+	}
+}
+
 // MakeFlightDateParam constructs a time.Time (in UTC)
 // using the passed year, month, and day.
 // `year` should be a "full" year, ex. 2006, 1997.
@@ -41,9 +41,14 @@ func FindFlightByDate(flights []FlightData, flightDate time.Time) (found *Flight
 }
 
 func FindClosestFlight(flights []FlightData) (found *FlightData, ok bool) {
+	return FindClosestFlightAt(flights, time.Now())
+}
+
+// FindClosestFlightAt returns the first flight scheduled to depart on or after the day of `now`.
+func FindClosestFlightAt(flights []FlightData, now time.Time) (found *FlightData, ok bool) {
 	flights = SortFlights(flights)
 
-	now := utils.ToLocalTime(time.Now())
+	now = utils.ToLocalTime(now)
 
 	for i := range flights {
 		flightTime := utils.ToLocalTime(scheduledGateDeparture(flights[i]))
@@ -71,6 +76,12 @@ func FindFlightByApiId(flights []FlightData, apiId string) (found *FlightData, o
 }
 
 func FindFlightFromParams(flights []FlightData, params FlightInformationParams) (found *FlightData, ok bool) {
+	return FindFlightFromParamsAt(flights, params, time.Now())
+}
+
+// FindFlightFromParamsAt returns the flight matching the passed params,
+// using `now` to find the closest flight if the params have neither a flight ID nor a date.
+func FindFlightFromParamsAt(flights []FlightData, params FlightInformationParams, now time.Time) (found *FlightData, ok bool) {
 	switch {
 	case params.FetchByApiId():
 		flightId, _ := utils.FromPointer(params.FlightId)
@@ -79,7 +90,7 @@ func FindFlightFromParams(flights []FlightData, params FlightInformationParams)
 		desiredDate, _ := params.FlightDateParam()
 		found, ok = FindFlightByDate(flights, desiredDate)
 	default:
-		found, ok = FindClosestFlight(flights)
+		found, ok = FindClosestFlightAt(flights, now)
 	}
 
 	return
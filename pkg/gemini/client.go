@@ -12,6 +12,7 @@ import (
 	"github.com/jalavosus/stuffnotifier/internal/env"
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/errs"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 )
 
 // Client is a superficial wrapper around
@@ -21,30 +22,86 @@ import (
 type Client struct {
 	authData   authdata.AuthData
 	httpClient *http.Client
-	baseApiUri string
+	transport  *transport.Transport
+	// http.RoundTripper which the transport sends requests through,
+	// or nil for http.DefaultTransport.
+	baseTransport http.RoundTripper
+	baseApiUri    string
 }
 
 // NewClient returns a Client instance.
 func NewClient(authData authdata.AuthData) *Client {
-	var baseApiUri = apiUri
-	if env.GeminiUseSandbox() {
-		baseApiUri = sandboxUri
-	}
+	httpClient, t := transport.NewClient(nil, defaultHttpTimeout, DefaultTransportConfig())
 
 	return &Client{
 		authData:   authData,
-		httpClient: utils.HttpClientWithTimeout(defaultHttpTimeout),
-		baseApiUri: baseApiUri,
+		httpClient: httpClient,
+		transport:  t,
+		baseApiUri: defaultBaseUrl(),
+	}
+}
+
+// defaultBaseUrl returns the base URL set in the environment,
+// or SandboxBaseUrl if the sandbox is enabled, or DefaultBaseUrl.
+func defaultBaseUrl() string {
+	baseUrl := DefaultBaseUrl
+	if env.GeminiUseSandbox() {
+		baseUrl = SandboxBaseUrl
+	}
+
+	return env.BaseUrl(env.GeminiBaseUrl, baseUrl)
+}
+
+// SetBaseUrl sets the base URL (scheme, host, and path prefix) of the Client's requests,
+// ex. http://localhost:8080.
+// An empty base URL resets it to the one set in the environment, or the default.
+// Returns an error if the base URL is invalid.
+func (c *Client) SetBaseUrl(baseUrl string) (*Client, error) {
+	if baseUrl == "" {
+		baseUrl = defaultBaseUrl()
+	}
+
+	u, err := utils.ParseBaseUrl(baseUrl)
+	if err != nil {
+		return nil, err
 	}
+
+	c.baseApiUri = u.String()
+	return c, nil
 }
 
-// SetHttpTimeout sets the timeout of the Client's http.Client instance.
+// SetHttpTimeout sets the timeout of each attempt of the Client's requests.
+// Retried requests get the full timeout for every attempt.
 // By default, this is set to 10 seconds.
 func (c *Client) SetHttpTimeout(timeout time.Duration) *Client {
-	c.httpClient.Timeout = timeout
+	c.transport.SetAttemptTimeout(timeout)
+	return c
+}
+
+// SetTransportConfig replaces the Client's transport with one using the passed config.
+// Unset fields are set to their DefaultTransportConfig values.
+func (c *Client) SetTransportConfig(conf transport.Config) *Client {
+	c.httpClient, c.transport = transport.NewClient(c.baseTransport, c.transport.AttemptTimeout(), conf.WithDefaults(DefaultTransportConfig()))
 	return c
 }
 
+// SetBaseTransport sets the http.RoundTripper which the Client's transport sends requests through,
+// ex. a transport.Recorder or transport.Replayer, keeping the transport's config.
+// A nil base resets it to http.DefaultTransport.
+func (c *Client) SetBaseTransport(base http.RoundTripper) *Client {
+	c.baseTransport = base
+	return c.SetTransportConfig(c.transport.Config())
+}
+
+// Usage returns the number and cost of every Gemini API request sent by the Client.
+func (c Client) Usage() transport.UsageStats {
+	if c.transport == nil {
+		return transport.UsageStats{}
+	}
+
+	return c.transport.Usage()
+}
+
 func (c Client) Symbols(ctx context.Context) (*SymbolsResponse, error) {
 	var response *SymbolsResponse
 
@@ -11,16 +11,41 @@ import (
 
 	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/errs"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
 )
 
 const (
 	DefaultPollInterval = 30 * time.Second
 )
 
+// DefaultTransportConfig returns the default retry and rate limiting
+// configuration of Gemini API requests.
+func DefaultTransportConfig() transport.Config {
+	conf := transport.DefaultConfig()
+	conf.RateLimit = 2
+	conf.Burst = 5
+
+	return conf
+}
+
 type Config struct {
 	Auth          *AuthConfig         `json:"auth,omitempty" yaml:"auth,omitempty" toml:"Auth"`
 	Notifications NotificationsConfig `json:"notifications" yaml:"notifications" toml:"Notifications"`
 	PollInterval  time.Duration       `json:"poll_interval" yaml:"poll_interval" toml:"PollInterval"`
+	// Base URL (scheme, host, and path prefix) of Gemini API requests,
+	// ex. to send them to a local stand-in or through a proxy.
+	// Default: GEMINI_BASE_URL if set, otherwise https://api.gemini.com (or its sandbox if GEMINI_SANDBOX is true)
+	BaseUrl string `json:"base_url,omitempty" yaml:"base_url,omitempty" toml:"BaseUrl,omitempty"`
+	// Retry, rate limiting, and cost accounting configuration of Gemini API requests.
+	// Default: up to 3 retries, and 2 requests per second with bursts of up to 5 requests.
+	Transport transport.Config `json:"transport,omitempty" yaml:"transport,omitempty" toml:"Transport,omitempty"`
+	// Records Gemini API traffic to a cassette file, or replays it from one.
+	// When replaying, prices are polled as fast as the recorded responses allow,
+	// and alerts are timed using the recorded timestamps.
+	Cassette transport.CassetteConfig `json:"cassette,omitempty" yaml:"cassette,omitempty" toml:"Cassette,omitempty"`
+	// Symbols (ex. BTCUSD) to remove from the cache when the poller starts,
+	// so that symbols which are no longer configured stop being resumed.
+	Forget []string `json:"forget,omitempty" yaml:"forget,omitempty" toml:"Forget,omitempty"`
 }
 
 type AuthConfig struct {
@@ -18,8 +18,11 @@ const (
 )
 
 const (
-	apiUri     string = "api.gemini.com"
-	sandboxUri string = "api.sandbox.gemini.com"
+	// DefaultBaseUrl is the base URL of Gemini API requests,
+	// unless overridden by config or GEMINI_BASE_URL.
+	DefaultBaseUrl string = "https://api.gemini.com"
+	// SandboxBaseUrl is used instead of DefaultBaseUrl if GEMINI_SANDBOX is true.
+	SandboxBaseUrl string = "https://api.sandbox.gemini.com"
 )
 
 const (
@@ -2,11 +2,14 @@ package slack
 
 import (
 	"context"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 
+	"github.com/jalavosus/stuffnotifier/internal/env"
 	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/internal/utils"
 	"github.com/jalavosus/stuffnotifier/pkg/authdata"
 )
 
@@ -37,9 +40,26 @@ func NewClient(conf *Config) (*Client, error) {
 		return nil, errors.New("no slack token found in configuration or environment")
 	}
 
+	var opts []slack.Option
+
+	baseUrl := env.BaseUrl(env.SlackBaseUrl, "")
+	if conf != nil && conf.BaseUrl != "" {
+		baseUrl = conf.BaseUrl
+	}
+
+	if baseUrl != "" {
+		u, err := utils.ParseBaseUrl(baseUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		// The slack client appends method names directly to its API URL.
+		opts = append(opts, slack.OptionAPIURL(strings.TrimSuffix(u.String(), "/")+"/"))
+	}
+
 	c := new(Client)
 
-	c.client = slack.New(authData.Secret())
+	c.client = slack.New(authData.Secret(), opts...)
 	c.authData = authData
 
 	authResponse, err := c.client.AuthTest()
@@ -64,8 +84,9 @@ func (c Client) sendMessage(ctx context.Context, msg messages.Message, channelId
 	formattedMsg := msg.FormatMarkdown()
 
 	attachment := slack.Attachment{
-		Pretext: "StuffNotifier alert",
-		Text:    formattedMsg,
+		Pretext:    "StuffNotifier alert",
+		Text:       formattedMsg,
+		MarkdownIn: []string{"text"},
 	}
 
 	_, _, err := c.client.PostMessageContext(ctx, channelId, slack.MsgOptionAttachments(attachment))
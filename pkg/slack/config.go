@@ -16,6 +16,10 @@ type Config struct {
 	Users []string `json:"users" yaml:"users" toml:"Users"`
 	// Which channel IDs to send notifications to
 	Channels []string `json:"channels" yaml:"channels" toml:"Channels"`
+	// Base URL (scheme, host, and path prefix) of Slack Web API requests,
+	// ex. to send them to a local stand-in or through a proxy.
+	// Default: SLACK_BASE_URL if set, otherwise https://slack.com/api/
+	BaseUrl string `json:"base_url,omitempty" yaml:"base_url,omitempty" toml:"BaseUrl,omitempty"`
 }
 
 type AuthConfig struct {
@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+)
+
+// BaseUrlTransport is an http.RoundTripper which sends every request to a base URL,
+// replacing each request's scheme and host with the base URL's, and prefixing
+// its path with the base URL's path.
+// It allows pointing clients whose API URL can't be configured (ex. Twilio's)
+// at a local stand-in or a proxy.
+type BaseUrlTransport struct {
+	base    http.RoundTripper
+	baseUrl *url.URL
+}
+
+// NewBaseUrlTransport returns a BaseUrlTransport sending requests to the passed base URL
+// using the passed base http.RoundTripper, or http.DefaultTransport if base is nil.
+func NewBaseUrlTransport(base http.RoundTripper, baseUrl string) (*BaseUrlTransport, error) {
+	u, err := utils.ParseBaseUrl(baseUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &BaseUrlTransport{
+		base:    base,
+		baseUrl: u,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *BaseUrlTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	req.URL.Scheme = t.baseUrl.Scheme
+	req.URL.Host = t.baseUrl.Host
+	req.Host = t.baseUrl.Host
+
+	if prefix := strings.TrimSuffix(t.baseUrl.Path, "/"); prefix != "" {
+		req.URL.Path = prefix + "/" + strings.TrimPrefix(req.URL.Path, "/")
+		req.URL.RawPath = ""
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,38 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseUrlTransport_RoundTrip(t *testing.T) {
+	var gotPath, gotQuery string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	baseUrlTransport, err := NewBaseUrlTransport(server.Client().Transport, server.URL+"/twilio/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: baseUrlTransport}
+
+	resp, err := client.Get("https://api.twilio.com/2010-04-01/Accounts/AC123/Messages.json?PageSize=1")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "/twilio/2010-04-01/Accounts/AC123/Messages.json", gotPath)
+	assert.Equal(t, "PageSize=1", gotQuery)
+}
@@ -0,0 +1,166 @@
+package transport
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+// CassetteMode sets whether API traffic is recorded to or replayed from a cassette file.
+type CassetteMode string
+
+const (
+	CassetteOff    CassetteMode = ""
+	CassetteRecord CassetteMode = "record"
+	CassetteReplay CassetteMode = "replay"
+)
+
+// maxCassetteLine is the maximum size of a single recorded interaction.
+const maxCassetteLine = 64 << 20
+
+// CassetteConfig contains the configuration for recording API traffic to,
+// or replaying it from, a cassette file.
+type CassetteConfig struct {
+	// "record" to append every request/response pair to the cassette file,
+	// or "replay" to serve responses from it instead of calling the API.
+	// Recording and replaying are disabled if unset.
+	Mode CassetteMode `json:"mode,omitempty" yaml:"mode,omitempty" toml:"Mode,omitempty"`
+	// Path to the cassette file.
+	Path string `json:"path,omitempty" yaml:"path,omitempty" toml:"Path,omitempty"`
+}
+
+// Validate returns an error if the config's mode is unknown,
+// or if a mode is set without a path.
+func (c CassetteConfig) Validate() error {
+	switch c.Mode {
+	case CassetteOff:
+		return nil
+	case CassetteRecord, CassetteReplay:
+	default:
+		return errors.Errorf(
+			"unknown cassette mode %[1]s. Allowed values: '%[2]s', '%[3]s'",
+			c.Mode, CassetteRecord, CassetteReplay,
+		)
+	}
+
+	if c.Path == "" {
+		return errors.Errorf("cassette mode %[1]s requires a path", c.Mode)
+	}
+
+	return nil
+}
+
+// Interaction is a single recorded request and its response (or error).
+type Interaction struct {
+	// Time the response was received.
+	RecordedAt time.Time        `json:"recorded_at"`
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	// Error returned instead of a response, ex. a network error.
+	Error string `json:"error,omitempty"`
+}
+
+// RecordedRequest identifies a recorded request.
+// Request headers aren't recorded, as they contain API credentials.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// Path and query of the request, without its scheme and host,
+	// so that a cassette can be replayed regardless of the base URL.
+	URL string `json:"url"`
+}
+
+// RecordedResponse is a recorded response.
+type RecordedResponse struct {
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+	StatusCode int         `json:"status_code"`
+}
+
+func newRecordedRequest(req *http.Request) RecordedRequest {
+	return RecordedRequest{
+		Method: strings.ToUpper(req.Method),
+		URL:    req.URL.RequestURI(),
+	}
+}
+
+// key returns the key which replayed requests are matched on.
+func (r RecordedRequest) key() string {
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	return method + " " + r.URL
+}
+
+// LoadCassette reads every interaction recorded in the cassette file at the passed path.
+func LoadCassette(path string) ([]Interaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errs.ReadFileError(err, path)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var (
+		interactions []Interaction
+		scanner      = bufio.NewScanner(f)
+		line         int
+	)
+
+	scanner.Buffer(nil, maxCassetteLine)
+
+	for scanner.Scan() {
+		line++
+
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, errors.WithMessagef(err, "error unmarshalling interaction on line %[1]d of cassette %[2]s", line, path)
+		}
+
+		interactions = append(interactions, interaction)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errs.ReadFileError(err, path)
+	}
+
+	return interactions, nil
+}
+
+// NewCassetteTransport returns the http.RoundTripper recording to or replaying from
+// the configured cassette file, using the passed base http.RoundTripper
+// (or http.DefaultTransport if base is nil) when recording.
+// It returns nil if the cassette's mode isn't set.
+func NewCassetteTransport(base http.RoundTripper, conf CassetteConfig) (http.RoundTripper, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch conf.Mode {
+	case CassetteRecord:
+		return NewRecorder(base, conf.Path), nil
+	case CassetteReplay:
+		replayer, err := NewReplayer(conf.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return replayer, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassetteConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    CassetteConfig
+		wantErr bool
+	}{
+		{name: "off", conf: CassetteConfig{}},
+		{name: "record", conf: CassetteConfig{Mode: CassetteRecord, Path: "cassette.jsonl"}},
+		{name: "replay", conf: CassetteConfig{Mode: CassetteReplay, Path: "cassette.jsonl"}},
+		{name: "missing path", conf: CassetteConfig{Mode: CassetteReplay}, wantErr: true},
+		{name: "unknown mode", conf: CassetteConfig{Mode: "rewind", Path: "cassette.jsonl"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.conf.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRecorder_Replayer(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","n":` + strconv.Itoa(requests) + `}`))
+	}))
+	defer server.Close()
+
+	var (
+		cassettePath = filepath.Join(t.TempDir(), "cassette.jsonl")
+		recordedAt   = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+		recorder     = NewRecorder(server.Client().Transport, cassettePath)
+	)
+
+	recorder.now = func() time.Time {
+		recordedAt = recordedAt.Add(time.Minute)
+		return recordedAt
+	}
+
+	recordClient := &http.Client{Transport: recorder}
+
+	for _, path := range []string{"/flights/UA1?max_pages=1", "/airports/KEWR", "/flights/UA1?max_pages=1", "/unavailable"} {
+		resp, err := recordClient.Get(server.URL + path)
+		require.NoError(t, err)
+
+		_, _ = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	interactions, err := LoadCassette(cassettePath)
+	require.NoError(t, err)
+	require.Len(t, interactions, 4)
+	assert.Equal(t, RecordedRequest{Method: http.MethodGet, URL: "/flights/UA1?max_pages=1"}, interactions[0].Request)
+	assert.Equal(t, "application/json", interactions[0].Response.Header.Get("Content-Type"))
+
+	replayer, err := NewReplayer(cassettePath)
+	require.NoError(t, err)
+	assert.Equal(t, interactions[0].RecordedAt, replayer.Now())
+
+	// Replayed requests may be sent to a different host.
+	replayClient := &http.Client{Transport: replayer}
+
+	tests := []struct {
+		path       string
+		wantBody   string
+		wantStatus int
+		wantTime   time.Time
+	}{
+		{
+			path:       "/airports/KEWR",
+			wantBody:   `{"path":"/airports/KEWR","n":2}`,
+			wantStatus: http.StatusOK,
+			wantTime:   interactions[1].RecordedAt,
+		},
+		{
+			path:       "/flights/UA1?max_pages=1",
+			wantBody:   `{"path":"/flights/UA1","n":1}`,
+			wantStatus: http.StatusOK,
+			wantTime:   interactions[1].RecordedAt,
+		},
+		{
+			path:       "/flights/UA1?max_pages=1",
+			wantBody:   `{"path":"/flights/UA1","n":3}`,
+			wantStatus: http.StatusOK,
+			wantTime:   interactions[2].RecordedAt,
+		},
+		{
+			path:       "/unavailable",
+			wantBody:   "",
+			wantStatus: http.StatusServiceUnavailable,
+			wantTime:   interactions[3].RecordedAt,
+		},
+	}
+
+	for _, tt := range tests {
+		resp, err := replayClient.Get("http://replay.invalid" + tt.path)
+		require.NoError(t, err)
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		assert.Equal(t, tt.wantStatus, resp.StatusCode, tt.path)
+		assert.Equal(t, tt.wantBody, string(body), tt.path)
+		assert.Equal(t, tt.wantTime, replayer.Now(), tt.path)
+	}
+
+	assert.Equal(t, 0, replayer.Remaining())
+	assert.Equal(t, 4, requests, "replayed requests shouldn't be sent")
+
+	_, err = replayClient.Get("http://replay.invalid/airports/KEWR")
+	assert.True(t, errors.Is(err, ErrCassetteExhausted))
+}
+
+func TestTransport_RoundTrip_Replayer(t *testing.T) {
+	replayer := NewReplayerFromInteractions([]Interaction{
+		{
+			Request:  RecordedRequest{Method: http.MethodGet, URL: "/flights/UA1"},
+			Response: RecordedResponse{StatusCode: http.StatusServiceUnavailable},
+		},
+		{
+			Request:  RecordedRequest{Method: http.MethodGet, URL: "/flights/UA1"},
+			Response: RecordedResponse{StatusCode: http.StatusOK, Body: "{}"},
+		},
+	})
+
+	tr := NewTransport(replayer, Config{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, RateLimit: -1})
+	client := &http.Client{Transport: tr}
+
+	// Recorded retries are replayed as retries.
+	resp, err := client.Get("http://replay.invalid/flights/UA1")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Running out of recorded responses isn't retried.
+	_, err = client.Get("http://replay.invalid/flights/UA1")
+	assert.True(t, errors.Is(err, ErrCassetteExhausted))
+	assert.Equal(t, UsageStats{Requests: 3, Retries: 1}, tr.Usage())
+}
@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// Config contains the retry, rate limiting, and cost accounting
+// configuration of a Transport.
+// Unset fields are filled in from the defaults of the API the Transport is used for.
+type Config struct {
+	// Maximum number of times a request which failed with a retryable error
+	// (network error, 429, or 5xx) is retried.
+	// Negative values disable retries.
+	// Default: 3
+	MaxRetries int `json:"max_retries,omitempty" yaml:"max_retries,omitempty" toml:"MaxRetries,omitempty"`
+	// Backoff before the first retry. The backoff doubles with every retry,
+	// and a random duration up to it (full jitter) is waited before retrying.
+	// Default: 500 milliseconds
+	MinBackoff time.Duration `json:"min_backoff,omitempty" yaml:"min_backoff,omitempty" toml:"MinBackoff,omitempty"`
+	// Maximum backoff between retries. Rate limited responses asking
+	// for a longer wait (using a Retry-After header) aren't retried.
+	// Default: 5 seconds
+	MaxBackoff time.Duration `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty" toml:"MaxBackoff,omitempty"`
+	// Maximum sustained number of requests per second sent to the API.
+	// Negative values disable rate limiting.
+	RateLimit float64 `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty" toml:"RateLimit,omitempty"`
+	// Maximum number of requests which can be sent at once
+	// before the rate limit applies.
+	// Default: 1
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty" toml:"Burst,omitempty"`
+	// Cost of a single request sent to the API, in whichever unit the API bills in.
+	// Every sent request (including retries) is counted towards usage.
+	CostPerRequest float64 `json:"cost_per_request,omitempty" yaml:"cost_per_request,omitempty" toml:"CostPerRequest,omitempty"`
+}
+
+// WithDefaults returns a copy of the Config with every unset field
+// set to its value in the passed defaults.
+func (c Config) WithDefaults(defaults Config) Config {
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaults.MaxRetries
+	}
+
+	if c.MinBackoff == 0 {
+		c.MinBackoff = defaults.MinBackoff
+	}
+
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = defaults.MaxBackoff
+	}
+
+	if c.RateLimit == 0 {
+		c.RateLimit = defaults.RateLimit
+	}
+
+	if c.Burst == 0 {
+		c.Burst = defaults.Burst
+	}
+
+	if c.CostPerRequest == 0 {
+		c.CostPerRequest = defaults.CostPerRequest
+	}
+
+	return c
+}
+
+// DefaultConfig returns a Config with retries enabled,
+// and without rate limiting or cost accounting.
+func DefaultConfig() Config {
+	return Config{
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		Burst:      1,
+	}
+}
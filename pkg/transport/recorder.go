@@ -0,0 +1,110 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Recorder is an http.RoundTripper which appends every request it sends,
+// along with its response (or error) and the time it was received, to a cassette file.
+// Cassettes can be replayed with a Replayer.
+type Recorder struct {
+	base http.RoundTripper
+	now  func() time.Time
+	path string
+	mu   sync.Mutex
+}
+
+// NewRecorder returns a Recorder appending to the cassette file at the passed path,
+// which sends requests using the passed base http.RoundTripper,
+// or http.DefaultTransport if base is nil.
+// The cassette file is created when the first request is recorded.
+func NewRecorder(base http.RoundTripper, path string) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Recorder{
+		base: base,
+		now:  time.Now,
+		path: path,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+// An error is returned if the interaction can't be written to the cassette file,
+// so that no traffic goes unrecorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	interaction := Interaction{
+		Request: newRecordedRequest(req),
+	}
+
+	resp, err := r.base.RoundTrip(req)
+
+	interaction.RecordedAt = r.now().UTC()
+
+	if err != nil {
+		interaction.Error = err.Error()
+
+		if recordErr := r.record(interaction); recordErr != nil {
+			return nil, recordErr
+		}
+
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	interaction.Response = RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       string(body),
+	}
+
+	if recordErr := r.record(interaction); recordErr != nil {
+		return nil, recordErr
+	}
+
+	return resp, nil
+}
+
+func (r *Recorder) record(interaction Interaction) error {
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return errors.WithMessage(err, "error marshalling recorded interaction")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.WithMessagef(err, "error opening cassette %[1]s", r.path)
+	}
+
+	_, writeErr := f.Write(append(line, '\n'))
+	closeErr := f.Close()
+
+	switch {
+	case writeErr != nil:
+		return errors.WithMessagef(writeErr, "error writing to cassette %[1]s", r.path)
+	case closeErr != nil:
+		return errors.WithMessagef(closeErr, "error writing to cassette %[1]s", r.path)
+	default:
+		return nil
+	}
+}
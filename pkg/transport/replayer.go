@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCassetteExhausted is returned by a Replayer for requests
+// which have no recorded interaction left to replay.
+var ErrCassetteExhausted = errors.New("no recorded interaction left to replay")
+
+// Replayer is an http.RoundTripper which serves the responses recorded in a cassette file
+// (see Recorder), instead of sending requests.
+// Requests are matched to recorded interactions by method, path, and query,
+// and interactions recorded for the same request are replayed in the order they were recorded.
+//
+// A Replayer also acts as a clock following the recorded timestamps:
+// Now returns the time at which the last replayed response was received.
+type Replayer struct {
+	interactions map[string][]Interaction
+	now          time.Time
+	remaining    int
+	mu           sync.Mutex
+}
+
+// NewReplayer returns a Replayer serving the interactions recorded in the cassette file at the passed path.
+func NewReplayer(path string) (*Replayer, error) {
+	interactions, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayerFromInteractions(interactions), nil
+}
+
+// NewReplayerFromInteractions returns a Replayer serving the passed interactions.
+func NewReplayerFromInteractions(interactions []Interaction) *Replayer {
+	r := &Replayer{
+		interactions: make(map[string][]Interaction),
+		remaining:    len(interactions),
+		now:          time.Now().UTC(),
+	}
+
+	for i, interaction := range interactions {
+		if i == 0 {
+			r.now = interaction.RecordedAt
+		}
+
+		key := interaction.Request.key()
+		r.interactions[key] = append(r.interactions[key], interaction)
+	}
+
+	return r
+}
+
+// Now returns the time at which the last replayed response was recorded,
+// or that of the first recorded interaction if none have been replayed yet.
+func (r *Replayer) Now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.now
+}
+
+// Remaining returns the number of recorded interactions which haven't been replayed.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.remaining
+}
+
+// RoundTrip implements http.RoundTripper.
+// ErrCassetteExhausted is returned if no recorded interaction is left for the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	key := newRecordedRequest(req).key()
+
+	r.mu.Lock()
+
+	queue := r.interactions[key]
+	if len(queue) == 0 {
+		r.mu.Unlock()
+		return nil, errors.WithMessagef(ErrCassetteExhausted, "%[1]s", key)
+	}
+
+	interaction := queue[0]
+	r.interactions[key] = queue[1:]
+	r.remaining--
+
+	if interaction.RecordedAt.After(r.now) {
+		r.now = interaction.RecordedAt
+	}
+
+	r.mu.Unlock()
+
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+
+	header := interaction.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%[1]d %[2]s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,263 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+// Transport is an http.RoundTripper which retries requests failing with
+// retryable errors (using exponential backoff with jitter), rate limits requests
+// with a token bucket, and accounts for the usage of every request it sends.
+// A Transport should be shared by every client of a single API.
+type Transport struct {
+	base       http.RoundTripper
+	limiter    *tokenBucket
+	usage      *Usage
+	randInt63n func(n int64) int64
+	conf       Config
+	// Timeout of each attempt of a request, or 0 for none.
+	attemptTimeout time.Duration
+}
+
+// NewTransport returns a Transport which sends requests using the passed base http.RoundTripper,
+// or http.DefaultTransport if base is nil.
+// Unset fields of the passed Config are set to their DefaultConfig values.
+func NewTransport(base http.RoundTripper, conf Config) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	conf = conf.WithDefaults(DefaultConfig())
+
+	t := &Transport{
+		base:       base,
+		usage:      new(Usage),
+		randInt63n: rand.Int63n,
+		conf:       conf,
+	}
+
+	if conf.RateLimit > 0 {
+		t.limiter = newTokenBucket(conf.RateLimit, conf.Burst)
+	}
+
+	return t
+}
+
+// NewClient returns an http.Client which sends requests through a new Transport
+// using the passed base http.RoundTripper, or http.DefaultTransport if base is nil.
+// The passed timeout is set as the Transport's attempt timeout, so that it applies
+// to each attempt of a request rather than to every attempt and the backoff between them.
+func NewClient(base http.RoundTripper, timeout time.Duration, conf Config) (*http.Client, *Transport) {
+	t := NewTransport(base, conf).SetAttemptTimeout(timeout)
+
+	return &http.Client{Transport: t}, t
+}
+
+// AttemptTimeout returns the timeout of each attempt of a request.
+func (t *Transport) AttemptTimeout() time.Duration {
+	return t.attemptTimeout
+}
+
+// SetAttemptTimeout sets the timeout of each attempt of a request,
+// which covers reading the attempt's response body.
+// Attempts which time out are retried. A timeout of 0 disables it.
+func (t *Transport) SetAttemptTimeout(timeout time.Duration) *Transport {
+	t.attemptTimeout = timeout
+	return t
+}
+
+// Config returns the Transport's Config, with defaults applied.
+func (t *Transport) Config() Config {
+	return t.conf
+}
+
+// Usage returns the usage of every request sent through the Transport.
+func (t *Transport) Usage() UsageStats {
+	return t.usage.Stats()
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		ctx         = req.Context()
+		ctxUsage, _ = usageFromContext(ctx)
+	)
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		if t.limiter != nil {
+			if err := t.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		t.recordUsage(ctxUsage, attempt > 0)
+
+		resp, err := t.roundTripAttempt(attemptReq)
+
+		wait, retry := t.retryWait(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		if sleepErr := sleep(ctx, wait); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+}
+
+// roundTripAttempt sends a single attempt of a request,
+// cancelling it once the attempt timeout has passed.
+func (t *Transport) roundTripAttempt(req *http.Request) (*http.Response, error) {
+	if t.attemptTimeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.attemptTimeout)
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The response body is read after the attempt returns.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// cancelOnClose is a response body which cancels its request's context once closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func (t *Transport) recordUsage(ctxUsage *Usage, retry bool) {
+	t.usage.add(retry, t.conf.CostPerRequest)
+
+	if ctxUsage != nil {
+		ctxUsage.add(retry, t.conf.CostPerRequest)
+	}
+}
+
+// retryWait returns how long to wait before retrying the request,
+// and false if it shouldn't be retried.
+func (t *Transport) retryWait(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= t.conf.MaxRetries || !canRetry(req) || req.Context().Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrCassetteExhausted) {
+			return 0, false
+		}
+
+		return t.backoff(attempt), true
+	}
+
+	if !retryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+
+	retryAfter := errs.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+	switch {
+	case retryAfter > t.conf.MaxBackoff:
+		// Waiting that long is left to the caller.
+		return 0, false
+	case retryAfter > 0:
+		return retryAfter, true
+	default:
+		return t.backoff(attempt), true
+	}
+}
+
+// backoff returns a random duration between 0 and
+// MinBackoff * 2^attempt, capped at MaxBackoff.
+func (t *Transport) backoff(attempt int) time.Duration {
+	ceiling := t.conf.MinBackoff
+	for i := 0; i < attempt && ceiling < t.conf.MaxBackoff; i++ {
+		ceiling *= 2
+	}
+
+	if ceiling > t.conf.MaxBackoff {
+		ceiling = t.conf.MaxBackoff
+	}
+
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(t.randInt63n(int64(ceiling) + 1))
+}
+
+// canRetry returns true if the request is idempotent,
+// and its body (if any) can be sent again.
+func canRetry(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,233 @@
+package transport
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		retryAfter   string
+		statuses     []int
+		maxRetries   int
+		wantStatus   int
+		wantRequests int
+	}{
+		{
+			name:         "success",
+			statuses:     []int{http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 1,
+		},
+		{
+			name:         "retried server error",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			name:         "retried rate limit",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "0",
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+		{
+			name:         "retries exhausted",
+			statuses:     []int{http.StatusInternalServerError},
+			maxRetries:   2,
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 3,
+		},
+		{
+			name:         "retries disabled",
+			statuses:     []int{http.StatusInternalServerError},
+			maxRetries:   -1,
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 1,
+		},
+		{
+			name:         "not found",
+			statuses:     []int{http.StatusNotFound},
+			wantStatus:   http.StatusNotFound,
+			wantRequests: 1,
+		},
+		{
+			name:         "retry after too long",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "3600",
+			wantStatus:   http.StatusTooManyRequests,
+			wantRequests: 1,
+		},
+		{
+			name:         "non-idempotent method",
+			method:       http.MethodPost,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				status := tt.statuses[len(tt.statuses)-1]
+				if requests < len(tt.statuses) {
+					status = tt.statuses[requests]
+				}
+
+				requests++
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			transport := NewTransport(server.Client().Transport, Config{
+				MaxRetries:     tt.maxRetries,
+				MinBackoff:     time.Millisecond,
+				MaxBackoff:     10 * time.Millisecond,
+				CostPerRequest: 0.5,
+			})
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			ctx, usage := WithUsage(context.Background())
+
+			req, err := http.NewRequestWithContext(ctx, method, server.URL, strings.NewReader("body"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := transport.RoundTrip(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_ = resp.Body.Close()
+
+			wantUsage := UsageStats{
+				Requests: tt.wantRequests,
+				Retries:  tt.wantRequests - 1,
+				Cost:     0.5 * float64(tt.wantRequests),
+			}
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantRequests, requests)
+			assert.Equal(t, wantUsage, usage.Stats())
+			assert.Equal(t, wantUsage, transport.Usage())
+		})
+	}
+}
+
+func TestTransport_AttemptTimeout(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt hangs until it times out.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, transport := NewClient(server.Client().Transport, 50*time.Millisecond, Config{
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+
+	// The client's timeout would cover every attempt.
+	assert.Zero(t, client.Timeout)
+	assert.Equal(t, 50*time.Millisecond, transport.AttemptTimeout())
+
+	resp, err := client.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The successful attempt's body can still be read once it's returned.
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestTransport_backoff(t *testing.T) {
+	transport := NewTransport(nil, Config{
+		MinBackoff: time.Second,
+		MaxBackoff: 10 * time.Second,
+	})
+
+	// Always pick the largest possible backoff.
+	transport.randInt63n = func(n int64) int64 {
+		return n - 1
+	}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: 2 * time.Second},
+		{attempt: 3, want: 8 * time.Second},
+		{attempt: 4, want: 10 * time.Second},
+		{attempt: 100, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, transport.backoff(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func TestTokenBucket_reserve(t *testing.T) {
+	var (
+		now    = time.Date(2022, 5, 31, 12, 0, 0, 0, time.UTC)
+		bucket = newTokenBucket(2, 2)
+	)
+
+	steps := []struct {
+		at   time.Duration
+		want time.Duration
+	}{
+		// The burst is available immediately.
+		{at: 0, want: 0},
+		{at: 0, want: 0},
+		// Further requests are spaced out at the rate.
+		{at: 0, want: 500 * time.Millisecond},
+		{at: 0, want: time.Second},
+		// The bucket refills after the queued requests have been sent.
+		{at: 2 * time.Second, want: 0},
+		{at: 10 * time.Second, want: 0},
+		{at: 10 * time.Second, want: 0},
+		{at: 10 * time.Second, want: 500 * time.Millisecond},
+	}
+
+	for i, step := range steps {
+		assert.Equal(t, step.want, bucket.reserve(now.Add(step.at)), "step %d", i)
+	}
+}
@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"context"
+	"sync"
+)
+
+// UsageStats contains the number and cost of requests sent through a Transport.
+type UsageStats struct {
+	// Number of requests sent, including retries.
+	Requests int `json:"requests"`
+	// Number of requests which were retries of a failed request.
+	Retries int `json:"retries"`
+	// Total cost of the sent requests.
+	Cost float64 `json:"cost"`
+}
+
+// Usage accumulates UsageStats, and is safe for concurrent use.
+type Usage struct {
+	stats UsageStats
+	mu    sync.Mutex
+}
+
+// Stats returns a snapshot of the accumulated UsageStats.
+func (u *Usage) Stats() UsageStats {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.stats
+}
+
+func (u *Usage) add(retry bool, cost float64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.stats.Requests++
+	u.stats.Cost += cost
+
+	if retry {
+		u.stats.Retries++
+	}
+}
+
+type usageCtxKey struct{}
+
+// WithUsage returns a context which accumulates the usage of every request
+// sent through a Transport using it (or a context derived from it),
+// allowing callers to account for the cost of a single call.
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	usage := new(Usage)
+	return context.WithValue(ctx, usageCtxKey{}, usage), usage
+}
+
+func usageFromContext(ctx context.Context) (*Usage, bool) {
+	usage, ok := ctx.Value(usageCtxKey{}).(*Usage)
+	return usage, ok
+}
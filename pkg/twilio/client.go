@@ -1,13 +1,22 @@
 package twilio
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/twilio/twilio-go"
+	"github.com/twilio/twilio-go/client"
 
 	"github.com/jalavosus/stuffnotifier/pkg/authdata"
 
 	"github.com/jalavosus/stuffnotifier/internal/env"
 	"github.com/jalavosus/stuffnotifier/pkg/singleton"
+	"github.com/jalavosus/stuffnotifier/pkg/transport"
+)
+
+const (
+	defaultHttpTimeout = 10 * time.Second
 )
 
 type Client struct {
@@ -27,30 +36,66 @@ func NewClient() (*Client, error) {
 		return nil, sendNumberErr
 	}
 
-	return newClient(auth, sendNumber), nil
+	return newClient(auth, sendNumber, env.BaseUrl(env.TwilioBaseUrl, ""))
 }
 
 func NewClientFromConfig(conf Config) (*Client, error) {
+	baseUrl := conf.BaseUrl
+	if baseUrl == "" {
+		baseUrl = env.BaseUrl(env.TwilioBaseUrl, "")
+	}
+
 	switch {
 	case conf.Auth.AuthToken != nil:
-		return newClient(conf.Auth.AuthToken, conf.SenderNumber), nil
+		return newClient(conf.Auth.AuthToken, conf.SenderNumber, baseUrl)
 	case conf.Auth.ApiKey != nil:
-		return newClient(conf.Auth.ApiKey, conf.SenderNumber), nil
+		return newClient(conf.Auth.ApiKey, conf.SenderNumber, baseUrl)
 	default:
 		return nil, errors.New("auth_token or api_key must be configured in config")
 	}
 }
 
-func newClient(authData authdata.AuthData, twilioNumber string) *Client {
+// newClient returns a Client using the passed auth data and sender number.
+// If baseUrl is set, every request is sent to it instead of Twilio's API hosts.
+func newClient(authData authdata.AuthData, twilioNumber, baseUrl string) (*Client, error) {
+	params := twilio.ClientParams{
+		AccountSid: authData.Account(),
+		Username:   authData.Key(),
+		Password:   authData.Secret(),
+	}
+
+	if baseUrl != "" {
+		baseUrlTransport, err := transport.NewBaseUrlTransport(nil, baseUrl)
+		if err != nil {
+			return nil, err
+		}
+
+		restClient := &client.Client{
+			Credentials: client.NewCredentials(authData.Key(), authData.Secret()),
+			HTTPClient: &http.Client{
+				Transport: baseUrlTransport,
+				Timeout:   defaultHttpTimeout,
+				// Same as the twilio client's default http.Client.
+				CheckRedirect: func(*http.Request, []*http.Request) error {
+					return http.ErrUseLastResponse
+				},
+			},
+		}
+
+		if accountSid := authData.Account(); accountSid != "" {
+			restClient.SetAccountSid(accountSid)
+		} else {
+			restClient.SetAccountSid(authData.Key())
+		}
+
+		params.Client = restClient
+	}
+
 	return &Client{
 		auth:       authData,
 		sendNumber: twilioNumber,
-		client: twilio.NewRestClientWithParams(twilio.ClientParams{
-			AccountSid: authData.Account(),
-			Username:   authData.Key(),
-			Password:   authData.Secret(),
-		}),
-	}
+		client:     twilio.NewRestClientWithParams(params),
+	}, nil
 }
 
 var (
@@ -14,6 +14,10 @@ type Config struct {
 	Auth            *AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty" toml:"Auth,omitempty"`
 	SenderNumber    string      `json:"sender_number" yaml:"sender_number" toml:"SenderNumber"`
 	RecipientNumber string      `json:"recipient_number" yaml:"recipient_number" toml:"RecipientNumber"`
+	// Base URL (scheme, host, and optional path prefix) which every Twilio API request is sent to,
+	// ex. to send them to a local stand-in or through a proxy.
+	// Default: TWILIO_BASE_URL if set, otherwise Twilio's own API hosts
+	BaseUrl string `json:"base_url,omitempty" yaml:"base_url,omitempty" toml:"BaseUrl,omitempty"`
 }
 
 type AuthConfig struct {
@@ -3,44 +3,25 @@ package twilio_test
 import (
 	"context"
 	"testing"
-	"text/template"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/jalavosus/stuffnotifier/internal/testservers"
 	"github.com/jalavosus/stuffnotifier/pkg/twilio"
 )
 
-type testMessage struct {
-	body string
-}
-
-func (t testMessage) FormatPlaintext() string {
-	return t.body
-}
-
-func (t testMessage) FormatMarkdown() string {
-	return t.body
-}
-
-func (t testMessage) PlaintextTemplate() *template.Template {
-	return nil
-}
-
-func (t testMessage) MarkdownTemplate() *template.Template {
-	return nil
-}
-
 func TestSendMessage(t *testing.T) {
 	t.Skip()
 
-	var testMsg = testMessage{"Hello, world!"}
+	var testMsg = testservers.PlainMessage("Hello, world!")
 
 	rootCtx := context.Background()
 
 	type testCase struct {
 		name        string
-		msg         testMessage
+		msg         testservers.Message
 		recipient   string
 		wantStatus  twilio.MessageSendStatus
 		wantSuccess bool
@@ -126,3 +107,40 @@ func TestSendMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestSendMessage_Fake(t *testing.T) {
+	const (
+		accountSid = "AC00000000000000000000000000000001"
+		sender     = "+15005550006"
+		recipient  = "+16037065541"
+	)
+
+	fake := testservers.NewTwilio(t)
+
+	client, err := twilio.NewClientFromConfig(twilio.Config{
+		Auth: &twilio.AuthConfig{
+			AuthToken: &twilio.AuthTokenConfig{AccountSid: accountSid, Token: "test-token"},
+		},
+		SenderNumber: sender,
+		BaseUrl:      fake.URL(),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := client.SendMessage(ctx, testservers.PlainMessage("Hello, world!"), recipient)
+	require.NoError(t, err)
+	assert.Equal(t, twilio.StatusQueued, resp.MessageStatus)
+	assert.NotEmpty(t, resp.MessageSid)
+
+	sent := fake.Messages()
+	require.Len(t, sent, 1)
+	assert.Equal(t, testservers.SMS{
+		Sid:        resp.MessageSid,
+		AccountSid: accountSid,
+		From:       sender,
+		To:         recipient,
+		Body:       "Hello, world!",
+	}, sent[0])
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRetryBackoff = 500 * time.Millisecond
+)
+
+// Client POSTs signed JSON payloads to one or more webhook endpoints.
+type Client struct {
+	httpClient   *http.Client
+	endpoints    []EndpointConfig
+	retryBackoff time.Duration
+}
+
+// NewClient returns a Client configured using the passed Config.
+func NewClient(conf *Config) (*Client, error) {
+	if conf == nil {
+		return nil, errors.New("webhook config must not be nil")
+	}
+
+	if len(conf.Endpoints) == 0 {
+		return nil, errors.New("at least one webhook endpoint must be configured")
+	}
+
+	for i, endpoint := range conf.Endpoints {
+		u, err := url.Parse(endpoint.Url)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid url for webhook endpoint %[1]d", i)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, errors.Errorf("invalid url for webhook endpoint %[1]d: scheme must be http or https", i)
+		}
+	}
+
+	c := &Client{
+		httpClient:   new(http.Client),
+		endpoints:    conf.Endpoints,
+		retryBackoff: defaultRetryBackoff,
+	}
+
+	return c, nil
+}
+
+// Endpoints returns the URLs of all configured endpoints.
+func (c *Client) Endpoints() []string {
+	urls := make([]string, len(c.endpoints))
+	for i := range c.endpoints {
+		urls[i] = c.endpoints[i].Url
+	}
+
+	return urls
+}
+
+// SetRetryBackoff sets the initial delay between retries,
+// which doubles after every attempt.
+// By default, this is set to 500 milliseconds.
+func (c *Client) SetRetryBackoff(backoff time.Duration) *Client {
+	c.retryBackoff = backoff
+	return c
+}
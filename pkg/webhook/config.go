@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/errs"
+)
+
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultMaxRetries = 3
+)
+
+// Config contains the configuration for sending notifications
+// to one or more outbound webhooks.
+type Config struct {
+	Endpoints []EndpointConfig `json:"endpoints" yaml:"endpoints" toml:"Endpoints"`
+}
+
+// EndpointConfig contains the configuration for a single webhook endpoint.
+type EndpointConfig struct {
+	// Secret used to sign payloads (HMAC-SHA256).
+	// If unset, payloads aren't signed.
+	Secret *string `json:"secret,omitempty" yaml:"secret,omitempty" toml:"Secret,omitempty"`
+	// Maximum number of times a request is retried after
+	// a 5xx response or a network error.
+	// Default: 3
+	MaxRetries *int `json:"max_retries,omitempty" yaml:"max_retries,omitempty" toml:"MaxRetries,omitempty"`
+	// Additional headers sent with every request.
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"Headers,omitempty"`
+	// URL which payloads are POSTed to.
+	Url string `json:"url" yaml:"url" toml:"Url"`
+	// Timeout of a single request.
+	// Default: 10s
+	Timeout time.Duration `json:"timeout" yaml:"timeout" toml:"Timeout"`
+}
+
+func (c EndpointConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+
+	return c.Timeout
+}
+
+func (c EndpointConfig) maxRetries() int {
+	if maxRetries, ok := utils.FromPointer(c.MaxRetries); ok && maxRetries >= 0 {
+		return maxRetries
+	}
+
+	return DefaultMaxRetries
+}
+
+func (c EndpointConfig) secret() string {
+	secret, _ := utils.FromPointer(c.Secret)
+	return secret
+}
+
+// LoadConfig reads configuration data from the file at the passed path
+// and returns it as a fully loaded Config.
+// `confPath` is expected to be an absolute file path.
+// Supported file types: json, yaml, toml
+func LoadConfig(confPath string) (conf Config, err error) {
+	confBytes, readErr := ioutil.ReadFile(confPath)
+	if readErr != nil {
+		err = errs.ReadFileError(readErr, confPath)
+		return
+	}
+
+	confType := utils.ConfigFileTypeFromExtension(filepath.Ext(confPath))
+
+	if unmarshalErr := utils.UnmarshalConfig(confBytes, confType, &conf); unmarshalErr != nil {
+		err = errors.WithMessagef(unmarshalErr, "error unmarshalling data from file %[1]s", confPath)
+		return
+	}
+
+	return
+}
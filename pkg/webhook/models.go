@@ -0,0 +1,22 @@
+package webhook
+
+import (
+	"time"
+)
+
+// PayloadVersion is the current version of the Payload envelope.
+// It's incremented whenever a breaking change is made to the envelope.
+const PayloadVersion int = 1
+
+// Payload is the JSON envelope POSTed to webhook endpoints.
+type Payload struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Data contains the message's structured fields,
+	// and is omitted if the message has no structured representation.
+	Data      any    `json:"data,omitempty"`
+	PollerId  string `json:"poller_id,omitempty"`
+	Kind      string `json:"kind"`
+	Plaintext string `json:"plaintext"`
+	Markdown  string `json:"markdown,omitempty"`
+	Version   int    `json:"version"`
+}
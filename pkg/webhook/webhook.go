@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+)
+
+const (
+	SignatureHeader string = "X-StuffNotifier-Signature"
+	TimestampHeader string = "X-StuffNotifier-Timestamp"
+	KindHeader      string = "X-StuffNotifier-Kind"
+	VersionHeader   string = "X-StuffNotifier-Version"
+)
+
+const (
+	contentType     string = "application/json"
+	signaturePrefix string = "sha256="
+)
+
+// BuildPayload builds the webhook Payload for the passed message.
+func BuildPayload(msg messages.Message, pollerId string) Payload {
+	payload := Payload{
+		Version:   PayloadVersion,
+		PollerId:  pollerId,
+		Kind:      messages.MessageKind(msg),
+		Timestamp: time.Now().UTC(),
+		Plaintext: msg.FormatPlaintext(),
+	}
+
+	if msg.MarkdownTemplate() != nil {
+		payload.Markdown = msg.FormatMarkdown()
+	}
+
+	if structured, ok := msg.(messages.StructuredMessage); ok {
+		payload.Data = structured.Structured()
+	}
+
+	return payload
+}
+
+// Sign returns the HMAC-SHA256 signature of the passed timestamp and body,
+// formatted as it's sent in the SignatureHeader.
+// The signed content is "<timestamp>.<body>", where timestamp is
+// the value of the TimestampHeader.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the passed signature is valid for
+// the passed secret, timestamp, and body.
+func Verify(secret, timestamp, signature string, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// SendMessage POSTs the passed message to every configured endpoint.
+// Every endpoint is attempted; errors are returned keyed by endpoint URL.
+func (c *Client) SendMessage(ctx context.Context, msg messages.Message, pollerId string) (sent int, endpointErrs map[string]error) {
+	endpointErrs = make(map[string]error)
+
+	body, err := json.Marshal(BuildPayload(msg, pollerId))
+	if err != nil {
+		err = errors.WithMessage(err, "error marshalling webhook payload")
+		for _, endpoint := range c.endpoints {
+			endpointErrs[endpoint.Url] = err
+		}
+
+		return
+	}
+
+	kind := messages.MessageKind(msg)
+
+	for _, endpoint := range c.endpoints {
+		if sendErr := c.send(ctx, endpoint, kind, body); sendErr != nil {
+			endpointErrs[endpoint.Url] = sendErr
+			continue
+		}
+
+		sent++
+	}
+
+	return
+}
+
+func (c *Client) send(ctx context.Context, endpoint EndpointConfig, kind string, body []byte) error {
+	var (
+		err     error
+		backoff = c.retryBackoff
+		retries = endpoint.maxRetries()
+	)
+
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return errors.WithMessagef(err, "retry aborted (%[1]s)", ctx.Err())
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+		}
+
+		var retryable bool
+		if retryable, err = c.attempt(ctx, endpoint, kind, body); err == nil || !retryable {
+			return err
+		}
+	}
+
+	return errors.WithMessagef(err, "giving up after %[1]d attempts", retries+1)
+}
+
+// attempt makes a single request to the passed endpoint,
+// returning whether the request should be retried if it failed.
+func (c *Client) attempt(ctx context.Context, endpoint EndpointConfig, kind string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, endpoint.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for k, v := range endpoint.Headers {
+		req.Header.Set(k, v)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(KindHeader, kind)
+	req.Header.Set(VersionHeader, strconv.Itoa(PayloadVersion))
+	req.Header.Set(TimestampHeader, timestamp)
+
+	if secret := endpoint.secret(); secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		// Network errors and per-attempt timeouts are retryable;
+		// cancellation of the parent context is handled by send.
+		return true, err
+	}
+
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+
+	return res.StatusCode >= http.StatusInternalServerError, &StatusError{StatusCode: res.StatusCode}
+}
+
+// StatusError is returned when a webhook endpoint
+// responds with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return "webhook endpoint returned status " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+}
@@ -0,0 +1,156 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jalavosus/stuffnotifier/internal/messages"
+	"github.com/jalavosus/stuffnotifier/internal/utils"
+	"github.com/jalavosus/stuffnotifier/pkg/webhook"
+)
+
+const (
+	testSecret   string = "s3cr3t"
+	testPollerId string = "poller-1"
+)
+
+var testMsg = messages.SpotPriceAlert{
+	EventTime:     time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+	SpotPrice:     decimal.NewFromInt(1800),
+	BaseAmount:    decimal.NewFromInt(1),
+	BaseCurrency:  "ETH",
+	QuoteCurrency: "USD",
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxRetries   int
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{
+			name:         "success",
+			statuses:     []int{http.StatusNoContent},
+			wantAttempts: 1,
+		},
+		{
+			name:         "retry on 5xx",
+			statuses:     []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:   2,
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries exhausted",
+			statuses:     []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			maxRetries:   1,
+			wantAttempts: 2,
+			wantErr:      true,
+		},
+		{
+			name:         "no retry on 4xx",
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			maxRetries:   2,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+
+				body, _ := ioutil.ReadAll(r.Body)
+
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, "custom", r.Header.Get("X-Custom"))
+				assert.Equal(t, messages.SpotPriceAlertKind, r.Header.Get(webhook.KindHeader))
+				assert.True(t, webhook.Verify(
+					testSecret,
+					r.Header.Get(webhook.TimestampHeader),
+					r.Header.Get(webhook.SignatureHeader),
+					body,
+				))
+
+				var payload map[string]any
+				assert.NoError(t, json.Unmarshal(body, &payload))
+				assert.EqualValues(t, webhook.PayloadVersion, payload["version"])
+				assert.Equal(t, testPollerId, payload["poller_id"])
+				assert.Equal(t, messages.SpotPriceAlertKind, payload["kind"])
+				assert.Equal(t, testMsg.FormatPlaintext(), payload["plaintext"])
+				if md := testMsg.FormatMarkdown(); md != "" {
+					assert.Equal(t, md, payload["markdown"])
+				}
+				assert.Equal(t, map[string]any{
+					"event_time":     "2022-06-01T12:00:00Z",
+					"spot_price":     "1800",
+					"base_amount":    "1",
+					"base_currency":  "ETH",
+					"quote_currency": "USD",
+				}, payload["data"])
+
+				w.WriteHeader(tt.statuses[int(n)-1])
+			}))
+			defer srv.Close()
+
+			client, err := webhook.NewClient(&webhook.Config{
+				Endpoints: []webhook.EndpointConfig{{
+					Url:        srv.URL,
+					Secret:     utils.ToPointer(testSecret),
+					MaxRetries: utils.ToPointer(tt.maxRetries),
+					Headers:    map[string]string{"X-Custom": "custom"},
+				}},
+			})
+			assert.NoError(t, err)
+
+			client.SetRetryBackoff(time.Millisecond)
+
+			sent, errs := client.SendMessage(context.Background(), testMsg, testPollerId)
+			if tt.wantErr {
+				assert.Equal(t, 0, sent)
+				assert.Len(t, errs, 1)
+			} else {
+				assert.Equal(t, 1, sent)
+				assert.Empty(t, errs)
+			}
+
+			assert.Equal(t, tt.wantAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"version":1}`)
+	sig := webhook.Sign(testSecret, "1654084800", body)
+
+	assert.True(t, webhook.Verify(testSecret, "1654084800", sig, body))
+	assert.False(t, webhook.Verify(testSecret, "1654084801", sig, body))
+	assert.False(t, webhook.Verify("other", "1654084800", sig, body))
+	assert.False(t, webhook.Verify(testSecret, "1654084800", sig, []byte(`{"version":2}`)))
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := webhook.NewClient(&webhook.Config{})
+	assert.Error(t, err)
+
+	_, err = webhook.NewClient(&webhook.Config{Endpoints: []webhook.EndpointConfig{{Url: "ftp://example.com"}}})
+	assert.Error(t, err)
+
+	_, err = webhook.NewClient(&webhook.Config{Endpoints: []webhook.EndpointConfig{{Url: "https://example.com/hook"}}})
+	assert.NoError(t, err)
+}